@@ -80,26 +80,56 @@ func run(osIdentifier utils.OSIdentifier, knownFilePaths *utils.KnownFilePaths,
 		}
 	}
 
+	deprecatedAPIsCollector := collector.NewDeprecatedAPIsCollector(config, runtimeInfo)
 	dnsCollector := collector.NewDNSCollector(osIdentifier, knownFilePaths, fileSystem)
+	dnsProbeCollector := collector.NewDNSProbeCollector(config, osIdentifier, runtimeInfo, knownFilePaths, fileSystem)
+	helmCollector := collector.NewHelmCollector(config, runtimeInfo)
 	kubeletCmdCollector := collector.NewKubeletCmdCollector(osIdentifier, runtimeInfo)
-	networkOutboundCollector := collector.NewNetworkOutboundCollector()
+	kubeletConfigCollector := collector.NewKubeletConfigCollector(config, runtimeInfo)
+	networkOutboundCollector := collector.NewNetworkOutboundCollector(config, runtimeInfo, knownFilePaths)
+	packetCaptureCollector := collector.NewPacketCaptureCollector(osIdentifier, runtimeInfo, knownFilePaths, fileSystem)
+	resourceQuotaCollector := collector.NewResourceQuotaCollector(config, runtimeInfo)
+	systemPerfCollector := collector.NewSystemPerfCollector(config, runtimeInfo)
 	collectors := []interfaces.Collector{
+		deprecatedAPIsCollector,
 		dnsCollector,
+		dnsProbeCollector,
+		helmCollector,
 		kubeletCmdCollector,
+		kubeletConfigCollector,
 		networkOutboundCollector,
-		collector.NewHelmCollector(config, runtimeInfo),
+		packetCaptureCollector,
+		resourceQuotaCollector,
+		systemPerfCollector,
+		collector.NewAutoscalingCollector(config, runtimeInfo),
+		collector.NewCertificatesCollector(config, osIdentifier, runtimeInfo),
+		collector.NewCgroupCollector(osIdentifier, runtimeInfo, knownFilePaths, fileSystem),
+		collector.NewContainerRuntimeCollector(osIdentifier, runtimeInfo),
+		collector.NewCoreDNSCollector(config, runtimeInfo),
+		collector.NewDiskUsageCollector(osIdentifier, runtimeInfo),
+		collector.NewEventsCollector(config, runtimeInfo),
 		collector.NewIPTablesCollector(osIdentifier, runtimeInfo),
+		collector.NewKernelCollector(osIdentifier, runtimeInfo, 24*time.Hour),
 		collector.NewKubeObjectsCollector(config, runtimeInfo),
+		collector.NewNetworkPolicyCollector(config, runtimeInfo),
+		collector.NewNodeCollector(config, runtimeInfo),
 		collector.NewNodeLogsCollector(runtimeInfo, fileSystem),
 		collector.NewOsmCollector(config, runtimeInfo),
 		collector.NewPDBCollector(config, runtimeInfo),
 		collector.NewPodsContainerLogsCollector(config, runtimeInfo),
+		collector.NewRBACCollector(config, runtimeInfo),
+		collector.NewServicesCollector(config, runtimeInfo),
 		collector.NewSmiCollector(config, runtimeInfo),
+		collector.NewStorageCollector(config, osIdentifier, runtimeInfo),
 		collector.NewSystemLogsCollector(osIdentifier, runtimeInfo),
-		collector.NewSystemPerfCollector(config, runtimeInfo),
+		collector.NewWebhooksCollector(config, runtimeInfo),
 		collector.NewWindowsLogsCollector(osIdentifier, runtimeInfo, knownFilePaths, fileSystem, 10*time.Second, 20*time.Minute),
+		collector.NewWorkloadsCollector(config, runtimeInfo),
 	}
 
+	// The capture file is read again for the zip archive, so it is only removed once everything has been exported.
+	defer packetCaptureCollector.Cleanup()
+
 	collectorGrp := new(sync.WaitGroup)
 
 	dataProducers := []interfaces.DataProducer{}
@@ -132,8 +162,13 @@ func run(osIdentifier utils.OSIdentifier, knownFilePaths *utils.KnownFilePaths,
 	collectorGrp.Wait()
 
 	diagnosers := []interfaces.Diagnoser{
-		diagnoser.NewNetworkConfigDiagnoser(runtimeInfo, dnsCollector, kubeletCmdCollector),
+		diagnoser.NewNetworkConfigDiagnoser(runtimeInfo, dnsCollector, kubeletCmdCollector, kubeletConfigCollector),
 		diagnoser.NewNetworkOutboundDiagnoser(runtimeInfo, networkOutboundCollector),
+		diagnoser.NewSystemPerfDiagnoser(runtimeInfo, systemPerfCollector),
+		diagnoser.NewDNSResolutionDiagnoser(runtimeInfo, dnsProbeCollector),
+		diagnoser.NewResourceQuotaDiagnoser(runtimeInfo, resourceQuotaCollector),
+		diagnoser.NewDeprecatedAPIsDiagnoser(runtimeInfo, deprecatedAPIsCollector),
+		diagnoser.NewHelmDiagnoser(runtimeInfo, helmCollector),
 	}
 
 	diagnoserGrp := new(sync.WaitGroup)
@@ -0,0 +1,457 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	vpaCRDName          = "verticalpodautoscalers.autoscaling.k8s.io"
+	kedaScaledObjectCRD = "scaledobjects.keda.sh"
+)
+
+var clusterAutoscalerCountRegex = regexp.MustCompile(`(\w+)=(-?\d+)`)
+
+// HPAMetric is a metric used by a HorizontalPodAutoscaler, with its target and current values.
+type HPAMetric struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Target  string `json:"target"`
+	Current string `json:"current"`
+}
+
+// HPACondition is a condition of a HorizontalPodAutoscaler, such as AbleToScale or ScalingLimited.
+type HPACondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// HPASummary is the state of a HorizontalPodAutoscaler.
+type HPASummary struct {
+	Namespace       string         `json:"namespace"`
+	Name            string         `json:"name"`
+	Target          string         `json:"target"`
+	MinReplicas     int32          `json:"minReplicas"`
+	MaxReplicas     int32          `json:"maxReplicas"`
+	CurrentReplicas int32          `json:"currentReplicas"`
+	DesiredReplicas int32          `json:"desiredReplicas"`
+	Metrics         []HPAMetric    `json:"metrics"`
+	Conditions      []HPACondition `json:"conditions"`
+}
+
+// ClusterAutoscalerCondition is one of the Health, ScaleUp or ScaleDown conditions reported by the cluster autoscaler.
+type ClusterAutoscalerCondition struct {
+	Status             string           `json:"status"`
+	Counts             map[string]int64 `json:"counts"`
+	LastProbeTime      string           `json:"lastProbeTime"`
+	LastTransitionTime string           `json:"lastTransitionTime"`
+}
+
+// ClusterAutoscalerNodeGroup is the status of the whole cluster, or of a single node group.
+type ClusterAutoscalerNodeGroup struct {
+	Name      string                      `json:"name,omitempty"`
+	Health    *ClusterAutoscalerCondition `json:"health,omitempty"`
+	ScaleUp   *ClusterAutoscalerCondition `json:"scaleUp,omitempty"`
+	ScaleDown *ClusterAutoscalerCondition `json:"scaleDown,omitempty"`
+}
+
+// ClusterAutoscalerStatus is the content of the cluster-autoscaler-status ConfigMap.
+type ClusterAutoscalerStatus struct {
+	Time        string                       `json:"time"`
+	ClusterWide ClusterAutoscalerNodeGroup   `json:"clusterWide"`
+	NodeGroups  []ClusterAutoscalerNodeGroup `json:"nodeGroups"`
+}
+
+// VPAContainerRecommendation is the resources recommended for a container by a VerticalPodAutoscaler.
+type VPAContainerRecommendation struct {
+	ContainerName  string            `json:"containerName"`
+	Target         map[string]string `json:"target"`
+	LowerBound     map[string]string `json:"lowerBound"`
+	UpperBound     map[string]string `json:"upperBound"`
+	UncappedTarget map[string]string `json:"uncappedTarget"`
+}
+
+// VPARecommendation is the recommendation of a VerticalPodAutoscaler for its target.
+type VPARecommendation struct {
+	Namespace  string                       `json:"namespace"`
+	Name       string                       `json:"name"`
+	Target     string                       `json:"target"`
+	UpdateMode string                       `json:"updateMode"`
+	Containers []VPAContainerRecommendation `json:"containers"`
+}
+
+// AutoscalingCollector defines a Autoscaling Collector struct
+type AutoscalingCollector struct {
+	data          map[string]string
+	kubeconfig    *rest.Config
+	commandRunner *utils.KubeCommandRunner
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewAutoscalingCollector is a constructor
+func NewAutoscalingCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *AutoscalingCollector {
+	return &AutoscalingCollector{
+		data:          make(map[string]string),
+		kubeconfig:    config,
+		commandRunner: utils.NewKubeCommandRunner(config),
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *AutoscalingCollector) GetName() string {
+	return "autoscaling"
+}
+
+func (collector *AutoscalingCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *AutoscalingCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	hpas := []HPASummary{}
+	for _, namespace := range getConfiguredNamespaces(collector.runtimeInfo) {
+		hpaList, err := clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing horizontalpodautoscalers in namespace '%s': %w", namespace, err)
+		}
+		for _, hpa := range hpaList.Items {
+			hpas = append(hpas, getHPASummary(hpa))
+		}
+	}
+	hpasBytes, err := json.Marshal(hpas)
+	if err != nil {
+		return fmt.Errorf("marshall horizontalpodautoscalers to json: %w", err)
+	}
+	collector.data["autoscaling_hpas"] = string(hpasBytes)
+
+	// The status ConfigMap only exists when the cluster autoscaler is enabled.
+	configMap, err := clientset.CoreV1().ConfigMaps("kube-system").Get(context.Background(), "cluster-autoscaler-status", metav1.GetOptions{})
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			return fmt.Errorf("error getting cluster-autoscaler-status configmap: %w", err)
+		}
+	} else {
+		status := parseClusterAutoscalerStatus(configMap.Data["status"])
+		statusBytes, err := json.Marshal(status)
+		if err != nil {
+			return fmt.Errorf("marshall cluster autoscaler status to json: %w", err)
+		}
+		collector.data["autoscaling_cluster_autoscaler_status"] = string(statusBytes)
+	}
+
+	return collector.collectCustomResources()
+}
+
+func (collector *AutoscalingCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// collectCustomResources collects VerticalPodAutoscalers and KEDA ScaledObjects, if their CRDs are installed.
+func (collector *AutoscalingCollector) collectCustomResources() error {
+	crds, err := collector.commandRunner.GetCRDUnstructuredList()
+	if err != nil {
+		return fmt.Errorf("error listing CRDs in cluster: %w", err)
+	}
+
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		var collectorKey string
+		switch crd.GetName() {
+		case vpaCRDName:
+			collectorKey = "autoscaling_vpas"
+		case kedaScaledObjectCRD:
+			collectorKey = "autoscaling_scaledobjects"
+		default:
+			continue
+		}
+
+		gvr, err := collector.commandRunner.GetGVRFromCRD(crd)
+		if err != nil {
+			log.Printf("Failed to get resource type for CRD %s: %v", crd.GetName(), err)
+			continue
+		}
+
+		recommendations := []VPARecommendation{}
+		for _, namespace := range getConfiguredNamespaces(collector.runtimeInfo) {
+			keySuffix := ""
+			if len(namespace) > 0 {
+				keySuffix = "_" + namespace
+			}
+
+			list, err := collector.commandRunner.GetUnstructuredList(gvr, namespace, &metav1.ListOptions{})
+			if err != nil {
+				value := fmt.Sprintf("Failed to collect %s in namespace '%s': %+v\n", gvr.Resource, namespace, err)
+				log.Print(value)
+				collector.data[collectorKey+keySuffix] = value
+				continue
+			}
+
+			value, err := collector.commandRunner.PrintAsJson(list)
+			if err != nil {
+				return fmt.Errorf("error printing %s in namespace '%s' as JSON: %w", gvr.Resource, namespace, err)
+			}
+			collector.data[collectorKey+keySuffix] = value
+
+			if crd.GetName() == vpaCRDName {
+				for j := range list.Items {
+					recommendations = append(recommendations, getVPARecommendation(&list.Items[j]))
+				}
+			}
+		}
+
+		if crd.GetName() == vpaCRDName {
+			recommendationsBytes, err := json.Marshal(recommendations)
+			if err != nil {
+				return fmt.Errorf("marshall vpa recommendations to json: %w", err)
+			}
+			collector.data["autoscaling_vpa_recommendations"] = string(recommendationsBytes)
+		}
+	}
+
+	return nil
+}
+
+func getHPASummary(hpa autoscalingv2beta2.HorizontalPodAutoscaler) HPASummary {
+	summary := HPASummary{
+		Namespace:       hpa.Namespace,
+		Name:            hpa.Name,
+		Target:          hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name,
+		MinReplicas:     getDesiredReplicas(hpa.Spec.MinReplicas),
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		Metrics:         []HPAMetric{},
+		Conditions:      []HPACondition{},
+	}
+
+	for i, spec := range hpa.Spec.Metrics {
+		metric := HPAMetric{Type: string(spec.Type), Current: "<unknown>"}
+		var target autoscalingv2beta2.MetricTarget
+		switch spec.Type {
+		case autoscalingv2beta2.ResourceMetricSourceType:
+			metric.Name, target = string(spec.Resource.Name), spec.Resource.Target
+		case autoscalingv2beta2.ContainerResourceMetricSourceType:
+			metric.Name, target = spec.ContainerResource.Container+"/"+string(spec.ContainerResource.Name), spec.ContainerResource.Target
+		case autoscalingv2beta2.PodsMetricSourceType:
+			metric.Name, target = spec.Pods.Metric.Name, spec.Pods.Target
+		case autoscalingv2beta2.ObjectMetricSourceType:
+			metric.Name, target = spec.Object.DescribedObject.Kind+"/"+spec.Object.DescribedObject.Name+" "+spec.Object.Metric.Name, spec.Object.Target
+		case autoscalingv2beta2.ExternalMetricSourceType:
+			metric.Name, target = spec.External.Metric.Name, spec.External.Target
+		}
+		metric.Target = formatMetricTarget(target)
+
+		// The current metrics are reported in the same order as the spec, for those the controller could read.
+		if i < len(hpa.Status.CurrentMetrics) && hpa.Status.CurrentMetrics[i].Type == spec.Type {
+			metric.Current = formatMetricStatus(hpa.Status.CurrentMetrics[i])
+		}
+
+		summary.Metrics = append(summary.Metrics, metric)
+	}
+
+	for _, condition := range hpa.Status.Conditions {
+		summary.Conditions = append(summary.Conditions, HPACondition{
+			Type:    string(condition.Type),
+			Status:  string(condition.Status),
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		})
+	}
+
+	return summary
+}
+
+func formatMetricTarget(target autoscalingv2beta2.MetricTarget) string {
+	switch {
+	case target.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *target.AverageUtilization)
+	case target.AverageValue != nil:
+		return target.AverageValue.String() + " (average)"
+	case target.Value != nil:
+		return target.Value.String()
+	}
+	return "<unknown>"
+}
+
+func formatMetricStatus(status autoscalingv2beta2.MetricStatus) string {
+	var current autoscalingv2beta2.MetricValueStatus
+	switch status.Type {
+	case autoscalingv2beta2.ResourceMetricSourceType:
+		current = status.Resource.Current
+	case autoscalingv2beta2.ContainerResourceMetricSourceType:
+		current = status.ContainerResource.Current
+	case autoscalingv2beta2.PodsMetricSourceType:
+		current = status.Pods.Current
+	case autoscalingv2beta2.ObjectMetricSourceType:
+		current = status.Object.Current
+	case autoscalingv2beta2.ExternalMetricSourceType:
+		current = status.External.Current
+	}
+
+	switch {
+	case current.AverageUtilization != nil && current.AverageValue != nil:
+		return fmt.Sprintf("%d%% (%s)", *current.AverageUtilization, current.AverageValue.String())
+	case current.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *current.AverageUtilization)
+	case current.AverageValue != nil:
+		return current.AverageValue.String() + " (average)"
+	case current.Value != nil:
+		return current.Value.String()
+	}
+	return "<unknown>"
+}
+
+// parseClusterAutoscalerStatus parses the human-readable status written by the cluster autoscaler. This has a
+// "Cluster-wide:" section and a "NodeGroups:" section, each with Health, ScaleUp and ScaleDown conditions of the form
+// "Status (name=count ...)" followed by their probe and transition times. Lines that are not recognized are ignored.
+func parseClusterAutoscalerStatus(content string) ClusterAutoscalerStatus {
+	status := ClusterAutoscalerStatus{NodeGroups: []ClusterAutoscalerNodeGroup{}}
+
+	var group *ClusterAutoscalerNodeGroup
+	var condition *ClusterAutoscalerCondition
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "Cluster-autoscaler status at ") {
+			status.Time = strings.TrimSuffix(strings.TrimPrefix(trimmed, "Cluster-autoscaler status at "), ":")
+			continue
+		}
+
+		switch trimmed {
+		case "Cluster-wide:":
+			group = &status.ClusterWide
+			condition = nil
+			continue
+		case "NodeGroups:":
+			group = nil
+			condition = nil
+			continue
+		}
+
+		key, value, found := cutClusterAutoscalerLine(trimmed)
+		if !found {
+			continue
+		}
+
+		switch key {
+		case "Name":
+			status.NodeGroups = append(status.NodeGroups, ClusterAutoscalerNodeGroup{Name: value})
+			group = &status.NodeGroups[len(status.NodeGroups)-1]
+			condition = nil
+		case "Health", "ScaleUp", "ScaleDown":
+			if group == nil {
+				continue
+			}
+			condition = parseClusterAutoscalerCondition(value)
+			switch key {
+			case "Health":
+				group.Health = condition
+			case "ScaleUp":
+				group.ScaleUp = condition
+			case "ScaleDown":
+				group.ScaleDown = condition
+			}
+		case "LastProbeTime":
+			if condition != nil {
+				condition.LastProbeTime = value
+			}
+		case "LastTransitionTime":
+			if condition != nil {
+				condition.LastTransitionTime = value
+			}
+		}
+	}
+
+	return status
+}
+
+func cutClusterAutoscalerLine(line string) (string, string, bool) {
+	i := strings.Index(line, ":")
+	if i <= 0 || strings.ContainsAny(line[:i], " \t") {
+		return "", "", false
+	}
+	return line[:i], strings.TrimSpace(line[i+1:]), true
+}
+
+func parseClusterAutoscalerCondition(value string) *ClusterAutoscalerCondition {
+	condition := &ClusterAutoscalerCondition{Counts: map[string]int64{}}
+	if i := strings.Index(value, " "); i >= 0 {
+		condition.Status = value[:i]
+	} else {
+		condition.Status = value
+	}
+
+	for _, match := range clusterAutoscalerCountRegex.FindAllStringSubmatch(value, -1) {
+		count, err := strconv.ParseInt(match[2], 10, 64)
+		if err == nil {
+			condition.Counts[match[1]] = count
+		}
+	}
+
+	return condition
+}
+
+func getVPARecommendation(vpa *unstructured.Unstructured) VPARecommendation {
+	recommendation := VPARecommendation{
+		Namespace:  vpa.GetNamespace(),
+		Name:       vpa.GetName(),
+		Containers: []VPAContainerRecommendation{},
+	}
+
+	kind, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "kind")
+	name, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "name")
+	recommendation.Target = kind + "/" + name
+
+	// The VPA defaults to the Auto update mode.
+	recommendation.UpdateMode, _, _ = unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode")
+	if recommendation.UpdateMode == "" {
+		recommendation.UpdateMode = "Auto"
+	}
+
+	containers, _, _ := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations")
+	for _, item := range containers {
+		container, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		containerName, _, _ := unstructured.NestedString(container, "containerName")
+		target, _, _ := unstructured.NestedStringMap(container, "target")
+		lowerBound, _, _ := unstructured.NestedStringMap(container, "lowerBound")
+		upperBound, _, _ := unstructured.NestedStringMap(container, "upperBound")
+		uncappedTarget, _, _ := unstructured.NestedStringMap(container, "uncappedTarget")
+		recommendation.Containers = append(recommendation.Containers, VPAContainerRecommendation{
+			ContainerName:  containerName,
+			Target:         target,
+			LowerBound:     lowerBound,
+			UpperBound:     upperBound,
+			UncappedTarget: uncappedTarget,
+		})
+	}
+
+	sort.Slice(recommendation.Containers, func(i, j int) bool {
+		return recommendation.Containers[i].ContainerName < recommendation.Containers[j].ContainerName
+	})
+
+	return recommendation
+}
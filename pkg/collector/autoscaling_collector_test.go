@@ -0,0 +1,220 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestAutoscalingCollectorGetName(t *testing.T) {
+	const expectedName = "autoscaling"
+
+	c := NewAutoscalingCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestAutoscalingCollectorCheckSupported(t *testing.T) {
+	c := NewAutoscalingCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestAutoscalingCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewAutoscalingCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	// The test cluster has no cluster autoscaler, VPA or KEDA.
+	expectedData := map[string]*regexp.Regexp{
+		"autoscaling_hpas": regexp.MustCompile(`^\[.*\]$`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestGetHPASummary(t *testing.T) {
+	minReplicas := int32(2)
+	targetUtilization := int32(80)
+	currentUtilization := int32(45)
+	currentValue := resource.MustParse("120m")
+	queueTarget := resource.MustParse("30")
+
+	hpa := autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web"},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    10,
+			Metrics: []autoscalingv2beta2.MetricSpec{
+				{
+					Type:     autoscalingv2beta2.ResourceMetricSourceType,
+					Resource: &autoscalingv2beta2.ResourceMetricSource{Name: corev1.ResourceCPU, Target: autoscalingv2beta2.MetricTarget{AverageUtilization: &targetUtilization}},
+				},
+				{
+					Type:     autoscalingv2beta2.ExternalMetricSourceType,
+					External: &autoscalingv2beta2.ExternalMetricSource{Metric: autoscalingv2beta2.MetricIdentifier{Name: "queue_length"}, Target: autoscalingv2beta2.MetricTarget{AverageValue: &queueTarget}},
+				},
+			},
+		},
+		Status: autoscalingv2beta2.HorizontalPodAutoscalerStatus{
+			CurrentReplicas: 2,
+			DesiredReplicas: 2,
+			CurrentMetrics: []autoscalingv2beta2.MetricStatus{
+				{
+					Type:     autoscalingv2beta2.ResourceMetricSourceType,
+					Resource: &autoscalingv2beta2.ResourceMetricStatus{Name: corev1.ResourceCPU, Current: autoscalingv2beta2.MetricValueStatus{AverageUtilization: &currentUtilization, AverageValue: &currentValue}},
+				},
+			},
+			Conditions: []autoscalingv2beta2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2beta2.ScalingActive, Status: corev1.ConditionFalse, Reason: "FailedGetExternalMetric", Message: "unable to get external metric"},
+			},
+		},
+	}
+
+	expected := HPASummary{
+		Namespace:       "app",
+		Name:            "web",
+		Target:          "Deployment/web",
+		MinReplicas:     2,
+		MaxReplicas:     10,
+		CurrentReplicas: 2,
+		DesiredReplicas: 2,
+		Metrics: []HPAMetric{
+			{Type: "Resource", Name: "cpu", Target: "80%", Current: "45% (120m)"},
+			{Type: "External", Name: "queue_length", Target: "30 (average)", Current: "<unknown>"},
+		},
+		Conditions: []HPACondition{
+			{Type: "ScalingActive", Status: "False", Reason: "FailedGetExternalMetric", Message: "unable to get external metric"},
+		},
+	}
+
+	result := getHPASummary(hpa)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected hpa summary:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestParseClusterAutoscalerStatus(t *testing.T) {
+	content := `Cluster-autoscaler status at 2021-10-01 12:00:00.000000000 +0000 UTC:
+Cluster-wide:
+  Health:      Healthy (ready=3 unready=0 notStarted=0 longNotStarted=0 registered=3 longUnregistered=0)
+               LastProbeTime:      2021-10-01 12:00:00.000000000 +0000 UTC
+               LastTransitionTime: 2021-10-01 10:00:00.000000000 +0000 UTC
+  ScaleUp:     InProgress (ready=3 registered=3)
+               LastProbeTime:      2021-10-01 12:00:00.000000000 +0000 UTC
+               LastTransitionTime: 2021-10-01 11:59:00.000000000 +0000 UTC
+  ScaleDown:   NoCandidates (candidates=0)
+               LastProbeTime:      2021-10-01 12:00:00.000000000 +0000 UTC
+               LastTransitionTime: 2021-10-01 10:00:00.000000000 +0000 UTC
+
+NodeGroups:
+  Name:        aks-nodepool1-12345678-vmss
+  Health:      Healthy (ready=3 unready=0 notStarted=0 longNotStarted=0 registered=3 longUnregistered=0 cloudProviderTarget=4 (minSize=1, maxSize=5))
+               LastProbeTime:      2021-10-01 12:00:00.000000000 +0000 UTC
+               LastTransitionTime: 2021-10-01 10:00:00.000000000 +0000 UTC
+  ScaleUp:     InProgress (ready=3 cloudProviderTarget=4)
+               LastProbeTime:      2021-10-01 12:00:00.000000000 +0000 UTC
+               LastTransitionTime: 2021-10-01 11:59:00.000000000 +0000 UTC
+  ScaleDown:   NoCandidates (candidates=0)
+               LastProbeTime:      2021-10-01 12:00:00.000000000 +0000 UTC
+               LastTransitionTime: 2021-10-01 10:00:00.000000000 +0000 UTC
+`
+
+	result := parseClusterAutoscalerStatus(content)
+
+	if result.Time != "2021-10-01 12:00:00.000000000 +0000 UTC" {
+		t.Errorf("unexpected time: %s", result.Time)
+	}
+
+	expectedClusterScaleUp := &ClusterAutoscalerCondition{
+		Status:             "InProgress",
+		Counts:             map[string]int64{"ready": 3, "registered": 3},
+		LastProbeTime:      "2021-10-01 12:00:00.000000000 +0000 UTC",
+		LastTransitionTime: "2021-10-01 11:59:00.000000000 +0000 UTC",
+	}
+	if !reflect.DeepEqual(result.ClusterWide.ScaleUp, expectedClusterScaleUp) {
+		t.Errorf("unexpected cluster-wide scale up:\nexpected %+v\nfound    %+v", expectedClusterScaleUp, result.ClusterWide.ScaleUp)
+	}
+
+	if len(result.NodeGroups) != 1 {
+		t.Fatalf("expected 1 node group, found %d", len(result.NodeGroups))
+	}
+	nodeGroup := result.NodeGroups[0]
+	if nodeGroup.Name != "aks-nodepool1-12345678-vmss" {
+		t.Errorf("unexpected node group name: %s", nodeGroup.Name)
+	}
+	expectedCounts := map[string]int64{
+		"ready": 3, "unready": 0, "notStarted": 0, "longNotStarted": 0, "registered": 3, "longUnregistered": 0,
+		"cloudProviderTarget": 4, "minSize": 1, "maxSize": 5,
+	}
+	if nodeGroup.Health == nil || nodeGroup.Health.Status != "Healthy" || !reflect.DeepEqual(nodeGroup.Health.Counts, expectedCounts) {
+		t.Errorf("unexpected node group health: %+v", nodeGroup.Health)
+	}
+	if nodeGroup.ScaleDown == nil || nodeGroup.ScaleDown.Status != "NoCandidates" {
+		t.Errorf("unexpected node group scale down: %+v", nodeGroup.ScaleDown)
+	}
+}
+
+func TestGetVPARecommendation(t *testing.T) {
+	vpa := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"namespace": "app", "name": "web-vpa"},
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web"},
+		},
+		"status": map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{
+					map[string]interface{}{
+						"containerName":  "web",
+						"target":         map[string]interface{}{"cpu": "250m", "memory": "256Mi"},
+						"lowerBound":     map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+						"upperBound":     map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+						"uncappedTarget": map[string]interface{}{"cpu": "250m", "memory": "256Mi"},
+					},
+				},
+			},
+		},
+	}}
+
+	expected := VPARecommendation{
+		Namespace:  "app",
+		Name:       "web-vpa",
+		Target:     "Deployment/web",
+		UpdateMode: "Auto",
+		Containers: []VPAContainerRecommendation{
+			{
+				ContainerName:  "web",
+				Target:         map[string]string{"cpu": "250m", "memory": "256Mi"},
+				LowerBound:     map[string]string{"cpu": "100m", "memory": "128Mi"},
+				UpperBound:     map[string]string{"cpu": "1", "memory": "1Gi"},
+				UncappedTarget: map[string]string{"cpu": "250m", "memory": "256Mi"},
+			},
+		},
+	}
+
+	result := getVPARecommendation(vpa)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected vpa recommendation:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
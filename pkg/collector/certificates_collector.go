@@ -0,0 +1,384 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// defaultCertificateExpiryWindow is used when no certificate expiry window is configured.
+const defaultCertificateExpiryWindow = 30 * 24 * time.Hour
+
+// defaultOsmCABundleSecretName is the name of the secret holding the OSM root certificate, unless overridden
+// by the osm-controller's --ca-bundle-secret-name argument.
+const defaultOsmCABundleSecretName = "osm-ca-bundle"
+
+// nodeCertificateDirectories hold the kubelet's client and serving certificates, and the certificates referenced
+// by the kubelet's kubeconfig.
+var nodeCertificateDirectories = []string{"/var/lib/kubelet/pki", "/etc/kubernetes/certs"}
+
+// secretCertificateKeys are the keys of certificates in kubernetes.io/tls secrets. The tls.key entry is never read.
+var secretCertificateKeys = []string{corev1.TLSCertKey, corev1.ServiceAccountRootCAKey}
+
+// CertificateSource is a single place certificates were found, such as a secret key or a file on the node.
+type CertificateSource struct {
+	Kind         string                  `json:"kind"`
+	Namespace    string                  `json:"namespace,omitempty"`
+	Name         string                  `json:"name"`
+	Key          string                  `json:"key,omitempty"`
+	Certificates []utils.CertificateInfo `json:"certificates"`
+	Error        string                  `json:"error,omitempty"`
+}
+
+// CertificateFinding is a certificate that has expired, is about to expire or is not yet valid.
+type CertificateFinding struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	Key       string    `json:"key,omitempty"`
+	Subject   string    `json:"subject"`
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+	Reason    string    `json:"reason"`
+}
+
+// CertificatesCollector defines a Certificates Collector struct
+type CertificatesCollector struct {
+	data          map[string]string
+	kubeconfig    *rest.Config
+	commandRunner *utils.KubeCommandRunner
+	osIdentifier  utils.OSIdentifier
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewCertificatesCollector is a constructor
+func NewCertificatesCollector(config *rest.Config, osIdentifier utils.OSIdentifier, runtimeInfo *utils.RuntimeInfo) *CertificatesCollector {
+	return &CertificatesCollector{
+		data:          make(map[string]string),
+		kubeconfig:    config,
+		commandRunner: utils.NewKubeCommandRunner(config),
+		osIdentifier:  osIdentifier,
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *CertificatesCollector) GetName() string {
+	return "certificates"
+}
+
+func (collector *CertificatesCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *CertificatesCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	window := collector.runtimeInfo.CertificateExpiryWindow
+	if window <= 0 {
+		window = defaultCertificateExpiryWindow
+	}
+
+	sources := []CertificateSource{}
+
+	secretSources := []CertificateSource{}
+	for _, namespace := range getConfiguredNamespaces(collector.runtimeInfo) {
+		secrets, err := clientset.CoreV1().Secrets(namespace).List(context.Background(), metav1.ListOptions{
+			FieldSelector: "type=" + string(corev1.SecretTypeTLS),
+		})
+		if err != nil {
+			return fmt.Errorf("error listing tls secrets in namespace '%s': %w", namespace, err)
+		}
+		for _, secret := range secrets.Items {
+			secretSources = append(secretSources, getSecretCertificateSources(secret, secretCertificateKeys)...)
+		}
+	}
+	sources = append(sources, secretSources...)
+	if err := collector.setSources("certificates_secrets", secretSources); err != nil {
+		return err
+	}
+
+	webhookSources, err := collector.getWebhookCertificateSources(clientset)
+	if err != nil {
+		return err
+	}
+	sources = append(sources, webhookSources...)
+	if err := collector.setSources("certificates_webhooks", webhookSources); err != nil {
+		return err
+	}
+
+	osmSources, err := getOsmCertificateSources(clientset)
+	if err != nil {
+		return err
+	}
+	sources = append(sources, osmSources...)
+	if err := collector.setSources("certificates_osm", osmSources); err != nil {
+		return err
+	}
+
+	// The node's certificate files are only accessible on a Linux host, and not from connected clusters.
+	if collector.osIdentifier == utils.Linux && !utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		nodeSources := getNodeCertificateSources()
+		sources = append(sources, nodeSources...)
+		if err := collector.setSources("certificates_node", nodeSources); err != nil {
+			return err
+		}
+	}
+
+	findingsBytes, err := json.Marshal(getCertificateFindings(sources, time.Now(), window))
+	if err != nil {
+		return fmt.Errorf("marshall certificate findings to json: %w", err)
+	}
+	collector.data["certificates_findings"] = string(findingsBytes)
+
+	return nil
+}
+
+func (collector *CertificatesCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+func (collector *CertificatesCollector) setSources(key string, sources []CertificateSource) error {
+	sourcesBytes, err := json.Marshal(sources)
+	if err != nil {
+		return fmt.Errorf("marshall %s to json: %w", key, err)
+	}
+	collector.data[key] = string(sourcesBytes)
+	return nil
+}
+
+// getWebhookCertificateSources reads the CA bundles of admission webhooks and APIServices.
+func (collector *CertificatesCollector) getWebhookCertificateSources(clientset *kubernetes.Clientset) ([]CertificateSource, error) {
+	sources := []CertificateSource{}
+
+	validatingConfigs, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing validatingwebhookconfigurations: %w", err)
+	}
+	for _, config := range validatingConfigs.Items {
+		for _, webhook := range config.Webhooks {
+			status := getAdmissionWebhookStatus("ValidatingWebhookConfiguration", config.Name, webhook.Name, webhook.ClientConfig, webhook.FailurePolicy, webhook.TimeoutSeconds)
+			sources = append(sources, getWebhookCertificateSource(status.Kind, status.Configuration, status.Name, status.CABundle, status.CABundleError))
+		}
+	}
+
+	mutatingConfigs, err := clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing mutatingwebhookconfigurations: %w", err)
+	}
+	for _, config := range mutatingConfigs.Items {
+		for _, webhook := range config.Webhooks {
+			status := getAdmissionWebhookStatus("MutatingWebhookConfiguration", config.Name, webhook.Name, webhook.ClientConfig, webhook.FailurePolicy, webhook.TimeoutSeconds)
+			sources = append(sources, getWebhookCertificateSource(status.Kind, status.Configuration, status.Name, status.CABundle, status.CABundleError))
+		}
+	}
+
+	apiServiceGVR := schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+	apiServiceList, err := collector.commandRunner.GetUnstructuredList(&apiServiceGVR, "", &metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing apiservices: %w", err)
+	}
+	for i := range apiServiceList.Items {
+		status := getAPIServiceStatus(&apiServiceList.Items[i])
+		sources = append(sources, getWebhookCertificateSource("APIService", status.Name, "", status.CABundle, status.CABundleError))
+	}
+
+	return sources, nil
+}
+
+func getWebhookCertificateSource(kind, name, key string, caBundle []utils.CertificateInfo, caBundleError string) CertificateSource {
+	return CertificateSource{
+		Kind:         kind,
+		Name:         name,
+		Key:          key,
+		Certificates: caBundle,
+		Error:        caBundleError,
+	}
+}
+
+// getOsmCertificateSources reads the root certificate of each OSM mesh from the CA bundle secret of its controller.
+func getOsmCertificateSources(clientset *kubernetes.Clientset) ([]CertificateSource, error) {
+	sources := []CertificateSource{}
+
+	deployments, err := clientset.AppsV1().Deployments("").List(context.Background(), metav1.ListOptions{
+		LabelSelector: "app=osm-controller",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing osm-controller deployments in all namespaces: %w", err)
+	}
+
+	for _, deployment := range deployments.Items {
+		secretName := getOsmCABundleSecretName(deployment.Spec.Template.Spec.Containers)
+
+		// Listing with a field selector only needs the 'list' permission, which Periscope already has for secrets.
+		secrets, err := clientset.CoreV1().Secrets(deployment.Namespace).List(context.Background(), metav1.ListOptions{
+			FieldSelector: "metadata.name=" + secretName,
+		})
+		if err != nil {
+			sources = append(sources, CertificateSource{
+				Kind:         "OSMRootCertificate",
+				Namespace:    deployment.Namespace,
+				Name:         secretName,
+				Certificates: []utils.CertificateInfo{},
+				Error:        fmt.Sprintf("error listing secret: %v", err),
+			})
+			continue
+		}
+		for _, secret := range secrets.Items {
+			for _, source := range getSecretCertificateSources(secret, []string{corev1.ServiceAccountRootCAKey}) {
+				source.Kind = "OSMRootCertificate"
+				sources = append(sources, source)
+			}
+		}
+	}
+
+	return sources, nil
+}
+
+func getOsmCABundleSecretName(containers []corev1.Container) string {
+	for _, container := range containers {
+		for _, arg := range container.Args {
+			if strings.HasPrefix(arg, "--ca-bundle-secret-name=") {
+				return strings.TrimPrefix(arg, "--ca-bundle-secret-name=")
+			}
+		}
+	}
+	return defaultOsmCABundleSecretName
+}
+
+// getNodeCertificateSources reads the certificates in the node's certificate directories. Files are read in full,
+// because the kubelet keeps its certificate and key in the same file, but only the certificates are parsed.
+func getNodeCertificateSources() []CertificateSource {
+	sources := []CertificateSource{}
+
+	// Each directory is listed separately, because one that is missing fails the whole find command.
+	paths := []string{}
+	for _, directory := range nodeCertificateDirectories {
+		output, err := utils.RunCommandOnHost("find", directory, "-maxdepth", "1", "(", "-name", "*.crt", "-o", "-name", "*.pem", ")")
+		if err != nil {
+			log.Printf("Failed to list node certificate files in %s: %+v", directory, err)
+			sources = append(sources, CertificateSource{
+				Kind:         "NodeDirectory",
+				Name:         directory,
+				Certificates: []utils.CertificateInfo{},
+				Error:        fmt.Sprintf("failed to list certificate files: %v", err),
+			})
+			continue
+		}
+		paths = append(paths, strings.Fields(output)...)
+	}
+
+	for _, path := range paths {
+		content, err := utils.RunCommandOnHost("cat", path)
+		if err != nil {
+			log.Printf("Failed to read node certificate file %s: %+v", path, err)
+			sources = append(sources, CertificateSource{
+				Kind:         "NodeFile",
+				Name:         path,
+				Certificates: []utils.CertificateInfo{},
+				Error:        fmt.Sprintf("failed to read file: %v", err),
+			})
+			continue
+		}
+
+		source := getFileCertificateSource(path, []byte(content))
+		// Files containing only keys have no certificates worth reporting.
+		if len(source.Certificates) > 0 || len(source.Error) > 0 {
+			sources = append(sources, source)
+		}
+	}
+
+	return sources
+}
+
+func getFileCertificateSource(path string, content []byte) CertificateSource {
+	source := CertificateSource{
+		Kind:         "NodeFile",
+		Name:         path,
+		Certificates: []utils.CertificateInfo{},
+	}
+
+	infos, err := utils.GetCertificateInfos(content)
+	if err != nil {
+		source.Error = err.Error()
+	} else {
+		source.Certificates = infos
+	}
+
+	return source
+}
+
+// getSecretCertificateSources parses the certificates in the given keys of a secret.
+func getSecretCertificateSources(secret corev1.Secret, keys []string) []CertificateSource {
+	sources := []CertificateSource{}
+	for _, key := range keys {
+		content, ok := secret.Data[key]
+		if !ok || len(content) == 0 {
+			continue
+		}
+
+		source := CertificateSource{
+			Kind:         "Secret",
+			Namespace:    secret.Namespace,
+			Name:         secret.Name,
+			Key:          key,
+			Certificates: []utils.CertificateInfo{},
+		}
+
+		infos, err := utils.GetCertificateInfos(content)
+		if err != nil {
+			source.Error = err.Error()
+		} else {
+			source.Certificates = infos
+		}
+
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// getCertificateFindings returns the certificates that have expired, will expire within the window, or are not yet valid.
+func getCertificateFindings(sources []CertificateSource, now time.Time, window time.Duration) []CertificateFinding {
+	findings := []CertificateFinding{}
+	for _, source := range sources {
+		for _, cert := range source.Certificates {
+			var reason string
+			switch {
+			case now.After(cert.NotAfter):
+				reason = fmt.Sprintf("expired %s ago", now.Sub(cert.NotAfter).Round(time.Hour))
+			case now.Add(window).After(cert.NotAfter):
+				reason = fmt.Sprintf("expires in %s", cert.NotAfter.Sub(now).Round(time.Hour))
+			case now.Before(cert.NotBefore):
+				reason = fmt.Sprintf("not valid for another %s", cert.NotBefore.Sub(now).Round(time.Minute))
+			default:
+				continue
+			}
+
+			findings = append(findings, CertificateFinding{
+				Kind:      source.Kind,
+				Namespace: source.Namespace,
+				Name:      source.Name,
+				Key:       source.Key,
+				Subject:   cert.Subject,
+				NotBefore: cert.NotBefore,
+				NotAfter:  cert.NotAfter,
+				Reason:    reason,
+			})
+		}
+	}
+	return findings
+}
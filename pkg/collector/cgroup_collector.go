@@ -0,0 +1,460 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// CgroupStats holds the resource accounting for a single pod or container cgroup.
+type CgroupStats struct {
+	Path               string `json:"path"`
+	Level              string `json:"level"`
+	PodUID             string `json:"podUID"`
+	ContainerID        string `json:"containerID"`
+	Namespace          string `json:"namespace"`
+	PodName            string `json:"pod"`
+	ContainerName      string `json:"container"`
+	MemoryCurrentBytes int64  `json:"memoryCurrentBytes"`
+	MemoryMaxBytes     int64  `json:"memoryMaxBytes"` // -1 if unlimited
+	OOMEvents          int64  `json:"oomEvents"`
+	OOMKills           int64  `json:"oomKills"`
+	CPUUsageUsec       int64  `json:"cpuUsageUsec"`
+	CPUPeriods         int64  `json:"cpuPeriods"`
+	CPUThrottled       int64  `json:"cpuThrottled"`
+	CPUThrottledUsec   int64  `json:"cpuThrottledUsec"`
+	IOReadBytes        int64  `json:"ioReadBytes"`
+	IOWriteBytes       int64  `json:"ioWriteBytes"`
+	IOReadOps          int64  `json:"ioReadOps"`
+	IOWriteOps         int64  `json:"ioWriteOps"`
+}
+
+// CgroupSummary is the structured output of the CgroupCollector.
+type CgroupSummary struct {
+	Version int           `json:"version"`
+	Cgroups []CgroupStats `json:"cgroups"`
+}
+
+type criPodMetadata struct {
+	namespace string
+	name      string
+}
+
+type criContainerMetadata struct {
+	podUID    string
+	namespace string
+	podName   string
+	name      string
+}
+
+// criMetadata maps the identifiers found in cgroup paths to Kubernetes identities.
+type criMetadata struct {
+	podsByUID       map[string]criPodMetadata
+	podsBySandboxID map[string]criPodMetadata
+	containersByID  map[string]criContainerMetadata
+}
+
+// CgroupCollector defines a Cgroup Collector struct
+type CgroupCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	runtimeInfo  *utils.RuntimeInfo
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+}
+
+// NewCgroupCollector is a constructor
+func NewCgroupCollector(osIdentifier utils.OSIdentifier, runtimeInfo *utils.RuntimeInfo, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor) *CgroupCollector {
+	return &CgroupCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		runtimeInfo:  runtimeInfo,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+	}
+}
+
+func (collector *CgroupCollector) GetName() string {
+	return "cgroups"
+}
+
+func (collector *CgroupCollector) CheckSupported() error {
+	// Cgroups are a Linux kernel feature. Windows uses job objects, which we can't inspect from a container.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *CgroupCollector) Collect() error {
+	summary, err := readCgroupSummary(collector.fileSystem, collector.filePaths.CgroupRoot)
+	if err != nil {
+		return fmt.Errorf("error reading kubepods cgroups: %w", err)
+	}
+
+	// Map the cgroup identifiers back to Kubernetes identities. If the CRI isn't available we still
+	// have the pod UIDs and container IDs, so don't fail the collection.
+	runtime := findContainerRuntime()
+	metadata, err := getCriMetadata(runtime.Endpoint)
+	if err != nil {
+		log.Printf("Failed to get pod and container metadata from CRI: %v", err)
+	} else {
+		summary.applyCriMetadata(metadata)
+	}
+
+	summaryBytes, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("marshall cgroup stats to json: %w", err)
+	}
+	collector.data["cgroup_stats"] = string(summaryBytes)
+
+	return nil
+}
+
+func (collector *CgroupCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// readCgroupSummary reads the accounting for all pod and container cgroups under the kubepods hierarchy.
+// With cgroup v2 there is a single unified hierarchy, whereas with v1 each controller has its own.
+func readCgroupSummary(fileSystem interfaces.FileSystemAccessor, cgroupRoot string) (*CgroupSummary, error) {
+	isV2, err := fileSystem.FileExists(path.Join(cgroupRoot, "cgroup.controllers"))
+	if err != nil {
+		return nil, err
+	}
+
+	cgroups := map[string]*CgroupStats{}
+	if isV2 {
+		err = readCgroupController(fileSystem, cgroupRoot, cgroups, readCgroupV2Files)
+	} else {
+		for _, controller := range []string{"memory", "cpu,cpuacct", "blkio"} {
+			controllerErr := readCgroupController(fileSystem, path.Join(cgroupRoot, controller), cgroups, readCgroupV1Files)
+			if controllerErr != nil {
+				err = controllerErr
+			}
+		}
+	}
+
+	if len(cgroups) == 0 && err != nil {
+		return nil, err
+	}
+
+	summary := &CgroupSummary{Version: 1, Cgroups: []CgroupStats{}}
+	if isV2 {
+		summary.Version = 2
+	}
+
+	paths := make([]string, 0, len(cgroups))
+	for cgroupPath := range cgroups {
+		paths = append(paths, cgroupPath)
+	}
+	sort.Strings(paths)
+	for _, cgroupPath := range paths {
+		summary.Cgroups = append(summary.Cgroups, *cgroups[cgroupPath])
+	}
+
+	return summary, nil
+}
+
+type cgroupFileReader func(fileSystem interfaces.FileSystemAccessor, fileName, filePath string, stats *CgroupStats) error
+
+// readCgroupController finds the pod and container cgroups beneath the kubepods cgroup of a hierarchy, and
+// reads the files within each one into the stats for that cgroup (keyed by path relative to the hierarchy).
+func readCgroupController(fileSystem interfaces.FileSystemAccessor, hierarchyRoot string, cgroups map[string]*CgroupStats, readFile cgroupFileReader) error {
+	// The kubepods cgroup is named according to the kubelet's cgroup driver (systemd or cgroupfs).
+	var kubepodsRoot string
+	for _, name := range []string{"kubepods.slice", "kubepods"} {
+		candidate := path.Join(hierarchyRoot, name)
+		files, err := fileSystem.ListFiles(candidate)
+		if err == nil && len(files) > 0 {
+			kubepodsRoot = candidate
+			break
+		}
+	}
+	if kubepodsRoot == "" {
+		return fmt.Errorf("no kubepods cgroup found in %s", hierarchyRoot)
+	}
+
+	files, err := fileSystem.ListFiles(kubepodsRoot)
+	if err != nil {
+		return err
+	}
+
+	for _, filePath := range files {
+		cgroupPath := strings.TrimPrefix(path.Dir(filePath), hierarchyRoot)
+		podUID, containerID := parseCgroupPath(cgroupPath)
+		if podUID == "" {
+			// This is a QoS-class cgroup, or the kubepods cgroup itself.
+			continue
+		}
+
+		stats, ok := cgroups[cgroupPath]
+		if !ok {
+			stats = &CgroupStats{Path: cgroupPath, PodUID: podUID, ContainerID: containerID, Level: "pod", MemoryMaxBytes: -1}
+			if containerID != "" {
+				stats.Level = "container"
+			}
+			cgroups[cgroupPath] = stats
+		}
+
+		if err := readFile(fileSystem, path.Base(filePath), filePath, stats); err != nil {
+			log.Printf("Failed to read cgroup file %s: %v", filePath, err)
+		}
+	}
+
+	return nil
+}
+
+func readCgroupV2Files(fileSystem interfaces.FileSystemAccessor, fileName, filePath string, stats *CgroupStats) error {
+	switch fileName {
+	case "memory.current":
+		return readCgroupValue(fileSystem, filePath, &stats.MemoryCurrentBytes)
+	case "memory.max":
+		return readCgroupValue(fileSystem, filePath, &stats.MemoryMaxBytes)
+	case "memory.events":
+		return readCgroupKeyValues(fileSystem, filePath, map[string]*int64{
+			"oom":      &stats.OOMEvents,
+			"oom_kill": &stats.OOMKills,
+		})
+	case "cpu.stat":
+		return readCgroupKeyValues(fileSystem, filePath, map[string]*int64{
+			"usage_usec":     &stats.CPUUsageUsec,
+			"nr_periods":     &stats.CPUPeriods,
+			"nr_throttled":   &stats.CPUThrottled,
+			"throttled_usec": &stats.CPUThrottledUsec,
+		})
+	case "io.stat":
+		content, err := readCgroupFile(fileSystem, filePath)
+		if err != nil {
+			return err
+		}
+		// Each line is a device followed by key=value pairs, e.g. "8:0 rbytes=1024 wbytes=0 rios=1 wios=0 ..."
+		for _, line := range strings.Split(content, "\n") {
+			for _, pair := range strings.Fields(line) {
+				parts := strings.SplitN(pair, "=", 2)
+				if len(parts) != 2 {
+					continue
+				}
+				value, _ := strconv.ParseInt(parts[1], 10, 64)
+				switch parts[0] {
+				case "rbytes":
+					stats.IOReadBytes += value
+				case "wbytes":
+					stats.IOWriteBytes += value
+				case "rios":
+					stats.IOReadOps += value
+				case "wios":
+					stats.IOWriteOps += value
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func readCgroupV1Files(fileSystem interfaces.FileSystemAccessor, fileName, filePath string, stats *CgroupStats) error {
+	switch fileName {
+	case "memory.usage_in_bytes":
+		return readCgroupValue(fileSystem, filePath, &stats.MemoryCurrentBytes)
+	case "memory.limit_in_bytes":
+		if err := readCgroupValue(fileSystem, filePath, &stats.MemoryMaxBytes); err != nil {
+			return err
+		}
+		// An unlimited v1 memory cgroup reports a very large page-aligned value rather than 'max'.
+		if stats.MemoryMaxBytes >= 1<<62 {
+			stats.MemoryMaxBytes = -1
+		}
+	case "memory.oom_control":
+		if err := readCgroupKeyValues(fileSystem, filePath, map[string]*int64{"oom_kill": &stats.OOMKills}); err != nil {
+			return err
+		}
+		stats.OOMEvents = stats.OOMKills
+	case "cpu.stat":
+		var throttledNanoseconds int64
+		if err := readCgroupKeyValues(fileSystem, filePath, map[string]*int64{
+			"nr_periods":     &stats.CPUPeriods,
+			"nr_throttled":   &stats.CPUThrottled,
+			"throttled_time": &throttledNanoseconds,
+		}); err != nil {
+			return err
+		}
+		stats.CPUThrottledUsec = throttledNanoseconds / 1000
+	case "cpuacct.usage":
+		var usageNanoseconds int64
+		if err := readCgroupValue(fileSystem, filePath, &usageNanoseconds); err != nil {
+			return err
+		}
+		stats.CPUUsageUsec = usageNanoseconds / 1000
+	case "blkio.throttle.io_service_bytes", "blkio.throttle.io_serviced":
+		content, err := readCgroupFile(fileSystem, filePath)
+		if err != nil {
+			return err
+		}
+		readTarget, writeTarget := &stats.IOReadBytes, &stats.IOWriteBytes
+		if fileName == "blkio.throttle.io_serviced" {
+			readTarget, writeTarget = &stats.IOReadOps, &stats.IOWriteOps
+		}
+		// Each line is a device, operation and value, e.g. "8:0 Read 1024", with a final "Total" line.
+		for _, line := range strings.Split(content, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 3 {
+				continue
+			}
+			value, _ := strconv.ParseInt(fields[2], 10, 64)
+			switch fields[1] {
+			case "Read":
+				*readTarget += value
+			case "Write":
+				*writeTarget += value
+			}
+		}
+	}
+	return nil
+}
+
+func readCgroupFile(fileSystem interfaces.FileSystemAccessor, filePath string) (string, error) {
+	return utils.GetContent(func() (io.ReadCloser, error) { return fileSystem.GetFileReader(filePath) })
+}
+
+// readCgroupValue reads a file containing a single value, where 'max' represents no limit.
+func readCgroupValue(fileSystem interfaces.FileSystemAccessor, filePath string, target *int64) error {
+	content, err := readCgroupFile(fileSystem, filePath)
+	if err != nil {
+		return err
+	}
+
+	value := strings.TrimSpace(content)
+	if value == "max" {
+		*target = -1
+		return nil
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("unexpected value in %s: %s", filePath, value)
+	}
+	*target = parsed
+	return nil
+}
+
+// readCgroupKeyValues reads the specified keys from a file of space-separated key/value lines.
+func readCgroupKeyValues(fileSystem interfaces.FileSystemAccessor, filePath string, targets map[string]*int64) error {
+	content, err := readCgroupFile(fileSystem, filePath)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if target, ok := targets[fields[0]]; ok {
+			*target, _ = strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+	return nil
+}
+
+func getCriMetadata(endpoint string) (*criMetadata, error) {
+	containersJson, err := runCrictl(endpoint, "ps", "-a", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("error listing containers: %w", err)
+	}
+
+	podsJson, err := runCrictl(endpoint, "pods", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods: %w", err)
+	}
+
+	return parseCriMetadata(containersJson, podsJson)
+}
+
+// parseCriMetadata reads the output of `crictl ps -o json` and `crictl pods -o json`.
+func parseCriMetadata(containersJson, podsJson string) (*criMetadata, error) {
+	var containers struct {
+		Containers []struct {
+			ID     string            `json:"id"`
+			Labels map[string]string `json:"labels"`
+		} `json:"containers"`
+	}
+	if err := json.Unmarshal([]byte(containersJson), &containers); err != nil {
+		return nil, fmt.Errorf("error parsing containers: %w", err)
+	}
+
+	var pods struct {
+		Items []struct {
+			ID       string `json:"id"`
+			Metadata struct {
+				Name      string `json:"name"`
+				UID       string `json:"uid"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(podsJson), &pods); err != nil {
+		return nil, fmt.Errorf("error parsing pods: %w", err)
+	}
+
+	metadata := &criMetadata{
+		podsByUID:       map[string]criPodMetadata{},
+		podsBySandboxID: map[string]criPodMetadata{},
+		containersByID:  map[string]criContainerMetadata{},
+	}
+
+	for _, pod := range pods.Items {
+		podMetadata := criPodMetadata{namespace: pod.Metadata.Namespace, name: pod.Metadata.Name}
+		metadata.podsByUID[pod.Metadata.UID] = podMetadata
+		metadata.podsBySandboxID[pod.ID] = podMetadata
+	}
+
+	for _, container := range containers.Containers {
+		metadata.containersByID[container.ID] = criContainerMetadata{
+			podUID:    container.Labels["io.kubernetes.pod.uid"],
+			namespace: container.Labels["io.kubernetes.pod.namespace"],
+			podName:   container.Labels["io.kubernetes.pod.name"],
+			name:      container.Labels["io.kubernetes.container.name"],
+		}
+	}
+
+	return metadata, nil
+}
+
+func (summary *CgroupSummary) applyCriMetadata(metadata *criMetadata) {
+	for i := range summary.Cgroups {
+		stats := &summary.Cgroups[i]
+		if pod, ok := metadata.podsByUID[stats.PodUID]; ok {
+			stats.Namespace = pod.namespace
+			stats.PodName = pod.name
+		}
+
+		if stats.ContainerID == "" {
+			continue
+		}
+
+		if container, ok := metadata.containersByID[stats.ContainerID]; ok {
+			stats.Namespace = container.namespace
+			stats.PodName = container.podName
+			stats.ContainerName = container.name
+		} else if pod, ok := metadata.podsBySandboxID[stats.ContainerID]; ok {
+			// The pod sandbox ('pause') container has its own cgroup.
+			stats.Namespace = pod.namespace
+			stats.PodName = pod.name
+			stats.ContainerName = "POD"
+		}
+	}
+}
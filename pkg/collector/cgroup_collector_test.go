@@ -0,0 +1,225 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+const (
+	testCgroupPodUID      = "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+	testCgroupContainerID = "3d6f2f4c0c3a0f1e5a5d9a4b1e2c3d4e5f60718293a4b5c6d7e8f90123456789"
+	testCgroupSandboxID   = "9a8b7c6d5e4f30211203f4e5d6c7b8a99a8b7c6d5e4f30211203f4e5d6c7b8a9"
+)
+
+func TestCgroupCollectorGetName(t *testing.T) {
+	const expectedName = "cgroups"
+
+	c := NewCgroupCollector("", nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestCgroupCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewCgroupCollector(tt.osIdentifier, runtimeInfo, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReadCgroupSummaryV2(t *testing.T) {
+	podPath := "/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice"
+	containerPath := podPath + "/cri-containerd-" + testCgroupContainerID + ".scope"
+	fileSystem := test.NewFakeFileSystem(map[string]string{
+		"/cgroup/cgroup.controllers":                               "cpuset cpu io memory pids",
+		"/cgroup/kubepods.slice/memory.current":                    "999999",
+		"/cgroup/kubepods.slice/kubepods-burstable.slice/cpu.stat": "usage_usec 1",
+		podPath + "/memory.current":                                "2048\n",
+		podPath + "/memory.max":                                    "max\n",
+		containerPath + "/memory.current":                          "1024\n",
+		containerPath + "/memory.max":                              "4096\n",
+		containerPath + "/memory.events":                           "low 0\nhigh 0\nmax 12\noom 3\noom_kill 2\n",
+		containerPath + "/cpu.stat":                                "usage_usec 5000\nuser_usec 4000\nsystem_usec 1000\nnr_periods 100\nnr_throttled 25\nthrottled_usec 7500\n",
+		containerPath + "/io.stat":                                 "8:0 rbytes=1000 wbytes=2000 rios=10 wios=20 dbytes=0 dios=0\n8:16 rbytes=1 wbytes=2 rios=3 wios=4 dbytes=0 dios=0\n",
+	})
+
+	summary, err := readCgroupSummary(fileSystem, "/cgroup")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if summary.Version != 2 {
+		t.Errorf("unexpected version: expected 2, found %d", summary.Version)
+	}
+	if len(summary.Cgroups) != 2 {
+		t.Fatalf("unexpected cgroup count: expected 2, found %d: %+v", len(summary.Cgroups), summary.Cgroups)
+	}
+
+	pod := summary.Cgroups[0]
+	if pod.Level != "pod" || pod.PodUID != testCgroupPodUID || pod.MemoryCurrentBytes != 2048 || pod.MemoryMaxBytes != -1 {
+		t.Errorf("unexpected pod cgroup: %+v", pod)
+	}
+
+	container := summary.Cgroups[1]
+	expected := CgroupStats{
+		Path:               containerPath[len("/cgroup"):],
+		Level:              "container",
+		PodUID:             testCgroupPodUID,
+		ContainerID:        testCgroupContainerID,
+		MemoryCurrentBytes: 1024,
+		MemoryMaxBytes:     4096,
+		OOMEvents:          3,
+		OOMKills:           2,
+		CPUUsageUsec:       5000,
+		CPUPeriods:         100,
+		CPUThrottled:       25,
+		CPUThrottledUsec:   7500,
+		IOReadBytes:        1001,
+		IOWriteBytes:       2002,
+		IOReadOps:          13,
+		IOWriteOps:         24,
+	}
+	if container != expected {
+		t.Errorf("unexpected container cgroup:\nexpected %+v\nfound    %+v", expected, container)
+	}
+}
+
+func TestReadCgroupSummaryV1(t *testing.T) {
+	containerPath := "/kubepods/besteffort/pod" + testCgroupPodUID + "/" + testCgroupContainerID
+	fileSystem := test.NewFakeFileSystem(map[string]string{
+		"/cgroup/memory" + containerPath + "/memory.usage_in_bytes":            "1024\n",
+		"/cgroup/memory" + containerPath + "/memory.limit_in_bytes":            "9223372036854771712\n",
+		"/cgroup/memory" + containerPath + "/memory.oom_control":               "oom_kill_disable 0\nunder_oom 0\noom_kill 1\n",
+		"/cgroup/cpu,cpuacct" + containerPath + "/cpu.stat":                    "nr_periods 10\nnr_throttled 4\nthrottled_time 3000000\n",
+		"/cgroup/cpu,cpuacct" + containerPath + "/cpuacct.usage":               "2000000\n",
+		"/cgroup/blkio" + containerPath + "/blkio.throttle.io_service_bytes":   "8:0 Read 100\n8:0 Write 200\n8:0 Sync 300\n8:0 Async 0\n8:0 Total 300\nTotal 300\n",
+		"/cgroup/blkio" + containerPath + "/blkio.throttle.io_serviced":        "8:0 Read 1\n8:0 Write 2\n8:0 Total 3\nTotal 3\n",
+		"/cgroup/memory/kubepods/besteffort/memory.usage_in_bytes":             "999999\n",
+		"/cgroup/memory/system.slice/containerd.service/memory.usage_in_bytes": "999999\n",
+	})
+
+	summary, err := readCgroupSummary(fileSystem, "/cgroup")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if summary.Version != 1 {
+		t.Errorf("unexpected version: expected 1, found %d", summary.Version)
+	}
+	if len(summary.Cgroups) != 1 {
+		t.Fatalf("unexpected cgroup count: expected 1, found %d: %+v", len(summary.Cgroups), summary.Cgroups)
+	}
+
+	expected := CgroupStats{
+		Path:               containerPath,
+		Level:              "container",
+		PodUID:             testCgroupPodUID,
+		ContainerID:        testCgroupContainerID,
+		MemoryCurrentBytes: 1024,
+		MemoryMaxBytes:     -1,
+		OOMEvents:          1,
+		OOMKills:           1,
+		CPUUsageUsec:       2000,
+		CPUPeriods:         10,
+		CPUThrottled:       4,
+		CPUThrottledUsec:   3000,
+		IOReadBytes:        100,
+		IOWriteBytes:       200,
+		IOReadOps:          1,
+		IOWriteOps:         2,
+	}
+	if summary.Cgroups[0] != expected {
+		t.Errorf("unexpected container cgroup:\nexpected %+v\nfound    %+v", expected, summary.Cgroups[0])
+	}
+}
+
+func TestReadCgroupSummaryNoKubepods(t *testing.T) {
+	fileSystem := test.NewFakeFileSystem(map[string]string{
+		"/cgroup/cgroup.controllers":                       "cpuset cpu io memory pids",
+		"/cgroup/system.slice/containerd.service/cpu.stat": "usage_usec 1",
+	})
+
+	if _, err := readCgroupSummary(fileSystem, "/cgroup"); err == nil {
+		t.Errorf("expected error when no kubepods cgroup exists")
+	}
+}
+
+func TestApplyCriMetadata(t *testing.T) {
+	containersJson := `{"containers":[{"id":"` + testCgroupContainerID + `","podSandboxId":"` + testCgroupSandboxID + `","labels":{"io.kubernetes.container.name":"app","io.kubernetes.pod.name":"web-0","io.kubernetes.pod.namespace":"default","io.kubernetes.pod.uid":"` + testCgroupPodUID + `"}}]}`
+	podsJson := `{"items":[{"id":"` + testCgroupSandboxID + `","metadata":{"name":"web-0","uid":"` + testCgroupPodUID + `","namespace":"default"}}]}`
+
+	metadata, err := parseCriMetadata(containersJson, podsJson)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	summary := &CgroupSummary{
+		Version: 2,
+		Cgroups: []CgroupStats{
+			{Level: "pod", PodUID: testCgroupPodUID},
+			{Level: "container", PodUID: testCgroupPodUID, ContainerID: testCgroupContainerID},
+			{Level: "container", PodUID: testCgroupPodUID, ContainerID: testCgroupSandboxID},
+			{Level: "pod", PodUID: "00000000-0000-0000-0000-000000000000"},
+		},
+	}
+	summary.applyCriMetadata(metadata)
+
+	expected := []struct {
+		namespace string
+		pod       string
+		container string
+	}{
+		{namespace: "default", pod: "web-0", container: ""},
+		{namespace: "default", pod: "web-0", container: "app"},
+		{namespace: "default", pod: "web-0", container: "POD"},
+		{namespace: "", pod: "", container: ""},
+	}
+
+	for i, e := range expected {
+		stats := summary.Cgroups[i]
+		if stats.Namespace != e.namespace || stats.PodName != e.pod || stats.ContainerName != e.container {
+			t.Errorf("unexpected metadata for cgroup %d: expected %s/%s/%s, found %s/%s/%s", i, e.namespace, e.pod, e.container, stats.Namespace, stats.PodName, stats.ContainerName)
+		}
+	}
+}
+
+func TestParseCriMetadataInvalid(t *testing.T) {
+	if _, err := parseCriMetadata("not json", `{"items":[]}`); err == nil {
+		t.Errorf("expected error for invalid containers output")
+	}
+}
@@ -0,0 +1,178 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+const (
+	defaultContainerRuntimeEndpoint = "unix:///run/containerd/containerd.sock"
+	containerdConfigPath            = "/etc/containerd/config.toml"
+)
+
+var (
+	kubeletRuntimeEndpointFlagRegex   = regexp.MustCompile(`--container-runtime-endpoint[= ](\S+)`)
+	kubeletConfigFlagRegex            = regexp.MustCompile(`--config[= ](\S+)`)
+	kubeletConfigRuntimeEndpointRegex = regexp.MustCompile(`"?containerRuntimeEndpoint"?\s*:\s*"?([^"\s,]+)`)
+)
+
+// ContainerRuntimeInfo describes the container runtime used by the kubelet, and how it was identified.
+type ContainerRuntimeInfo struct {
+	Runtime  string `json:"runtime"`
+	Endpoint string `json:"endpoint"`
+	Source   string `json:"source"`
+}
+
+// ContainerRuntimeCollector defines a ContainerRuntime Collector struct
+type ContainerRuntimeCollector struct {
+	RuntimeInfo  *ContainerRuntimeInfo
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewContainerRuntimeCollector is a constructor
+func NewContainerRuntimeCollector(osIdentifier utils.OSIdentifier, runtimeInfo *utils.RuntimeInfo) *ContainerRuntimeCollector {
+	return &ContainerRuntimeCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *ContainerRuntimeCollector) GetName() string {
+	return "containerruntime"
+}
+
+func (collector *ContainerRuntimeCollector) CheckSupported() error {
+	// This relies on running `crictl` on the host, which we can't do from a Windows container.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *ContainerRuntimeCollector) Collect() error {
+	collector.RuntimeInfo = findContainerRuntime()
+
+	infoBytes, err := json.Marshal(collector.RuntimeInfo)
+	if err != nil {
+		return fmt.Errorf("marshall container runtime info to json: %w", err)
+	}
+	collector.data["containerruntime"] = string(infoBytes)
+
+	crictlQueries := []struct {
+		collectorKey string
+		args         []string
+	}{
+		{collectorKey: "crictl_info", args: []string{"info"}},
+		{collectorKey: "crictl_containers", args: []string{"ps", "-a", "-o", "json"}},
+		{collectorKey: "crictl_pods", args: []string{"pods", "-o", "json"}},
+		{collectorKey: "crictl_images", args: []string{"images", "-o", "json"}},
+		{collectorKey: "crictl_stats", args: []string{"stats", "-a", "-o", "json"}},
+	}
+
+	for _, query := range crictlQueries {
+		output, err := runCrictl(collector.RuntimeInfo.Endpoint, query.args...)
+		if err != nil {
+			output = fmt.Sprintf("Failed to run crictl %s: %+v\n", strings.Join(query.args, " "), err)
+			log.Print(output)
+		}
+		collector.data[query.collectorKey] = output
+	}
+
+	if collector.RuntimeInfo.Runtime == "containerd" {
+		output, err := utils.RunCommandOnHost("cat", containerdConfigPath)
+		if err != nil {
+			output = fmt.Sprintf("Failed to read %s: %+v\n", containerdConfigPath, err)
+			log.Print(output)
+		}
+		collector.data["containerd_config"] = output
+	}
+
+	return nil
+}
+
+func (collector *ContainerRuntimeCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// findContainerRuntime identifies the container runtime endpoint from the kubelet's command-line flags,
+// falling back to the kubelet config file (newer kubelets), and finally to the containerd default.
+func findContainerRuntime() *ContainerRuntimeInfo {
+	kubeletCommand, err := utils.RunCommandOnHost("ps", "-o", "cmd=", "-C", "kubelet")
+	if err != nil {
+		log.Printf("Failed to read kubelet command, assuming default container runtime: %v", err)
+		return newContainerRuntimeInfo(defaultContainerRuntimeEndpoint, "default")
+	}
+
+	if endpoint := getRuntimeEndpointFromKubeletCommand(kubeletCommand); endpoint != "" {
+		return newContainerRuntimeInfo(endpoint, "kubelet flags")
+	}
+
+	if match := kubeletConfigFlagRegex.FindStringSubmatch(kubeletCommand); match != nil {
+		configContent, err := utils.RunCommandOnHost("cat", match[1])
+		if err != nil {
+			log.Printf("Failed to read kubelet config file %s: %v", match[1], err)
+		} else if endpoint := getRuntimeEndpointFromKubeletConfig(configContent); endpoint != "" {
+			return newContainerRuntimeInfo(endpoint, "kubelet config")
+		}
+	}
+
+	return newContainerRuntimeInfo(defaultContainerRuntimeEndpoint, "default")
+}
+
+func newContainerRuntimeInfo(endpoint, source string) *ContainerRuntimeInfo {
+	return &ContainerRuntimeInfo{
+		Runtime:  getRuntimeNameFromEndpoint(endpoint),
+		Endpoint: endpoint,
+		Source:   source,
+	}
+}
+
+func getRuntimeEndpointFromKubeletCommand(kubeletCommand string) string {
+	match := kubeletRuntimeEndpointFlagRegex.FindStringSubmatch(kubeletCommand)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func getRuntimeEndpointFromKubeletConfig(configContent string) string {
+	match := kubeletConfigRuntimeEndpointRegex.FindStringSubmatch(configContent)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func getRuntimeNameFromEndpoint(endpoint string) string {
+	switch {
+	case strings.Contains(endpoint, "containerd"):
+		return "containerd"
+	case strings.Contains(endpoint, "dockershim"), strings.Contains(endpoint, "docker"):
+		return "docker"
+	case strings.Contains(endpoint, "crio"):
+		return "cri-o"
+	default:
+		return "unknown"
+	}
+}
+
+// runCrictl runs a crictl command on the host against the specified CRI endpoint.
+func runCrictl(endpoint string, args ...string) (string, error) {
+	crictlArgs := append([]string{"--runtime-endpoint", endpoint}, args...)
+	return utils.RunCommandOnHost("crictl", crictlArgs...)
+}
@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestContainerRuntimeCollectorGetName(t *testing.T) {
+	const expectedName = "containerruntime"
+
+	c := NewContainerRuntimeCollector("", nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestContainerRuntimeCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewContainerRuntimeCollector(tt.osIdentifier, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetRuntimeEndpoint(t *testing.T) {
+	tests := []struct {
+		name           string
+		kubeletCommand string
+		kubeletConfig  string
+		wantEndpoint   string
+		wantRuntime    string
+	}{
+		{
+			name:           "endpoint in kubelet flags",
+			kubeletCommand: "/usr/local/bin/kubelet --max-pods=30 --container-runtime=remote --container-runtime-endpoint=unix:///run/containerd/containerd.sock --v=2",
+			wantEndpoint:   "unix:///run/containerd/containerd.sock",
+			wantRuntime:    "containerd",
+		},
+		{
+			name:           "space-separated kubelet flag",
+			kubeletCommand: "/usr/local/bin/kubelet --container-runtime-endpoint unix:///var/run/dockershim.sock",
+			wantEndpoint:   "unix:///var/run/dockershim.sock",
+			wantRuntime:    "docker",
+		},
+		{
+			name:          "endpoint in yaml kubelet config",
+			kubeletConfig: "kind: KubeletConfiguration\ncontainerRuntimeEndpoint: unix:///var/run/crio/crio.sock\nmaxPods: 110\n",
+			wantEndpoint:  "unix:///var/run/crio/crio.sock",
+			wantRuntime:   "cri-o",
+		},
+		{
+			name:          "endpoint in json kubelet config",
+			kubeletConfig: `{"kind":"KubeletConfiguration","containerRuntimeEndpoint":"unix:///run/containerd/containerd.sock"}`,
+			wantEndpoint:  "unix:///run/containerd/containerd.sock",
+			wantRuntime:   "containerd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := getRuntimeEndpointFromKubeletCommand(tt.kubeletCommand)
+			if endpoint == "" {
+				endpoint = getRuntimeEndpointFromKubeletConfig(tt.kubeletConfig)
+			}
+			if endpoint != tt.wantEndpoint {
+				t.Errorf("unexpected endpoint: expected %s, found %s", tt.wantEndpoint, endpoint)
+			}
+			runtime := getRuntimeNameFromEndpoint(endpoint)
+			if runtime != tt.wantRuntime {
+				t.Errorf("unexpected runtime: expected %s, found %s", tt.wantRuntime, runtime)
+			}
+		})
+	}
+}
+
+func TestContainerRuntimeCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "get container runtime state",
+			want:    1,
+			wantErr: false,
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+	c := NewContainerRuntimeCollector(utils.Linux, runtimeInfo)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.Collect()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			raw := c.GetData()
+			if len(raw) < tt.want {
+				t.Errorf("len(GetData()) = %v, want %v", len(raw), tt.want)
+			}
+		})
+	}
+}
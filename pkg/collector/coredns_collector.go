@@ -0,0 +1,586 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	coreDNSNamespace     = "kube-system"
+	coreDNSLabelSelector = "k8s-app=kube-dns"
+	coreDNSMetricsPort   = 9153
+)
+
+// CorefileDirective is a plugin or plugin property in a Corefile, with any nested block of properties.
+type CorefileDirective struct {
+	Name  string              `json:"name"`
+	Args  []string            `json:"args"`
+	Block []CorefileDirective `json:"block,omitempty"`
+}
+
+// CorefileServerBlock is a server block in a Corefile, listing the plugins enabled for its zones and ports.
+type CorefileServerBlock struct {
+	Source     string              `json:"source"`
+	Keys       []string            `json:"keys"`
+	Plugins    []string            `json:"plugins"`
+	Forwarders []string            `json:"forwarders"`
+	Directives []CorefileDirective `json:"directives"`
+}
+
+// CorefileOverride is a set of plugins from the coredns-custom ConfigMap, imported into the default server block.
+type CorefileOverride struct {
+	Source     string              `json:"source"`
+	Directives []CorefileDirective `json:"directives"`
+}
+
+// CorefileSummary is the structured CoreDNS configuration from the coredns and coredns-custom ConfigMaps.
+type CorefileSummary struct {
+	ServerBlocks []CorefileServerBlock `json:"serverBlocks"`
+	Overrides    []CorefileOverride    `json:"overrides"`
+	Errors       []string              `json:"errors"`
+}
+
+// CoreDNSPodStatus is the status of a single CoreDNS pod.
+type CoreDNSPodStatus struct {
+	Name                  string `json:"name"`
+	NodeName              string `json:"nodeName"`
+	PodIP                 string `json:"podIP"`
+	Phase                 string `json:"phase"`
+	Ready                 bool   `json:"ready"`
+	Restarts              int32  `json:"restarts"`
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+}
+
+// CoreDNSUpstreamMetrics are the forwarding metrics for a single upstream nameserver.
+type CoreDNSUpstreamMetrics struct {
+	Upstream         string  `json:"upstream"`
+	Requests         float64 `json:"requests"`
+	AverageLatencyMs float64 `json:"averageLatencyMs"`
+}
+
+// CoreDNSMetricsSummary is a summary of the counters scraped from a single CoreDNS pod's metrics endpoint.
+// As these are counters, RequestsPerSecond is averaged over the lifetime of the CoreDNS process.
+type CoreDNSMetricsSummary struct {
+	PodName                 string                   `json:"podName"`
+	UptimeSeconds           float64                  `json:"uptimeSeconds"`
+	Requests                float64                  `json:"requests"`
+	RequestsPerSecond       float64                  `json:"requestsPerSecond"`
+	Responses               map[string]float64       `json:"responses"`
+	ServerFailures          float64                  `json:"serverFailures"`
+	NameErrors              float64                  `json:"nameErrors"`
+	CacheHits               float64                  `json:"cacheHits"`
+	CacheMisses             float64                  `json:"cacheMisses"`
+	CacheHitRatio           float64                  `json:"cacheHitRatio"`
+	ForwardRequests         float64                  `json:"forwardRequests"`
+	ForwardAverageLatencyMs float64                  `json:"forwardAverageLatencyMs"`
+	Upstreams               []CoreDNSUpstreamMetrics `json:"upstreams"`
+	Panics                  float64                  `json:"panics"`
+}
+
+// CoreDNSCollector defines a CoreDNS Collector struct
+type CoreDNSCollector struct {
+	data        map[string]string
+	kubeconfig  *rest.Config
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewCoreDNSCollector is a constructor
+func NewCoreDNSCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *CoreDNSCollector {
+	return &CoreDNSCollector{
+		data:        make(map[string]string),
+		kubeconfig:  config,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *CoreDNSCollector) GetName() string {
+	return "coredns"
+}
+
+func (collector *CoreDNSCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *CoreDNSCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	// Everything collected here is the same on every node, and scraping metrics port-forwards to each CoreDNS pod,
+	// so it is only collected once per collection.
+	if !isPrimaryNode(clientset, collector.runtimeInfo) {
+		return nil
+	}
+
+	if err := collector.collectConfig(clientset); err != nil {
+		return err
+	}
+
+	pods, err := clientset.CoreV1().Pods(coreDNSNamespace).List(context.Background(), metav1.ListOptions{LabelSelector: coreDNSLabelSelector})
+	if err != nil {
+		return fmt.Errorf("pod list failure: %w", err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	podStatuses := []CoreDNSPodStatus{}
+	metricsSummaries := []CoreDNSMetricsSummary{}
+	for _, pod := range pods.Items {
+		podStatuses = append(podStatuses, getCoreDNSPodStatus(&pod))
+
+		containerName := ""
+		for _, container := range pod.Spec.Containers {
+			if container.Name == "coredns" {
+				containerName = container.Name
+			}
+		}
+
+		logs, err := getPodContainerLogs(pod.Namespace, pod.Name, containerName, clientset)
+		if err != nil {
+			logs = fmt.Sprintf("Failed to collect logs for pod %s: %+v\n", pod.Name, err)
+			log.Print(logs)
+		}
+		collector.data[fmt.Sprintf("coredns/%s_logs", pod.Name)] = logs
+
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		metrics, err := collector.scrapeMetrics(pod.Name)
+		if err != nil {
+			metrics = fmt.Sprintf("Failed to scrape metrics for pod %s: %+v\n", pod.Name, err)
+			log.Print(metrics)
+		}
+		collector.data[fmt.Sprintf("coredns/%s_metrics", pod.Name)] = metrics
+		if err != nil {
+			continue
+		}
+
+		samples, err := utils.ParsePrometheusText(metrics)
+		if err != nil {
+			log.Printf("Failed to parse metrics for pod %s: %v", pod.Name, err)
+			continue
+		}
+		metricsSummaries = append(metricsSummaries, summarizeCoreDNSMetrics(pod.Name, samples, time.Now()))
+	}
+
+	podsBytes, err := json.Marshal(podStatuses)
+	if err != nil {
+		return fmt.Errorf("marshall coredns pods to json: %w", err)
+	}
+	collector.data["coredns_pods"] = string(podsBytes)
+
+	metricsBytes, err := json.Marshal(metricsSummaries)
+	if err != nil {
+		return fmt.Errorf("marshall coredns metrics summary to json: %w", err)
+	}
+	collector.data["coredns_metrics_summary"] = string(metricsBytes)
+
+	return nil
+}
+
+func (collector *CoreDNSCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// collectConfig stores the coredns and coredns-custom ConfigMaps, and the Corefile parsed from them.
+func (collector *CoreDNSCollector) collectConfig(clientset *kubernetes.Clientset) error {
+	summary := CorefileSummary{
+		ServerBlocks: []CorefileServerBlock{},
+		Overrides:    []CorefileOverride{},
+		Errors:       []string{},
+	}
+
+	for _, name := range []string{"coredns", "coredns-custom"} {
+		key := strings.ReplaceAll(name, "-", "_") + "_configmap"
+		configMap, err := clientset.CoreV1().ConfigMaps(coreDNSNamespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			// The coredns-custom ConfigMap is only present when a user has customized CoreDNS.
+			if k8sErrors.IsNotFound(err) && name == "coredns-custom" {
+				log.Printf("No %s configmap found", name)
+				continue
+			}
+			value := fmt.Sprintf("Failed to get configmap %s: %+v\n", name, err)
+			log.Print(value)
+			collector.data[key] = value
+			continue
+		}
+
+		configMapBytes, err := json.Marshal(configMap.Data)
+		if err != nil {
+			return fmt.Errorf("marshall configmap %s to json: %w", name, err)
+		}
+		collector.data[key] = string(configMapBytes)
+
+		dataKeys := make([]string, 0, len(configMap.Data))
+		for dataKey := range configMap.Data {
+			dataKeys = append(dataKeys, dataKey)
+		}
+		sort.Strings(dataKeys)
+
+		for _, dataKey := range dataKeys {
+			source := name + "/" + dataKey
+			content := configMap.Data[dataKey]
+			switch {
+			case dataKey == "Corefile" || strings.HasSuffix(dataKey, ".server"):
+				blocks, err := parseCorefile(source, content)
+				if err != nil {
+					summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", source, err))
+					continue
+				}
+				summary.ServerBlocks = append(summary.ServerBlocks, blocks...)
+			case strings.HasSuffix(dataKey, ".override"):
+				directives, err := parseCorefileDirectives(content)
+				if err != nil {
+					summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", source, err))
+					continue
+				}
+				summary.Overrides = append(summary.Overrides, CorefileOverride{Source: source, Directives: directives})
+			}
+		}
+	}
+
+	summaryBytes, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("marshall corefile summary to json: %w", err)
+	}
+	collector.data["coredns_corefile_summary"] = string(summaryBytes)
+
+	return nil
+}
+
+// scrapeMetrics port-forwards to the CoreDNS prometheus plugin's port on a pod, and returns the content of /metrics.
+func (collector *CoreDNSCollector) scrapeMetrics(podName string) (string, error) {
+	readyChan := make(chan struct{})
+	stopChan := make(chan struct{})
+	errorChan := make(chan error, 1)
+
+	defer close(stopChan)
+
+	// A free local port is chosen, so that pods can be scraped while other collectors are port-forwarding.
+	fw, err := newPortForwarder(collector.kubeconfig, &portForwardParams{
+		namespace: coreDNSNamespace,
+		podName:   podName,
+		localPort: 0,
+		podPort:   coreDNSMetricsPort,
+		outStream: io.Discard,
+		errStream: io.Discard,
+		readyChan: readyChan,
+		stopChan:  stopChan,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	go func() {
+		errorChan <- fw.ForwardPorts()
+	}()
+
+	select {
+	case err := <-errorChan:
+		return "", fmt.Errorf("port-forward to %s failed: %w", podName, err)
+	case <-readyChan:
+	}
+
+	ports, err := fw.GetPorts()
+	if err != nil {
+		return "", err
+	}
+
+	body, err := utils.GetUrlWithRetries(fmt.Sprintf("http://localhost:%d/metrics", ports[0].Local), 3)
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+func getCoreDNSPodStatus(pod *corev1.Pod) CoreDNSPodStatus {
+	status := CoreDNSPodStatus{
+		Name:     pod.Name,
+		NodeName: pod.Spec.NodeName,
+		PodIP:    pod.Status.PodIP,
+		Phase:    string(pod.Status.Phase),
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			status.Ready = condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		status.Restarts += containerStatus.RestartCount
+		if containerStatus.LastTerminationState.Terminated != nil {
+			status.LastTerminationReason = containerStatus.LastTerminationState.Terminated.Reason
+		}
+	}
+
+	return status
+}
+
+// summarizeCoreDNSMetrics extracts the request, response code, cache and forwarding counters from CoreDNS metrics,
+// accepting both the current metric names and those used before CoreDNS 1.7.0.
+func summarizeCoreDNSMetrics(podName string, samples []utils.PrometheusSample, now time.Time) CoreDNSMetricsSummary {
+	sum := func(names ...string) float64 {
+		total := 0.0
+		for _, name := range names {
+			total += utils.SumPrometheusSamples(samples, name, nil)
+		}
+		return total
+	}
+
+	summary := CoreDNSMetricsSummary{
+		PodName:         podName,
+		Requests:        sum("coredns_dns_requests_total", "coredns_dns_request_count_total"),
+		Responses:       map[string]float64{},
+		CacheHits:       sum("coredns_cache_hits_total"),
+		CacheMisses:     sum("coredns_cache_misses_total"),
+		ForwardRequests: sum("coredns_forward_requests_total", "coredns_forward_request_count_total"),
+		Upstreams:       []CoreDNSUpstreamMetrics{},
+		Panics:          sum("coredns_panics_total", "coredns_panic_count_total"),
+	}
+
+	upstreams := map[string]*CoreDNSUpstreamMetrics{}
+	latencySums := map[string]float64{}
+	latencyCounts := map[string]float64{}
+	for _, sample := range samples {
+		switch sample.Name {
+		case "coredns_dns_responses_total", "coredns_dns_response_rcode_count_total":
+			summary.Responses[sample.Labels["rcode"]] += sample.Value
+		case "coredns_forward_requests_total", "coredns_forward_request_count_total":
+			upstream := sample.Labels["to"]
+			if _, ok := upstreams[upstream]; !ok {
+				upstreams[upstream] = &CoreDNSUpstreamMetrics{Upstream: upstream}
+			}
+			upstreams[upstream].Requests += sample.Value
+		case "coredns_forward_request_duration_seconds_sum":
+			latencySums[sample.Labels["to"]] += sample.Value
+		case "coredns_forward_request_duration_seconds_count":
+			latencyCounts[sample.Labels["to"]] += sample.Value
+		case "process_start_time_seconds":
+			startTime := time.Unix(0, int64(sample.Value*float64(time.Second)))
+			summary.UptimeSeconds = now.Sub(startTime).Seconds()
+		}
+	}
+
+	summary.ServerFailures = summary.Responses["SERVFAIL"]
+	summary.NameErrors = summary.Responses["NXDOMAIN"]
+
+	if summary.UptimeSeconds > 0 {
+		summary.RequestsPerSecond = summary.Requests / summary.UptimeSeconds
+	}
+	if summary.CacheHits+summary.CacheMisses > 0 {
+		summary.CacheHitRatio = summary.CacheHits / (summary.CacheHits + summary.CacheMisses)
+	}
+
+	totalLatency, totalCount := 0.0, 0.0
+	for upstream, count := range latencyCounts {
+		totalLatency += latencySums[upstream]
+		totalCount += count
+		if _, ok := upstreams[upstream]; !ok {
+			upstreams[upstream] = &CoreDNSUpstreamMetrics{Upstream: upstream}
+		}
+		if count > 0 {
+			upstreams[upstream].AverageLatencyMs = latencySums[upstream] / count * 1000
+		}
+	}
+	if totalCount > 0 {
+		summary.ForwardAverageLatencyMs = totalLatency / totalCount * 1000
+	}
+
+	for _, upstream := range upstreams {
+		summary.Upstreams = append(summary.Upstreams, *upstream)
+	}
+	sort.Slice(summary.Upstreams, func(i, j int) bool { return summary.Upstreams[i].Upstream < summary.Upstreams[j].Upstream })
+
+	return summary
+}
+
+type corefileToken struct {
+	text   string
+	quoted bool
+}
+
+func (token corefileToken) is(text string) bool {
+	return !token.quoted && token.text == text
+}
+
+// tokenizeCorefile splits a Corefile into words, quoted strings, braces and line breaks, dropping comments.
+func tokenizeCorefile(content string) []corefileToken {
+	tokens := []corefileToken{}
+	var word strings.Builder
+	endWord := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, corefileToken{text: word.String()})
+			word.Reset()
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '#':
+			endWord()
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+		case c == '\n':
+			endWord()
+			tokens = append(tokens, corefileToken{text: "\n"})
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		case c == '{' && word.Len() == 0 && i+1 < len(content) && content[i+1] == '$':
+			// An environment variable placeholder, e.g. {$POD_NAMESPACE}
+			word.WriteByte(c)
+		case (c == '{' || c == '}') && word.Len() == 0:
+			tokens = append(tokens, corefileToken{text: string(c)})
+		case c == '"' && word.Len() == 0:
+			var quoted strings.Builder
+			for i++; i < len(content) && content[i] != '"'; i++ {
+				if content[i] == '\\' && i+1 < len(content) && content[i+1] == '"' {
+					i++
+				}
+				quoted.WriteByte(content[i])
+			}
+			tokens = append(tokens, corefileToken{text: quoted.String(), quoted: true})
+		default:
+			word.WriteByte(c)
+		}
+	}
+	endWord()
+
+	return tokens
+}
+
+type corefileParser struct {
+	tokens []corefileToken
+	pos    int
+}
+
+func (parser *corefileParser) done() bool {
+	return parser.pos >= len(parser.tokens)
+}
+
+func (parser *corefileParser) skipLineBreaks() {
+	for !parser.done() && parser.tokens[parser.pos].is("\n") {
+		parser.pos++
+	}
+}
+
+// parseCorefile parses the server blocks in a Corefile, each of which has one or more keys (zones and ports)
+// followed by a braced list of plugins.
+func parseCorefile(source, content string) ([]CorefileServerBlock, error) {
+	parser := &corefileParser{tokens: tokenizeCorefile(content)}
+	blocks := []CorefileServerBlock{}
+	for {
+		parser.skipLineBreaks()
+		if parser.done() {
+			return blocks, nil
+		}
+
+		block := CorefileServerBlock{Source: source, Keys: []string{}, Plugins: []string{}, Forwarders: []string{}}
+		for !parser.done() && !parser.tokens[parser.pos].is("{") {
+			token := parser.tokens[parser.pos]
+			parser.pos++
+			if token.is("}") {
+				return nil, fmt.Errorf("unexpected '}' before server block")
+			}
+			if token.is("\n") {
+				continue
+			}
+			for _, key := range strings.Split(token.text, ",") {
+				if len(key) > 0 {
+					block.Keys = append(block.Keys, key)
+				}
+			}
+		}
+		if parser.done() {
+			return nil, fmt.Errorf("missing '{' after server block keys %v", block.Keys)
+		}
+		parser.pos++
+
+		directives, err := parser.parseDirectives(true)
+		if err != nil {
+			return nil, fmt.Errorf("server block %v: %w", block.Keys, err)
+		}
+		block.Directives = directives
+
+		for _, directive := range directives {
+			block.Plugins = append(block.Plugins, directive.Name)
+			if (directive.Name == "forward" || directive.Name == "proxy") && len(directive.Args) > 1 {
+				block.Forwarders = append(block.Forwarders, directive.Args[1:]...)
+			}
+		}
+
+		blocks = append(blocks, block)
+	}
+}
+
+// parseCorefileDirectives parses a list of plugins outside of a server block, as imported from coredns-custom.
+func parseCorefileDirectives(content string) ([]CorefileDirective, error) {
+	parser := &corefileParser{tokens: tokenizeCorefile(content)}
+	return parser.parseDirectives(false)
+}
+
+// parseDirectives parses one directive per line, each with an optional braced block of nested directives,
+// until the closing brace of the enclosing block (or the end of the content, when not in a block).
+func (parser *corefileParser) parseDirectives(inBlock bool) ([]CorefileDirective, error) {
+	directives := []CorefileDirective{}
+	for {
+		parser.skipLineBreaks()
+		if parser.done() {
+			if inBlock {
+				return nil, fmt.Errorf("missing '}'")
+			}
+			return directives, nil
+		}
+
+		token := parser.tokens[parser.pos]
+		parser.pos++
+		if token.is("}") {
+			if !inBlock {
+				return nil, fmt.Errorf("unexpected '}'")
+			}
+			return directives, nil
+		}
+		if token.is("{") {
+			return nil, fmt.Errorf("unexpected '{'")
+		}
+
+		directive := CorefileDirective{Name: token.text, Args: []string{}}
+		for !parser.done() {
+			token := parser.tokens[parser.pos]
+			if token.is("\n") || token.is("}") {
+				break
+			}
+			parser.pos++
+
+			if token.is("{") {
+				block, err := parser.parseDirectives(true)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", directive.Name, err)
+				}
+				directive.Block = block
+				break
+			}
+			directive.Args = append(directive.Args, token.text)
+		}
+		directives = append(directives, directive)
+	}
+}
@@ -0,0 +1,230 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestCoreDNSCollectorGetName(t *testing.T) {
+	const expectedName = "coredns"
+
+	c := NewCoreDNSCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestCoreDNSCollectorCheckSupported(t *testing.T) {
+	c := NewCoreDNSCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestCoreDNSCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewCoreDNSCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	data := c.GetData()
+	expectedData := map[string]*regexp.Regexp{
+		"coredns_configmap":        regexp.MustCompile(`"Corefile":`),
+		"coredns_corefile_summary": regexp.MustCompile(`"forwarders":\["/etc/resolv.conf"\]`),
+		"coredns_pods":             regexp.MustCompile(`"phase":"Running"`),
+		"coredns_metrics_summary":  regexp.MustCompile(`"requests":`),
+	}
+
+	for key, regexp := range expectedData {
+		value, ok := data[key]
+		if !ok {
+			t.Errorf("missing key %s", key)
+			continue
+		}
+
+		testDataValue(t, value, func(v string) {
+			if !regexp.MatchString(v) {
+				t.Errorf("unexpected value for %s\n\tvalue: %s\n\tpattern: %s", key, v, regexp.String())
+			}
+		})
+	}
+}
+
+func TestParseCorefile(t *testing.T) {
+	const corefile = `.:53 {
+    errors
+    ready
+    health {
+        lameduck 5s
+    }
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+        pods insecure
+        fallthrough in-addr.arpa ip6.arpa
+        ttl 30
+    }
+    prometheus :9153
+    forward . /etc/resolv.conf # use the node's resolvers
+    cache 30
+    loop
+    reload
+    loadbalance
+    import custom/*.override
+}
+example.com:53, example.org:53 {
+    log . "{remote} {type} {name}"
+    forward . 10.0.0.4 10.0.0.5
+    template IN A {$ZONE} {
+        answer "{{ .Name }} 60 IN A 127.0.0.1"
+    }
+}
+`
+
+	blocks, err := parseCorefile("coredns/Corefile", corefile)
+	if err != nil {
+		t.Fatalf("parseCorefile() error = %v", err)
+	}
+
+	if len(blocks) != 2 {
+		t.Fatalf("unexpected number of server blocks: expected 2, found %d", len(blocks))
+	}
+
+	expectedPlugins := []string{"errors", "ready", "health", "kubernetes", "prometheus", "forward", "cache", "loop", "reload", "loadbalance", "import"}
+	if !reflect.DeepEqual(blocks[0].Plugins, expectedPlugins) {
+		t.Errorf("unexpected plugins:\nexpected %v\nfound    %v", expectedPlugins, blocks[0].Plugins)
+	}
+
+	if !reflect.DeepEqual(blocks[0].Keys, []string{".:53"}) {
+		t.Errorf("unexpected keys: %v", blocks[0].Keys)
+	}
+
+	kubernetes := blocks[0].Directives[3]
+	expectedKubernetes := CorefileDirective{
+		Name: "kubernetes",
+		Args: []string{"cluster.local", "in-addr.arpa", "ip6.arpa"},
+		Block: []CorefileDirective{
+			{Name: "pods", Args: []string{"insecure"}},
+			{Name: "fallthrough", Args: []string{"in-addr.arpa", "ip6.arpa"}},
+			{Name: "ttl", Args: []string{"30"}},
+		},
+	}
+	if !reflect.DeepEqual(kubernetes, expectedKubernetes) {
+		t.Errorf("unexpected kubernetes directive:\nexpected %+v\nfound    %+v", expectedKubernetes, kubernetes)
+	}
+
+	if !reflect.DeepEqual(blocks[0].Forwarders, []string{"/etc/resolv.conf"}) {
+		t.Errorf("unexpected forwarders: %v", blocks[0].Forwarders)
+	}
+
+	if !reflect.DeepEqual(blocks[1].Keys, []string{"example.com:53", "example.org:53"}) {
+		t.Errorf("unexpected keys: %v", blocks[1].Keys)
+	}
+
+	if !reflect.DeepEqual(blocks[1].Directives[0].Args, []string{".", "{remote} {type} {name}"}) {
+		t.Errorf("unexpected log args: %v", blocks[1].Directives[0].Args)
+	}
+
+	if !reflect.DeepEqual(blocks[1].Forwarders, []string{"10.0.0.4", "10.0.0.5"}) {
+		t.Errorf("unexpected forwarders: %v", blocks[1].Forwarders)
+	}
+
+	template := blocks[1].Directives[2]
+	if !reflect.DeepEqual(template.Args, []string{"IN", "A", "{$ZONE}"}) || len(template.Block) != 1 {
+		t.Errorf("unexpected template directive: %+v", template)
+	}
+}
+
+func TestParseCorefileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unclosed server block", content: ".:53 {\n errors\n"},
+		{name: "unclosed plugin block", content: ".:53 {\n health {\n lameduck 5s\n}\n"},
+		{name: "missing server block", content: ".:53\n"},
+		{name: "unexpected closing brace", content: "}\n"},
+	}
+
+	for _, tt := range tests {
+		if _, err := parseCorefile("test", tt.content); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestParseCorefileDirectives(t *testing.T) {
+	directives, err := parseCorefileDirectives("log\nrewrite name substring demo.com default.svc.cluster.local\n")
+	if err != nil {
+		t.Fatalf("parseCorefileDirectives() error = %v", err)
+	}
+
+	expected := []CorefileDirective{
+		{Name: "log", Args: []string{}},
+		{Name: "rewrite", Args: []string{"name", "substring", "demo.com", "default.svc.cluster.local"}},
+	}
+	if !reflect.DeepEqual(directives, expected) {
+		t.Errorf("unexpected directives:\nexpected %+v\nfound    %+v", expected, directives)
+	}
+}
+
+func TestSummarizeCoreDNSMetrics(t *testing.T) {
+	const metrics = `# TYPE coredns_dns_requests_total counter
+coredns_dns_requests_total{family="1",proto="udp",server="dns://:53",type="A",zone="."} 900
+coredns_dns_requests_total{family="1",proto="udp",server="dns://:53",type="AAAA",zone="."} 100
+coredns_dns_responses_total{rcode="NOERROR",server="dns://:53",zone="."} 950
+coredns_dns_responses_total{rcode="NXDOMAIN",server="dns://:53",zone="."} 40
+coredns_dns_responses_total{rcode="SERVFAIL",server="dns://:53",zone="."} 10
+coredns_cache_hits_total{server="dns://:53",type="success"} 600
+coredns_cache_hits_total{server="dns://:53",type="denial"} 150
+coredns_cache_misses_total{server="dns://:53"} 250
+coredns_forward_requests_total{to="168.63.129.16:53"} 250
+coredns_forward_request_duration_seconds_bucket{le="0.01",rcode="NOERROR",to="168.63.129.16:53"} 200
+coredns_forward_request_duration_seconds_sum{rcode="NOERROR",to="168.63.129.16:53"} 2.5
+coredns_forward_request_duration_seconds_count{rcode="NOERROR",to="168.63.129.16:53"} 250
+coredns_panics_total 0
+process_start_time_seconds 1.6e+09
+`
+
+	samples, err := utils.ParsePrometheusText(metrics)
+	if err != nil {
+		t.Fatalf("ParsePrometheusText() error = %v", err)
+	}
+
+	summary := summarizeCoreDNSMetrics("coredns-1", samples, time.Unix(1600000100, 0))
+	expected := CoreDNSMetricsSummary{
+		PodName:                 "coredns-1",
+		UptimeSeconds:           100,
+		Requests:                1000,
+		RequestsPerSecond:       10,
+		Responses:               map[string]float64{"NOERROR": 950, "NXDOMAIN": 40, "SERVFAIL": 10},
+		ServerFailures:          10,
+		NameErrors:              40,
+		CacheHits:               750,
+		CacheMisses:             250,
+		CacheHitRatio:           0.75,
+		ForwardRequests:         250,
+		ForwardAverageLatencyMs: 10,
+		Upstreams:               []CoreDNSUpstreamMetrics{{Upstream: "168.63.129.16:53", Requests: 250, AverageLatencyMs: 10}},
+		Panics:                  0,
+	}
+
+	if !reflect.DeepEqual(summary, expected) {
+		expectedJson, _ := json.Marshal(expected)
+		summaryJson, _ := json.Marshal(summary)
+		t.Errorf("unexpected summary:\nexpected %s\nfound    %s", expectedJson, summaryJson)
+	}
+}
@@ -0,0 +1,277 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"helm.sh/helm/v3/pkg/action"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	restclient "k8s.io/client-go/rest"
+)
+
+// DeprecatedAPIVersions are the version of the API server and the version it is being checked against.
+type DeprecatedAPIVersions struct {
+	ServerVersion string `json:"serverVersion"`
+	TargetVersion string `json:"targetVersion"`
+}
+
+// DeprecatedAPIRequest is a deprecated API that has been requested since the API server started, as reported
+// by its apiserver_requested_deprecated_apis metric.
+type DeprecatedAPIRequest struct {
+	Group          string  `json:"group"`
+	Version        string  `json:"version"`
+	Resource       string  `json:"resource"`
+	Subresource    string  `json:"subresource,omitempty"`
+	RemovedRelease string  `json:"removedRelease"`
+	Requests       float64 `json:"requests"`
+}
+
+// DeprecatedAPIObject is an object in a Helm release manifest with a deprecated apiVersion.
+type DeprecatedAPIObject struct {
+	Release          string `json:"release"`
+	ReleaseNamespace string `json:"releaseNamespace"`
+	Revision         int    `json:"revision"`
+	APIVersion       string `json:"apiVersion"`
+	Kind             string `json:"kind"`
+	Namespace        string `json:"namespace,omitempty"`
+	Name             string `json:"name"`
+}
+
+// DeprecatedAPIsCollector defines a DeprecatedAPIs Collector struct
+type DeprecatedAPIsCollector struct {
+	data          map[string]string
+	kubeconfig    *restclient.Config
+	runtimeInfo   *utils.RuntimeInfo
+	Versions      *DeprecatedAPIVersions
+	ServedAPIs    []utils.RemovedAPI
+	RequestedAPIs []DeprecatedAPIRequest
+	HelmObjects   []DeprecatedAPIObject
+}
+
+// NewDeprecatedAPIsCollector is a constructor
+func NewDeprecatedAPIsCollector(config *restclient.Config, runtimeInfo *utils.RuntimeInfo) *DeprecatedAPIsCollector {
+	return &DeprecatedAPIsCollector{
+		data:        make(map[string]string),
+		kubeconfig:  config,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *DeprecatedAPIsCollector) GetName() string {
+	return "deprecatedapis"
+}
+
+func (collector *DeprecatedAPIsCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *DeprecatedAPIsCollector) Collect() error {
+	// The discovery client is used for querying the served API versions
+	discoveryClient, _, err := getDiscoveryClientAndMapper(collector.kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return fmt.Errorf("error getting server version: %w", err)
+	}
+
+	targetVersion := collector.runtimeInfo.TargetKubernetesVersion
+	if len(targetVersion) == 0 {
+		targetVersion, err = utils.GetNextMinorVersion(serverVersion.GitVersion)
+		if err != nil {
+			return err
+		}
+	}
+	collector.Versions = &DeprecatedAPIVersions{
+		ServerVersion: serverVersion.GitVersion,
+		TargetVersion: targetVersion,
+	}
+
+	versionsBytes, err := json.Marshal(collector.Versions)
+	if err != nil {
+		return fmt.Errorf("marshall deprecated API versions to json: %w", err)
+	}
+	collector.data["deprecatedapis_versions"] = string(versionsBytes)
+
+	// Aggregated APIs that are unavailable cause partial failures, but the other groups are still returned.
+	_, resourceLists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return fmt.Errorf("error discovering server resources: %w", err)
+		}
+		log.Printf("Failed to discover some server resources: %v", err)
+	}
+	collector.ServedAPIs = getServedRemovedAPIs(resourceLists)
+
+	servedBytes, err := json.Marshal(collector.ServedAPIs)
+	if err != nil {
+		return fmt.Errorf("marshall served deprecated APIs to json: %w", err)
+	}
+	collector.data["deprecatedapis_served"] = string(servedBytes)
+
+	metrics, err := discoveryClient.RESTClient().Get().AbsPath("/metrics").DoRaw(context.Background())
+	if err != nil {
+		value := fmt.Sprintf("Failed to collect API server metrics: %+v\n", err)
+		log.Print(value)
+		collector.data["deprecatedapis_requested"] = value
+	} else {
+		samples, err := utils.ParsePrometheusText(string(metrics))
+		if err != nil {
+			return fmt.Errorf("error parsing API server metrics: %w", err)
+		}
+		collector.RequestedAPIs = getDeprecatedAPIRequests(samples)
+
+		requestedBytes, err := json.Marshal(collector.RequestedAPIs)
+		if err != nil {
+			return fmt.Errorf("marshall requested deprecated APIs to json: %w", err)
+		}
+		collector.data["deprecatedapis_requested"] = string(requestedBytes)
+	}
+
+	helmObjects, err := collector.getHelmObjects()
+	if err != nil {
+		value := fmt.Sprintf("Failed to collect Helm release manifests: %+v\n", err)
+		log.Print(value)
+		collector.data["deprecatedapis_helm"] = value
+	} else {
+		collector.HelmObjects = helmObjects
+
+		helmBytes, err := json.Marshal(collector.HelmObjects)
+		if err != nil {
+			return fmt.Errorf("marshall deprecated Helm objects to json: %w", err)
+		}
+		collector.data["deprecatedapis_helm"] = string(helmBytes)
+	}
+
+	return nil
+}
+
+func (collector *DeprecatedAPIsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// getHelmObjects scans the manifest of the current revision of each Helm release for deprecated apiVersions.
+func (collector *DeprecatedAPIsCollector) getHelmObjects() ([]DeprecatedAPIObject, error) {
+	actionConfig, err := NewHelmCollector(collector.kubeconfig, collector.runtimeInfo).getActionConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := action.NewList(actionConfig).Run()
+	if err != nil {
+		return nil, fmt.Errorf("list helm releases: %w", err)
+	}
+
+	objects := []DeprecatedAPIObject{}
+	for _, release := range releases {
+		releaseObjects, err := getDeprecatedManifestObjects(release.Name, release.Namespace, release.Version, release.Manifest)
+		if err != nil {
+			log.Printf("Failed to parse manifest of release %s in namespace %s: %v", release.Name, release.Namespace, err)
+			continue
+		}
+		objects = append(objects, releaseObjects...)
+	}
+
+	return objects, nil
+}
+
+// getServedRemovedAPIs returns the kinds the API server still serves at a version that is removed in a later release.
+func getServedRemovedAPIs(resourceLists []*metav1.APIResourceList) []utils.RemovedAPI {
+	served := []utils.RemovedAPI{}
+	for _, resourceList := range resourceLists {
+		if resourceList == nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			// Subresources such as 'cronjobs/status' share the Kind of their parent resource.
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			if api, ok := utils.GetRemovedAPIForKind(resourceList.GroupVersion, resource.Kind); ok {
+				served = append(served, api)
+			}
+		}
+	}
+	return served
+}
+
+// getDeprecatedAPIRequests finds the deprecated APIs reported by the apiserver_requested_deprecated_apis metric,
+// along with the number of requests made to each of them.
+func getDeprecatedAPIRequests(samples []utils.PrometheusSample) []DeprecatedAPIRequest {
+	requests := []DeprecatedAPIRequest{}
+	for _, sample := range samples {
+		if sample.Name != "apiserver_requested_deprecated_apis" {
+			continue
+		}
+
+		request := DeprecatedAPIRequest{
+			Group:          sample.Labels["group"],
+			Version:        sample.Labels["version"],
+			Resource:       sample.Labels["resource"],
+			Subresource:    sample.Labels["subresource"],
+			RemovedRelease: sample.Labels["removed_release"],
+		}
+		request.Requests = utils.SumPrometheusSamples(samples, "apiserver_request_total", map[string]string{
+			"group":       request.Group,
+			"version":     request.Version,
+			"resource":    request.Resource,
+			"subresource": request.Subresource,
+		})
+		requests = append(requests, request)
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		a, b := requests[i], requests[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Subresource < b.Subresource
+	})
+
+	return requests
+}
+
+// getDeprecatedManifestObjects returns the objects in a multi-document YAML manifest with a deprecated apiVersion.
+func getDeprecatedManifestObjects(release, releaseNamespace string, revision int, manifest string) ([]DeprecatedAPIObject, error) {
+	manifestObjects, err := parseHelmManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []DeprecatedAPIObject{}
+	for _, manifestObject := range manifestObjects {
+		object := &unstructured.Unstructured{Object: manifestObject}
+		if _, ok := utils.GetRemovedAPIForKind(object.GetAPIVersion(), object.GetKind()); !ok {
+			continue
+		}
+
+		objects = append(objects, DeprecatedAPIObject{
+			Release:          release,
+			ReleaseNamespace: releaseNamespace,
+			Revision:         revision,
+			APIVersion:       object.GetAPIVersion(),
+			Kind:             object.GetKind(),
+			Namespace:        object.GetNamespace(),
+			Name:             object.GetName(),
+		})
+	}
+
+	return objects, nil
+}
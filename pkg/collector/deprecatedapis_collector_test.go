@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeprecatedAPIsCollectorGetName(t *testing.T) {
+	const expectedName = "deprecatedapis"
+
+	c := NewDeprecatedAPIsCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestDeprecatedAPIsCollectorCheckSupported(t *testing.T) {
+	c := NewDeprecatedAPIsCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestDeprecatedAPIsCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList:           []string{},
+		TargetKubernetesVersion: "1.25",
+	}
+
+	c := NewDeprecatedAPIsCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	// The test cluster runs Kubernetes 1.23, which still serves batch/v1beta1 CronJobs.
+	expectedData := map[string]*regexp.Regexp{
+		"deprecatedapis_versions":  regexp.MustCompile(`"serverVersion":"v1\.\d+\.\d+","targetVersion":"1\.25"`),
+		"deprecatedapis_served":    regexp.MustCompile(`"groupVersion":"batch/v1beta1","kind":"CronJob"`),
+		"deprecatedapis_requested": regexp.MustCompile(`^\[.*\]$`),
+		"deprecatedapis_helm":      regexp.MustCompile(`^\[.*\]$`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestGetServedRemovedAPIs(t *testing.T) {
+	resourceLists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "batch/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "cronjobs", Kind: "CronJob"},
+				{Name: "cronjobs/status", Kind: "CronJob"},
+			},
+		},
+		{
+			GroupVersion: "batch/v1",
+			APIResources: []metav1.APIResource{{Name: "jobs", Kind: "Job"}},
+		},
+		nil,
+	}
+
+	expected := []utils.RemovedAPI{
+		{GroupVersion: "batch/v1beta1", Kind: "CronJob", Resource: "cronjobs", RemovedIn: "1.25", Replacement: "batch/v1"},
+	}
+
+	result := getServedRemovedAPIs(resourceLists)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected served APIs:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestGetDeprecatedAPIRequests(t *testing.T) {
+	content := `# HELP apiserver_requested_deprecated_apis [STABLE] Gauge of deprecated APIs that have been requested, broken out by API group, version, resource, subresource, and removed_release.
+# TYPE apiserver_requested_deprecated_apis gauge
+apiserver_requested_deprecated_apis{group="policy",removed_release="1.25",resource="podsecuritypolicies",subresource="",version="v1beta1"} 1
+apiserver_requested_deprecated_apis{group="batch",removed_release="1.25",resource="cronjobs",subresource="",version="v1beta1"} 1
+apiserver_request_total{code="200",component="apiserver",dry_run="",group="batch",resource="cronjobs",scope="cluster",subresource="",verb="LIST",version="v1beta1"} 4
+apiserver_request_total{code="200",component="apiserver",dry_run="",group="batch",resource="cronjobs",scope="cluster",subresource="",verb="WATCH",version="v1beta1"} 2
+apiserver_request_total{code="200",component="apiserver",dry_run="",group="batch",resource="cronjobs",scope="cluster",subresource="",verb="LIST",version="v1"} 100
+`
+	samples, err := utils.ParsePrometheusText(content)
+	if err != nil {
+		t.Fatalf("ParsePrometheusText() error = %v", err)
+	}
+
+	expected := []DeprecatedAPIRequest{
+		{Group: "batch", Version: "v1beta1", Resource: "cronjobs", RemovedRelease: "1.25", Requests: 6},
+		{Group: "policy", Version: "v1beta1", Resource: "podsecuritypolicies", RemovedRelease: "1.25", Requests: 0},
+	}
+
+	result := getDeprecatedAPIRequests(samples)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected requests:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestGetDeprecatedManifestObjects(t *testing.T) {
+	manifest := `---
+# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+# Source: app/templates/ingress.yaml
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: web
+  namespace: app
+---
+# Source: app/templates/cronjob.yaml
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cleanup
+`
+
+	expected := []DeprecatedAPIObject{
+		{Release: "app", ReleaseNamespace: "app", Revision: 3, APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", Namespace: "app", Name: "web"},
+		{Release: "app", ReleaseNamespace: "app", Revision: 3, APIVersion: "batch/v1beta1", Kind: "CronJob", Name: "cleanup"},
+	}
+
+	result, err := getDeprecatedManifestObjects("app", "app", 3, manifest)
+	if err != nil {
+		t.Fatalf("getDeprecatedManifestObjects() error = %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected objects:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
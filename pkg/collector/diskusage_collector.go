@@ -0,0 +1,263 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// The kubelet's default hard eviction thresholds, see:
+// https://kubernetes.io/docs/concepts/scheduling-eviction/node-pressure-eviction/#hard-eviction-thresholds
+const (
+	nodeFsAvailableThresholdPercent  = 10
+	imageFsAvailableThresholdPercent = 15
+	inodesFreeThresholdPercent       = 5
+	highUsageThresholdPercent        = 85
+	largestPodLogDirectoryCount      = 20
+)
+
+// FilesystemUsage describes the space and inode usage of the filesystem containing a node path.
+type FilesystemUsage struct {
+	Path              string   `json:"path"`
+	Role              string   `json:"role"`
+	Filesystem        string   `json:"filesystem"`
+	MountPoint        string   `json:"mountPoint"`
+	SizeKB            int64    `json:"sizeKB"`
+	UsedKB            int64    `json:"usedKB"`
+	AvailableKB       int64    `json:"availableKB"`
+	UsedPercent       float64  `json:"usedPercent"`
+	AvailablePercent  float64  `json:"availablePercent"`
+	Inodes            int64    `json:"inodes"`
+	InodesUsed        int64    `json:"inodesUsed"`
+	InodesFree        int64    `json:"inodesFree"`
+	InodesFreePercent float64  `json:"inodesFreePercent"`
+	Flags             []string `json:"flags"`
+}
+
+// DirectoryUsage describes the disk space used by a directory.
+type DirectoryUsage struct {
+	Path   string `json:"path"`
+	SizeKB int64  `json:"sizeKB"`
+}
+
+type dfRow struct {
+	filesystem string
+	total      int64
+	used       int64
+	available  int64
+	mountPoint string
+}
+
+// DiskUsageCollector defines a DiskUsage Collector struct
+type DiskUsageCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewDiskUsageCollector is a constructor
+func NewDiskUsageCollector(osIdentifier utils.OSIdentifier, runtimeInfo *utils.RuntimeInfo) *DiskUsageCollector {
+	return &DiskUsageCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *DiskUsageCollector) GetName() string {
+	return "diskusage"
+}
+
+func (collector *DiskUsageCollector) CheckSupported() error {
+	// This runs `df` and `du` on the host, which is not possible from a Windows container.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *DiskUsageCollector) Collect() error {
+	dfHuman, err := utils.RunCommandOnHost("df", "-h")
+	if err != nil {
+		return fmt.Errorf("error running df: %w", err)
+	}
+	collector.data["df"] = dfHuman
+
+	dfInodes, err := utils.RunCommandOnHost("df", "-i")
+	if err != nil {
+		return fmt.Errorf("error running df for inodes: %w", err)
+	}
+	collector.data["df_inodes"] = dfInodes
+
+	// The role determines which kubelet eviction signal applies to the filesystem.
+	nodePaths := []struct {
+		path string
+		role string
+	}{
+		{path: "/", role: "nodefs"},
+		{path: "/var/lib/kubelet", role: "nodefs"},
+		{path: "/var/log", role: "nodefs"},
+		{path: "/var/lib/containerd", role: "imagefs"},
+	}
+
+	usages := []FilesystemUsage{}
+	for _, nodePath := range nodePaths {
+		usage, err := getFilesystemUsage(nodePath.path, nodePath.role)
+		if err != nil {
+			log.Printf("Failed to get filesystem usage for %s: %v", nodePath.path, err)
+			continue
+		}
+		usages = append(usages, *usage)
+	}
+
+	usageBytes, err := json.Marshal(usages)
+	if err != nil {
+		return fmt.Errorf("marshall filesystem usage to json: %w", err)
+	}
+	collector.data["filesystem_usage"] = string(usageBytes)
+
+	podLogUsage, err := utils.RunCommandOnHost("sh", "-c", fmt.Sprintf("du -sk /var/log/pods/* 2>/dev/null | sort -rn | head -n %d", largestPodLogDirectoryCount))
+	if err != nil {
+		log.Printf("Failed to get pod log directory usage: %v", err)
+	} else {
+		podLogUsageBytes, err := json.Marshal(parseDuOutput(podLogUsage))
+		if err != nil {
+			return fmt.Errorf("marshall pod log directory usage to json: %w", err)
+		}
+		collector.data["pod_logs_usage"] = string(podLogUsageBytes)
+	}
+
+	return nil
+}
+
+func (collector *DiskUsageCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+func getFilesystemUsage(path, role string) (*FilesystemUsage, error) {
+	blocksOutput, err := utils.RunCommandOnHost("df", "-P", "-k", path)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := parseDfOutput(blocksOutput)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing df output: %w", err)
+	}
+
+	inodesOutput, err := utils.RunCommandOnHost("df", "-P", "-i", path)
+	if err != nil {
+		return nil, err
+	}
+	inodes, err := parseDfOutput(inodesOutput)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing df inode output: %w", err)
+	}
+
+	return newFilesystemUsage(path, role, blocks, inodes), nil
+}
+
+func newFilesystemUsage(path, role string, blocks, inodes *dfRow) *FilesystemUsage {
+	usage := &FilesystemUsage{
+		Path:        path,
+		Role:        role,
+		Filesystem:  blocks.filesystem,
+		MountPoint:  blocks.mountPoint,
+		SizeKB:      blocks.total,
+		UsedKB:      blocks.used,
+		AvailableKB: blocks.available,
+		Inodes:      inodes.total,
+		InodesUsed:  inodes.used,
+		InodesFree:  inodes.available,
+		Flags:       []string{},
+	}
+
+	if blocks.total > 0 {
+		usage.UsedPercent = percentage(blocks.used, blocks.total)
+		usage.AvailablePercent = percentage(blocks.available, blocks.total)
+	}
+
+	// Some filesystems (e.g. btrfs) report zero inodes, so these can't be under inode pressure.
+	if inodes.total > 0 {
+		usage.InodesFreePercent = percentage(inodes.available, inodes.total)
+		if usage.InodesFreePercent < inodesFreeThresholdPercent {
+			usage.Flags = append(usage.Flags, "InodesFreeBelowEvictionThreshold")
+		}
+	}
+
+	availableThreshold := float64(nodeFsAvailableThresholdPercent)
+	if role == "imagefs" {
+		availableThreshold = imageFsAvailableThresholdPercent
+	}
+	if blocks.total > 0 && usage.AvailablePercent < availableThreshold {
+		usage.Flags = append(usage.Flags, "AvailableBelowEvictionThreshold")
+	}
+	if usage.UsedPercent >= highUsageThresholdPercent {
+		usage.Flags = append(usage.Flags, "HighUsage")
+	}
+
+	return usage
+}
+
+// parseDfOutput parses the output of `df -P` for a single path. The columns are the same for both
+// block (-k) and inode (-i) output: filesystem, total, used, available, capacity and mount point.
+func parseDfOutput(output string) (*dfRow, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("unexpected df output: %s", output)
+	}
+
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 6 {
+		return nil, fmt.Errorf("unexpected df output line: %s", lines[len(lines)-1])
+	}
+
+	values := make([]int64, 3)
+	for i := range values {
+		value, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			// Some filesystems report '-' for inode counts.
+			value = 0
+		}
+		values[i] = value
+	}
+
+	return &dfRow{
+		filesystem: fields[0],
+		total:      values[0],
+		used:       values[1],
+		available:  values[2],
+		mountPoint: strings.Join(fields[5:], " "),
+	}, nil
+}
+
+// parseDuOutput parses the tab-separated size (in KB) and path lines output by `du -sk`.
+func parseDuOutput(output string) []DirectoryUsage {
+	result := []DirectoryUsage{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+		if err != nil {
+			continue
+		}
+		result = append(result, DirectoryUsage{Path: parts[1], SizeKB: size})
+	}
+	return result
+}
+
+func percentage(value, total int64) float64 {
+	return float64(int64(float64(value)/float64(total)*10000)) / 100
+}
@@ -0,0 +1,154 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestDiskUsageCollectorGetName(t *testing.T) {
+	const expectedName = "diskusage"
+
+	c := NewDiskUsageCollector("", nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestDiskUsageCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewDiskUsageCollector(tt.osIdentifier, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFilesystemUsageFlags(t *testing.T) {
+	tests := []struct {
+		name         string
+		role         string
+		blocksOutput string
+		inodesOutput string
+		wantFlags    []string
+	}{
+		{
+			name:         "healthy nodefs",
+			role:         "nodefs",
+			blocksOutput: "Filesystem     1024-blocks     Used Available Capacity Mounted on\n/dev/sda1        129900528 25623964 104260180      20% /\n",
+			inodesOutput: "Filesystem      Inodes  IUsed    IFree IUse% Mounted on\n/dev/sda1      16515072 460000 16055072    3% /\n",
+			wantFlags:    []string{},
+		},
+		{
+			name:         "full nodefs",
+			role:         "nodefs",
+			blocksOutput: "Filesystem     1024-blocks      Used Available Capacity Mounted on\n/dev/sda1        100000000  95000000   5000000      95% /\n",
+			inodesOutput: "Filesystem      Inodes    IUsed  IFree IUse% Mounted on\n/dev/sda1      1000000   980000  20000   98% /\n",
+			wantFlags:    []string{"InodesFreeBelowEvictionThreshold", "AvailableBelowEvictionThreshold", "HighUsage"},
+		},
+		{
+			name:         "imagefs below its higher threshold",
+			role:         "imagefs",
+			blocksOutput: "Filesystem     1024-blocks     Used Available Capacity Mounted on\n/dev/sdb1        100000000 88000000  12000000      88% /var/lib/containerd\n",
+			inodesOutput: "Filesystem      Inodes  IUsed    IFree IUse% Mounted on\n/dev/sdb1      1000000 100000   900000   10% /var/lib/containerd\n",
+			wantFlags:    []string{"AvailableBelowEvictionThreshold", "HighUsage"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := parseDfOutput(tt.blocksOutput)
+			if err != nil {
+				t.Fatalf("error parsing block output: %v", err)
+			}
+			inodes, err := parseDfOutput(tt.inodesOutput)
+			if err != nil {
+				t.Fatalf("error parsing inode output: %v", err)
+			}
+
+			usage := newFilesystemUsage("/", tt.role, blocks, inodes)
+			if !reflect.DeepEqual(usage.Flags, tt.wantFlags) {
+				t.Errorf("unexpected flags: expected %v, found %v", tt.wantFlags, usage.Flags)
+			}
+		})
+	}
+}
+
+func TestParseDfOutputInvalid(t *testing.T) {
+	if _, err := parseDfOutput("Filesystem 1024-blocks Used Available Capacity Mounted on"); err == nil {
+		t.Errorf("expected error for output without data rows")
+	}
+}
+
+func TestParseDuOutput(t *testing.T) {
+	output := "204800\t/var/log/pods/kube-system_coredns-abc_1234\n1024\t/var/log/pods/default_app-xyz_5678\n\n"
+	expected := []DirectoryUsage{
+		{Path: "/var/log/pods/kube-system_coredns-abc_1234", SizeKB: 204800},
+		{Path: "/var/log/pods/default_app-xyz_5678", SizeKB: 1024},
+	}
+
+	result := parseDuOutput(output)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected result: expected %v, found %v", expected, result)
+	}
+}
+
+func TestDiskUsageCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "get disk usage",
+			want:    1,
+			wantErr: true,
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+	c := NewDiskUsageCollector(utils.Linux, runtimeInfo)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.Collect()
+			if (err != nil) == tt.wantErr {
+				t.Logf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"golang.org/x/net/dns/dnsmessage"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+)
+
+const dnsProbeTimeout = 3 * time.Second
+
+// defaultDNSProbeNames are resolved when no names are configured: a cluster-internal name, and an
+// external name required by AKS nodes.
+var defaultDNSProbeNames = []string{"kubernetes.default.svc.cluster.local", "mcr.microsoft.com"}
+
+// DNSNameserver is a DNS server queried by the DNSProbeCollector, along with where it was found.
+type DNSNameserver struct {
+	Address string `json:"address"`
+	Source  string `json:"source"`
+}
+
+// DNSProbeResult is the result of querying a single nameserver for a single name.
+type DNSProbeResult struct {
+	Name       string        `json:"name"`
+	Nameserver DNSNameserver `json:"nameserver"`
+	Rcode      string        `json:"rcode"`
+	LatencyMs  float64       `json:"latencyMs"`
+	Answers    []string      `json:"answers"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// DNSProbeCollector defines a DNSProbe Collector struct
+type DNSProbeCollector struct {
+	data                map[string]string
+	kubeconfig          *restclient.Config
+	osIdentifier        utils.OSIdentifier
+	runtimeInfo         *utils.RuntimeInfo
+	filePaths           *utils.KnownFilePaths
+	fileSystem          interfaces.FileSystemAccessor
+	HostResolvConf      *utils.ResolvConf
+	ContainerResolvConf *utils.ResolvConf
+	Results             []DNSProbeResult
+}
+
+// NewDNSProbeCollector is a constructor
+func NewDNSProbeCollector(config *restclient.Config, osIdentifier utils.OSIdentifier, runtimeInfo *utils.RuntimeInfo, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor) *DNSProbeCollector {
+	return &DNSProbeCollector{
+		data:         make(map[string]string),
+		kubeconfig:   config,
+		osIdentifier: osIdentifier,
+		runtimeInfo:  runtimeInfo,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+	}
+}
+
+func (collector *DNSProbeCollector) GetName() string {
+	return "dnsprobe"
+}
+
+func (collector *DNSProbeCollector) CheckSupported() error {
+	// The nameservers to query are read from `resolv.conf`, which is unavailable in Windows.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *DNSProbeCollector) Collect() error {
+	hostConf, err := utils.GetContent(func() (io.ReadCloser, error) {
+		return collector.fileSystem.GetFileReader(collector.filePaths.ResolvConfHost)
+	})
+	if err != nil {
+		return fmt.Errorf("error reading host resolv.conf: %w", err)
+	}
+	collector.HostResolvConf = utils.ParseResolvConf(hostConf)
+
+	containerConf, err := utils.GetContent(func() (io.ReadCloser, error) {
+		return collector.fileSystem.GetFileReader(collector.filePaths.ResolvConfContainer)
+	})
+	if err != nil {
+		return fmt.Errorf("error reading container resolv.conf: %w", err)
+	}
+	collector.ContainerResolvConf = utils.ParseResolvConf(containerConf)
+
+	nameservers := []DNSNameserver{}
+	for _, address := range collector.HostResolvConf.Nameservers {
+		nameservers = append(nameservers, DNSNameserver{Address: address, Source: "virtualmachine"})
+	}
+	for _, address := range collector.ContainerResolvConf.Nameservers {
+		nameservers = append(nameservers, DNSNameserver{Address: address, Source: "kubernetes"})
+	}
+
+	// Querying each CoreDNS pod directly shows whether a single replica is misbehaving, which would
+	// otherwise be hidden behind the kube-dns service.
+	coreDNSPods, err := collector.getCoreDNSPodNameservers()
+	if err != nil {
+		log.Printf("Failed to list CoreDNS pods: %v", err)
+	}
+	nameservers = append(nameservers, coreDNSPods...)
+
+	names := collector.runtimeInfo.DNSProbeNames
+	if len(names) == 0 {
+		names = defaultDNSProbeNames
+	}
+
+	collector.Results = probeNameservers(nameservers, names, dnsProbeTimeout)
+
+	resultBytes, err := json.Marshal(collector.Results)
+	if err != nil {
+		return fmt.Errorf("marshall dns probe results to json: %w", err)
+	}
+	collector.data["dns_probe"] = string(resultBytes)
+
+	return nil
+}
+
+func (collector *DNSProbeCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+func (collector *DNSProbeCollector) getCoreDNSPodNameservers() ([]DNSNameserver, error) {
+	if collector.kubeconfig == nil {
+		return []DNSNameserver{}, nil
+	}
+
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	pods, err := clientset.CoreV1().Pods("kube-system").List(context.TODO(), metav1.ListOptions{LabelSelector: "k8s-app=kube-dns"})
+	if err != nil {
+		return nil, fmt.Errorf("pod list failure: %w", err)
+	}
+
+	nameservers := []DNSNameserver{}
+	for _, pod := range pods.Items {
+		if len(pod.Status.PodIP) > 0 {
+			nameservers = append(nameservers, DNSNameserver{Address: pod.Status.PodIP, Source: "coredns-pod " + pod.Name})
+		}
+	}
+
+	sort.Slice(nameservers, func(i, j int) bool { return nameservers[i].Source < nameservers[j].Source })
+	return nameservers, nil
+}
+
+// probeNameservers concurrently resolves each name against each nameserver, returning the results ordered by name.
+func probeNameservers(nameservers []DNSNameserver, names []string, timeout time.Duration) []DNSProbeResult {
+	results := make([]DNSProbeResult, len(names)*len(nameservers))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		for j, nameserver := range nameservers {
+			wg.Add(1)
+			go func(index int, name string, nameserver DNSNameserver) {
+				defer wg.Done()
+				results[index] = queryNameserver(nameserver, name, timeout)
+			}(i*len(nameservers)+j, name, nameserver)
+		}
+	}
+	wg.Wait()
+
+	return results
+}
+
+// queryNameserver sends an A query for name directly to nameserver over UDP, bypassing the local resolver
+// (and so the search domains), and records the response code, latency and answers.
+func queryNameserver(nameserver DNSNameserver, name string, timeout time.Duration) DNSProbeResult {
+	result := DNSProbeResult{
+		Name:       name,
+		Nameserver: nameserver,
+		Answers:    []string{},
+	}
+
+	fqdn := name
+	if !strings.HasSuffix(fqdn, ".") {
+		fqdn += "."
+	}
+
+	queryName, err := dnsmessage.NewName(fqdn)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: queryName, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}},
+	}
+	queryBytes, err := query.Pack()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	address := nameserver.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer conn.Close()
+	conn.SetDeadline(start.Add(timeout))
+
+	if _, err := conn.Write(queryBytes); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	buffer := make([]byte, 4096)
+	var response dnsmessage.Message
+	for {
+		n, err := conn.Read(buffer)
+		if err != nil {
+			result.LatencyMs = elapsedMilliseconds(start)
+			result.Error = err.Error()
+			return result
+		}
+
+		if err := response.Unpack(buffer[:n]); err != nil || response.Header.ID != id {
+			// Ignore malformed or unrelated responses, and keep waiting until the deadline.
+			continue
+		}
+		break
+	}
+	result.LatencyMs = elapsedMilliseconds(start)
+	result.Rcode = dnsRcodeName(response.Header.RCode)
+
+	for _, answer := range response.Answers {
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			result.Answers = append(result.Answers, net.IP(body.A[:]).String())
+		case *dnsmessage.CNAMEResource:
+			result.Answers = append(result.Answers, "CNAME "+body.CNAME.String())
+		}
+	}
+
+	return result
+}
+
+// dnsRcodeName returns the conventional (e.g. dig) name for a DNS response code.
+func dnsRcodeName(rcode dnsmessage.RCode) string {
+	switch rcode {
+	case dnsmessage.RCodeSuccess:
+		return "NOERROR"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	default:
+		return fmt.Sprintf("RCODE%d", rcode)
+	}
+}
@@ -0,0 +1,172 @@
+package collector
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSProbeCollectorGetName(t *testing.T) {
+	const expectedName = "dnsprobe"
+
+	c := NewDNSProbeCollector(nil, "", nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestDNSProbeCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewDNSProbeCollector(nil, tt.osIdentifier, nil, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+// startTestDNSServer starts a UDP DNS server that answers A queries for the names in records, and returns NXDOMAIN otherwise.
+func startTestDNSServer(t *testing.T, records map[string]string) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting DNS server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+
+			var query dnsmessage.Message
+			if err := query.Unpack(buffer[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+
+			question := query.Questions[0]
+			response := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.Header.ID, Response: true, RCode: dnsmessage.RCodeNameError},
+				Questions: query.Questions,
+			}
+			if address, ok := records[question.Name.String()]; ok {
+				var a [4]byte
+				copy(a[:], net.ParseIP(address).To4())
+				response.Header.RCode = dnsmessage.RCodeSuccess
+				response.Answers = []dnsmessage.Resource{
+					{
+						Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 30},
+						Body:   &dnsmessage.AResource{A: a},
+					},
+				}
+			}
+
+			responseBytes, err := response.Pack()
+			if err == nil {
+				conn.WriteTo(responseBytes, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestDNSProbeCollectorCollect(t *testing.T) {
+	clusterDNS := startTestDNSServer(t, map[string]string{
+		"kubernetes.default.svc.cluster.local.": "10.0.0.1",
+		"mcr.microsoft.com.":                    "204.79.197.219",
+	})
+	upstreamDNS := startTestDNSServer(t, map[string]string{
+		"mcr.microsoft.com.": "204.79.197.219",
+	})
+
+	filePaths := &utils.KnownFilePaths{
+		ResolvConfHost:      "/host/etc/resolv.conf",
+		ResolvConfContainer: "/etc/resolv.conf",
+	}
+	fs := test.NewFakeFileSystem(map[string]string{
+		"/host/etc/resolv.conf": "nameserver\t" + upstreamDNS + "\n",
+		"/etc/resolv.conf":      "search default.svc.cluster.local svc.cluster.local cluster.local\nnameserver " + clusterDNS + "\noptions ndots:5\n",
+	})
+	runtimeInfo := &utils.RuntimeInfo{
+		DNSProbeNames: []string{"kubernetes.default.svc.cluster.local", "mcr.microsoft.com"},
+	}
+
+	c := NewDNSProbeCollector(nil, utils.Linux, runtimeInfo, filePaths, fs)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expected := []struct {
+		name       string
+		nameserver DNSNameserver
+		rcode      string
+		answers    []string
+	}{
+		{name: "kubernetes.default.svc.cluster.local", nameserver: DNSNameserver{Address: upstreamDNS, Source: "virtualmachine"}, rcode: "NXDOMAIN", answers: []string{}},
+		{name: "kubernetes.default.svc.cluster.local", nameserver: DNSNameserver{Address: clusterDNS, Source: "kubernetes"}, rcode: "NOERROR", answers: []string{"10.0.0.1"}},
+		{name: "mcr.microsoft.com", nameserver: DNSNameserver{Address: upstreamDNS, Source: "virtualmachine"}, rcode: "NOERROR", answers: []string{"204.79.197.219"}},
+		{name: "mcr.microsoft.com", nameserver: DNSNameserver{Address: clusterDNS, Source: "kubernetes"}, rcode: "NOERROR", answers: []string{"204.79.197.219"}},
+	}
+
+	if len(c.Results) != len(expected) {
+		t.Fatalf("unexpected result count: expected %d, found %d", len(expected), len(c.Results))
+	}
+	for i, e := range expected {
+		result := c.Results[i]
+		if result.Name != e.name || result.Nameserver != e.nameserver || result.Rcode != e.rcode || !reflect.DeepEqual(result.Answers, e.answers) {
+			t.Errorf("unexpected result %d: %+v", i, result)
+		}
+		if len(result.Error) > 0 {
+			t.Errorf("unexpected error for result %d: %s", i, result.Error)
+		}
+	}
+
+	if c.ContainerResolvConf.Ndots != 5 {
+		t.Errorf("unexpected ndots: %d", c.ContainerResolvConf.Ndots)
+	}
+
+	testDataValue(t, c.GetData()["dns_probe"], func(raw string) {
+		var results []DNSProbeResult
+		if err := json.Unmarshal([]byte(raw), &results); err != nil {
+			t.Errorf("unmarshal GetData(): %v", err)
+		}
+	})
+}
+
+func TestDNSProbeCollectorCollectMissingResolvConf(t *testing.T) {
+	filePaths := &utils.KnownFilePaths{
+		ResolvConfHost:      "/host/etc/resolv.conf",
+		ResolvConfContainer: "/etc/resolv.conf",
+	}
+	fs := test.NewFakeFileSystem(map[string]string{
+		"/etc/resolv.conf": "nameserver 10.0.0.10",
+	})
+
+	c := NewDNSProbeCollector(nil, utils.Linux, &utils.RuntimeInfo{}, filePaths, fs)
+	if err := c.Collect(); err == nil {
+		t.Errorf("expected error for missing host resolv.conf")
+	}
+}
@@ -0,0 +1,385 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	defaultTimeWindow    = time.Hour
+	topWarningReasonsMax = 10
+	reasonExamplesMax    = 5
+)
+
+// notableWarningReasons are always included in the events summary, because they explain most scheduling,
+// crash-looping, storage and node pressure problems.
+var notableWarningReasons = []string{"FailedScheduling", "BackOff", "FailedMount", "Evicted"}
+
+// KubeEventObject identifies the object an event is about.
+type KubeEventObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// KubeEvent is an event from either the core/v1 or events.k8s.io/v1 API, with repeated occurrences combined.
+type KubeEvent struct {
+	Object         KubeEventObject `json:"object"`
+	Type           string          `json:"type"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Source         string          `json:"source"`
+	Count          int32           `json:"count"`
+	FirstTimestamp time.Time       `json:"firstTimestamp"`
+	LastTimestamp  time.Time       `json:"lastTimestamp"`
+	uid            string
+}
+
+// KubeObjectEvents are the events for a single object.
+type KubeObjectEvents struct {
+	Object   KubeEventObject `json:"object"`
+	Warnings int32           `json:"warnings"`
+	Events   []KubeEvent     `json:"events"`
+}
+
+// KubeEventReasonSummary is the number of occurrences of a Warning reason, and some of the objects it was reported for.
+type KubeEventReasonSummary struct {
+	Reason   string            `json:"reason"`
+	Count    int32             `json:"count"`
+	Objects  int               `json:"objects"`
+	Examples []KubeEventObject `json:"examples"`
+}
+
+// KubeEventsSummary summarizes the Warning events within the time window.
+type KubeEventsSummary struct {
+	WindowStart       time.Time                `json:"windowStart"`
+	WindowEnd         time.Time                `json:"windowEnd"`
+	Events            int                      `json:"events"`
+	WarningEvents     int                      `json:"warningEvents"`
+	TopWarningReasons []KubeEventReasonSummary `json:"topWarningReasons"`
+	NotableReasons    []KubeEventReasonSummary `json:"notableReasons"`
+}
+
+// EventsCollector defines an Events Collector struct
+type EventsCollector struct {
+	data        map[string]string
+	kubeconfig  *rest.Config
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewEventsCollector is a constructor
+func NewEventsCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *EventsCollector {
+	return &EventsCollector{
+		data:        make(map[string]string),
+		kubeconfig:  config,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *EventsCollector) GetName() string {
+	return "events"
+}
+
+func (collector *EventsCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *EventsCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	window := collector.runtimeInfo.TimeWindow
+	if window <= 0 {
+		window = defaultTimeWindow
+	}
+	windowEnd := time.Now()
+	windowStart := windowEnd.Add(-window)
+
+	events := []KubeEvent{}
+	for _, namespace := range getConfiguredNamespaces(collector.runtimeInfo) {
+		coreEvents, err := clientset.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing core/v1 events in namespace '%s': %w", namespace, err)
+		}
+		for i := range coreEvents.Items {
+			events = append(events, fromCoreEvent(&coreEvents.Items[i]))
+		}
+
+		// The events.k8s.io API is a different view of the same events, but includes those reported with series
+		// information by newer components. It may be unavailable on older clusters.
+		newEvents, err := clientset.EventsV1().Events(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			log.Printf("Failed to list events.k8s.io/v1 events in namespace '%s': %v", namespace, err)
+			continue
+		}
+		for i := range newEvents.Items {
+			events = append(events, fromEventsV1Event(&newEvents.Items[i]))
+		}
+	}
+
+	events = filterEventsByTime(dedupeEvents(events), windowStart)
+
+	eventsBytes, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("marshall events to json: %w", err)
+	}
+	collector.data["events"] = string(eventsBytes)
+
+	objectEventsBytes, err := json.Marshal(groupEventsByObject(events))
+	if err != nil {
+		return fmt.Errorf("marshall events by object to json: %w", err)
+	}
+	collector.data["events_by_object"] = string(objectEventsBytes)
+
+	summaryBytes, err := json.Marshal(summarizeEvents(events, windowStart, windowEnd))
+	if err != nil {
+		return fmt.Errorf("marshall events summary to json: %w", err)
+	}
+	collector.data["events_summary"] = string(summaryBytes)
+
+	return nil
+}
+
+func (collector *EventsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// getConfiguredNamespaces returns the namespaces to collect cluster resources from, where an empty
+// configuration means all namespaces.
+func getConfiguredNamespaces(runtimeInfo *utils.RuntimeInfo) []string {
+	if len(runtimeInfo.Namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return runtimeInfo.Namespaces
+}
+
+func fromCoreEvent(event *corev1.Event) KubeEvent {
+	result := KubeEvent{
+		Object: KubeEventObject{
+			Kind:      event.InvolvedObject.Kind,
+			Namespace: event.InvolvedObject.Namespace,
+			Name:      event.InvolvedObject.Name,
+		},
+		Type:           event.Type,
+		Reason:         event.Reason,
+		Message:        event.Message,
+		Source:         event.Source.Component,
+		Count:          event.Count,
+		FirstTimestamp: event.FirstTimestamp.Time,
+		LastTimestamp:  event.LastTimestamp.Time,
+		uid:            string(event.UID),
+	}
+
+	if len(result.Source) == 0 {
+		result.Source = event.ReportingController
+	}
+
+	// Events reported through the events.k8s.io API only set the event time and series in the core/v1 view.
+	if result.FirstTimestamp.IsZero() {
+		result.FirstTimestamp = event.EventTime.Time
+	}
+	if result.LastTimestamp.IsZero() {
+		result.LastTimestamp = result.FirstTimestamp
+		if event.Series != nil {
+			result.LastTimestamp = event.Series.LastObservedTime.Time
+		}
+	}
+	if result.Count == 0 {
+		result.Count = 1
+		if event.Series != nil {
+			result.Count = event.Series.Count
+		}
+	}
+
+	return result
+}
+
+func fromEventsV1Event(event *eventsv1.Event) KubeEvent {
+	result := KubeEvent{
+		Object: KubeEventObject{
+			Kind:      event.Regarding.Kind,
+			Namespace: event.Regarding.Namespace,
+			Name:      event.Regarding.Name,
+		},
+		Type:           event.Type,
+		Reason:         event.Reason,
+		Message:        event.Note,
+		Source:         event.ReportingController,
+		Count:          event.DeprecatedCount,
+		FirstTimestamp: event.EventTime.Time,
+		LastTimestamp:  event.DeprecatedLastTimestamp.Time,
+		uid:            string(event.UID),
+	}
+
+	if len(result.Source) == 0 {
+		result.Source = event.DeprecatedSource.Component
+	}
+
+	// Events reported through the core/v1 API only set the deprecated fields in the events.k8s.io view.
+	if result.FirstTimestamp.IsZero() {
+		result.FirstTimestamp = event.DeprecatedFirstTimestamp.Time
+	}
+	if event.Series != nil {
+		result.Count = event.Series.Count
+		result.LastTimestamp = event.Series.LastObservedTime.Time
+	}
+	if result.LastTimestamp.IsZero() {
+		result.LastTimestamp = result.FirstTimestamp
+	}
+	if result.Count == 0 {
+		result.Count = 1
+	}
+
+	return result
+}
+
+// dedupeEvents removes events seen through both APIs, and combines repeated events for the same object, type,
+// reason and message (such as a series that has been recorded as separate events) into one, totalling their counts.
+func dedupeEvents(events []KubeEvent) []KubeEvent {
+	seenUIDs := map[string]bool{}
+	keys := []string{}
+	combined := map[string]*KubeEvent{}
+	for _, event := range events {
+		if len(event.uid) > 0 {
+			if seenUIDs[event.uid] {
+				continue
+			}
+			seenUIDs[event.uid] = true
+		}
+
+		key := fmt.Sprintf("%s/%s/%s\x00%s\x00%s\x00%s", event.Object.Kind, event.Object.Namespace, event.Object.Name, event.Type, event.Reason, event.Message)
+		existing, ok := combined[key]
+		if !ok {
+			event := event
+			combined[key] = &event
+			keys = append(keys, key)
+			continue
+		}
+
+		existing.Count += event.Count
+		if !event.FirstTimestamp.IsZero() && (existing.FirstTimestamp.IsZero() || event.FirstTimestamp.Before(existing.FirstTimestamp)) {
+			existing.FirstTimestamp = event.FirstTimestamp
+		}
+		if event.LastTimestamp.After(existing.LastTimestamp) {
+			existing.LastTimestamp = event.LastTimestamp
+		}
+	}
+
+	result := make([]KubeEvent, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *combined[key])
+	}
+	return result
+}
+
+// filterEventsByTime returns the events last seen at or after start, ordered by when they were last seen.
+func filterEventsByTime(events []KubeEvent, start time.Time) []KubeEvent {
+	result := []KubeEvent{}
+	for _, event := range events {
+		if !event.LastTimestamp.Before(start) {
+			result = append(result, event)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].LastTimestamp.Before(result[j].LastTimestamp) })
+	return result
+}
+
+// groupEventsByObject groups events by the object they are about, listing objects with the most warnings first.
+func groupEventsByObject(events []KubeEvent) []KubeObjectEvents {
+	groups := []*KubeObjectEvents{}
+	groupsByObject := map[KubeEventObject]*KubeObjectEvents{}
+	for _, event := range events {
+		group, ok := groupsByObject[event.Object]
+		if !ok {
+			group = &KubeObjectEvents{Object: event.Object, Events: []KubeEvent{}}
+			groupsByObject[event.Object] = group
+			groups = append(groups, group)
+		}
+
+		group.Events = append(group.Events, event)
+		if event.Type == corev1.EventTypeWarning {
+			group.Warnings += event.Count
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Warnings > groups[j].Warnings })
+
+	result := make([]KubeObjectEvents, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	return result
+}
+
+// summarizeEvents counts the Warning events by reason, listing the most common reasons, and always listing
+// the notable reasons.
+func summarizeEvents(events []KubeEvent, windowStart, windowEnd time.Time) KubeEventsSummary {
+	summary := KubeEventsSummary{
+		WindowStart:       windowStart,
+		WindowEnd:         windowEnd,
+		Events:            len(events),
+		TopWarningReasons: []KubeEventReasonSummary{},
+		NotableReasons:    []KubeEventReasonSummary{},
+	}
+
+	reasons := []*KubeEventReasonSummary{}
+	reasonSummaries := map[string]*KubeEventReasonSummary{}
+	reasonObjects := map[string]map[KubeEventObject]bool{}
+	for _, event := range events {
+		if event.Type != corev1.EventTypeWarning {
+			continue
+		}
+		summary.WarningEvents++
+
+		reasonSummary, ok := reasonSummaries[event.Reason]
+		if !ok {
+			reasonSummary = &KubeEventReasonSummary{Reason: event.Reason, Examples: []KubeEventObject{}}
+			reasonSummaries[event.Reason] = reasonSummary
+			reasonObjects[event.Reason] = map[KubeEventObject]bool{}
+			reasons = append(reasons, reasonSummary)
+		}
+
+		reasonSummary.Count += event.Count
+		if !reasonObjects[event.Reason][event.Object] {
+			reasonObjects[event.Reason][event.Object] = true
+			reasonSummary.Objects++
+			if len(reasonSummary.Examples) < reasonExamplesMax {
+				reasonSummary.Examples = append(reasonSummary.Examples, event.Object)
+			}
+		}
+	}
+
+	sort.SliceStable(reasons, func(i, j int) bool { return reasons[i].Count > reasons[j].Count })
+	for i, reason := range reasons {
+		if i == topWarningReasonsMax {
+			break
+		}
+		summary.TopWarningReasons = append(summary.TopWarningReasons, *reason)
+	}
+
+	for _, reason := range notableWarningReasons {
+		reasonSummary, ok := reasonSummaries[reason]
+		if !ok {
+			reasonSummary = &KubeEventReasonSummary{Reason: reason, Examples: []KubeEventObject{}}
+		}
+		summary.NotableReasons = append(summary.NotableReasons, *reasonSummary)
+	}
+
+	return summary
+}
@@ -0,0 +1,203 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventsCollectorGetName(t *testing.T) {
+	const expectedName = "events"
+
+	c := NewEventsCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestEventsCollectorCheckSupported(t *testing.T) {
+	c := NewEventsCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestEventsCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		Namespaces:    []string{"kube-system"},
+		TimeWindow:    24 * time.Hour,
+	}
+
+	c := NewEventsCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"events":           regexp.MustCompile(`^\[.*\]$`),
+		"events_by_object": regexp.MustCompile(`^\[.*\]$`),
+		"events_summary":   regexp.MustCompile(`"notableReasons":\[\{"reason":"FailedScheduling"`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestKubeEventConversion(t *testing.T) {
+	first := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	last := first.Add(5 * time.Minute)
+
+	tests := []struct {
+		name  string
+		event KubeEvent
+		want  KubeEvent
+	}{
+		{
+			name: "core/v1 event with deprecated count",
+			event: fromCoreEvent(&corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{UID: "1"},
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "app"},
+				Type:           "Warning",
+				Reason:         "BackOff",
+				Message:        "Back-off restarting failed container",
+				Source:         corev1.EventSource{Component: "kubelet"},
+				Count:          7,
+				FirstTimestamp: metav1.NewTime(first),
+				LastTimestamp:  metav1.NewTime(last),
+			}),
+			want: KubeEvent{
+				Object:         KubeEventObject{Kind: "Pod", Namespace: "default", Name: "app"},
+				Type:           "Warning",
+				Reason:         "BackOff",
+				Message:        "Back-off restarting failed container",
+				Source:         "kubelet",
+				Count:          7,
+				FirstTimestamp: first,
+				LastTimestamp:  last,
+				uid:            "1",
+			},
+		},
+		{
+			name: "events.k8s.io/v1 event with series",
+			event: fromEventsV1Event(&eventsv1.Event{
+				ObjectMeta:          metav1.ObjectMeta{UID: "2"},
+				Regarding:           corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web"},
+				Type:                "Warning",
+				Reason:              "FailedScheduling",
+				Note:                "0/3 nodes are available",
+				ReportingController: "default-scheduler",
+				EventTime:           metav1.NewMicroTime(first),
+				Series:              &eventsv1.EventSeries{Count: 4, LastObservedTime: metav1.NewMicroTime(last)},
+			}),
+			want: KubeEvent{
+				Object:         KubeEventObject{Kind: "Pod", Namespace: "default", Name: "web"},
+				Type:           "Warning",
+				Reason:         "FailedScheduling",
+				Message:        "0/3 nodes are available",
+				Source:         "default-scheduler",
+				Count:          4,
+				FirstTimestamp: first,
+				LastTimestamp:  last,
+				uid:            "2",
+			},
+		},
+		{
+			name: "core/v1 view of an events.k8s.io/v1 event",
+			event: fromCoreEvent(&corev1.Event{
+				ObjectMeta:          metav1.ObjectMeta{UID: "3"},
+				InvolvedObject:      corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+				Type:                "Normal",
+				Reason:              "RegisteredNode",
+				ReportingController: "node-controller",
+				EventTime:           metav1.NewMicroTime(first),
+			}),
+			want: KubeEvent{
+				Object:         KubeEventObject{Kind: "Node", Name: "node-1"},
+				Type:           "Normal",
+				Reason:         "RegisteredNode",
+				Source:         "node-controller",
+				Count:          1,
+				FirstTimestamp: first,
+				LastTimestamp:  first,
+				uid:            "3",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.event, tt.want) {
+				t.Errorf("unexpected event:\nexpected %+v\nfound    %+v", tt.want, tt.event)
+			}
+		})
+	}
+}
+
+func TestDedupeAndGroupEvents(t *testing.T) {
+	start := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	pod := KubeEventObject{Kind: "Pod", Namespace: "default", Name: "app"}
+	otherPod := KubeEventObject{Kind: "Pod", Namespace: "default", Name: "other"}
+	node := KubeEventObject{Kind: "Node", Name: "node-1"}
+
+	events := []KubeEvent{
+		{Object: pod, Type: "Warning", Reason: "BackOff", Message: "back-off", Count: 3, FirstTimestamp: start.Add(time.Minute), LastTimestamp: start.Add(2 * time.Minute), uid: "a"},
+		// The same event, seen through the events.k8s.io API.
+		{Object: pod, Type: "Warning", Reason: "BackOff", Message: "back-off", Count: 3, FirstTimestamp: start.Add(time.Minute), LastTimestamp: start.Add(2 * time.Minute), uid: "a"},
+		// A repeat of the same event recorded separately.
+		{Object: pod, Type: "Warning", Reason: "BackOff", Message: "back-off", Count: 2, FirstTimestamp: start.Add(3 * time.Minute), LastTimestamp: start.Add(4 * time.Minute), uid: "b"},
+		{Object: otherPod, Type: "Warning", Reason: "BackOff", Message: "back-off", Count: 1, FirstTimestamp: start.Add(time.Minute), LastTimestamp: start.Add(time.Minute), uid: "c"},
+		{Object: node, Type: "Warning", Reason: "Evicted", Message: "low memory", Count: 1, FirstTimestamp: start.Add(5 * time.Minute), LastTimestamp: start.Add(5 * time.Minute), uid: "d"},
+		{Object: node, Type: "Normal", Reason: "Starting", Message: "starting", Count: 1, FirstTimestamp: start.Add(-time.Hour), LastTimestamp: start.Add(-time.Hour), uid: "e"},
+	}
+
+	result := filterEventsByTime(dedupeEvents(events), start)
+	if len(result) != 3 {
+		t.Fatalf("unexpected number of events: expected 3, found %d: %+v", len(result), result)
+	}
+
+	if result[0].Object != otherPod || result[1].Object != pod || result[2].Object != node {
+		t.Errorf("unexpected event order: %+v", result)
+	}
+
+	if result[1].Count != 5 || !result[1].FirstTimestamp.Equal(start.Add(time.Minute)) || !result[1].LastTimestamp.Equal(start.Add(4*time.Minute)) {
+		t.Errorf("unexpected combined event: %+v", result[1])
+	}
+
+	groups := groupEventsByObject(result)
+	if len(groups) != 3 || groups[0].Object != pod || groups[0].Warnings != 5 {
+		t.Errorf("unexpected groups: %+v", groups)
+	}
+
+	summary := summarizeEvents(result, start, start.Add(time.Hour))
+	expectedTopReasons := []KubeEventReasonSummary{
+		{Reason: "BackOff", Count: 6, Objects: 2, Examples: []KubeEventObject{otherPod, pod}},
+		{Reason: "Evicted", Count: 1, Objects: 1, Examples: []KubeEventObject{node}},
+	}
+	if !reflect.DeepEqual(summary.TopWarningReasons, expectedTopReasons) {
+		t.Errorf("unexpected top reasons:\nexpected %+v\nfound    %+v", expectedTopReasons, summary.TopWarningReasons)
+	}
+
+	if len(summary.NotableReasons) != len(notableWarningReasons) {
+		t.Fatalf("unexpected notable reasons: %+v", summary.NotableReasons)
+	}
+	for i, reason := range notableWarningReasons {
+		if summary.NotableReasons[i].Reason != reason {
+			t.Errorf("unexpected notable reason at %d: expected %s, found %s", i, reason, summary.NotableReasons[i].Reason)
+		}
+	}
+	if summary.NotableReasons[0].Count != 0 || summary.NotableReasons[1].Count != 6 || summary.NotableReasons[3].Count != 1 {
+		t.Errorf("unexpected notable reason counts: %+v", summary.NotableReasons)
+	}
+}
@@ -2,36 +2,85 @@ package collector
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
 	"github.com/Azure/aks-periscope/pkg/utils"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
 	restclient "k8s.io/client-go/rest"
 )
 
+// helmRedactedValue replaces sensitive values and secret data.
+const helmRedactedValue = "---redacted---"
+
+// helmSensitiveKeyPattern matches the names of values that are likely to hold credentials.
+var helmSensitiveKeyPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|key|credential|cert|connectionstring|auth)`)
+
 type HelmRelease struct {
-	Name      string               `json:"name"`
-	Namespace string               `json:"namespace"`
-	Status    release.Status       `json:"status"`
-	ChartName string               `json:"chart"`
-	History   []HelmReleaseHistory `json:"history"`
+	Name         string               `json:"name"`
+	Namespace    string               `json:"namespace"`
+	Status       release.Status       `json:"status"`
+	ChartName    string               `json:"chart"`
+	ChartVersion string               `json:"chartVersion"`
+	AppVersion   string               `json:"appVersion"`
+	Revision     int                  `json:"revision"`
+	History      []HelmReleaseHistory `json:"history"`
 }
 
 type HelmReleaseHistory struct {
-	Date       time.Time      `json:"lastDeployment"`
-	Message    string         `json:"description"`
-	Status     release.Status `json:"status"`
-	Revision   int            `json:"revision"`
-	AppVersion string         `json:"appVersion"`
+	Date         time.Time      `json:"lastDeployment"`
+	Message      string         `json:"description"`
+	Status       release.Status `json:"status"`
+	Revision     int            `json:"revision"`
+	ChartVersion string         `json:"chartVersion"`
+	AppVersion   string         `json:"appVersion"`
+}
+
+// HelmHook is a hook of a release, and the result of its last run.
+type HelmHook struct {
+	Name           string    `json:"name"`
+	Kind           string    `json:"kind"`
+	Path           string    `json:"path"`
+	Events         []string  `json:"events"`
+	Weight         int       `json:"weight"`
+	DeletePolicies []string  `json:"deletePolicies"`
+	LastRunPhase   string    `json:"lastRunPhase"`
+	LastRunStarted time.Time `json:"lastRunStarted"`
+	LastRunEnded   time.Time `json:"lastRunCompleted"`
+}
+
+// HelmDriftDifference is a field whose live value differs from the release manifest.
+type HelmDriftDifference struct {
+	Path     string `json:"path"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// HelmDriftObject is an object in a release manifest that is missing from the cluster or differs from the manifest.
+type HelmDriftObject struct {
+	APIVersion  string                `json:"apiVersion"`
+	Kind        string                `json:"kind"`
+	Namespace   string                `json:"namespace,omitempty"`
+	Name        string                `json:"name"`
+	Missing     bool                  `json:"missing"`
+	Differences []HelmDriftDifference `json:"differences,omitempty"`
+	Error       string                `json:"error,omitempty"`
 }
 
 // HelmCollector defines a Helm Collector struct
@@ -39,6 +88,7 @@ type HelmCollector struct {
 	data        map[string]string
 	kubeconfig  *restclient.Config
 	runtimeInfo *utils.RuntimeInfo
+	Releases    []HelmRelease
 }
 
 // NewHelmCollector is a constructor
@@ -80,26 +130,43 @@ func (collector *HelmCollector) ToRawKubeConfigLoader() clientcmd.ClientConfig {
 
 // Collect implements the interface method
 func (collector *HelmCollector) Collect() error {
-	actionConfig := new(action.Configuration)
-
-	if err := actionConfig.Init(collector, "", "", log.Printf); err != nil {
-		return fmt.Errorf("init action configuration: %w", err)
+	actionConfig, err := collector.getActionConfig()
+	if err != nil {
+		return err
 	}
 
-	releases, err := action.NewList(actionConfig).Run()
+	// Pending and failed releases are included, because these are the ones most likely to need investigating.
+	list := action.NewList(actionConfig)
+	list.StateMask = action.ListDeployed | action.ListFailed | action.ListPendingInstall | action.ListPendingUpgrade | action.ListPendingRollback | action.ListUninstalling
+	releases, err := list.Run()
 	if err != nil {
 		return fmt.Errorf("list helm releases: %w", err)
 	}
 
+	// Drift is found using the same RESTMapper for every release, so that API discovery is only done once.
+	var mapper meta.RESTMapper
+	if utils.Contains(collector.runtimeInfo.HelmDetails, "drift") {
+		_, mapper, err = getDiscoveryClientAndMapper(collector.kubeconfig)
+		if err != nil {
+			return err
+		}
+	}
+
 	result := make([]HelmRelease, 0)
 
 	for _, release := range releases {
-		release.Chart.AppVersion()
+		if !isHelmReleaseIncluded(collector.runtimeInfo, release.Namespace, release.Name) {
+			continue
+		}
+
 		r := HelmRelease{
-			Name:      release.Name,
-			Namespace: release.Namespace,
-			Status:    release.Info.Status,
-			ChartName: release.Chart.Name(),
+			Name:         release.Name,
+			Namespace:    release.Namespace,
+			Status:       release.Info.Status,
+			ChartName:    release.Chart.Name(),
+			ChartVersion: getHelmChartVersion(release.Chart),
+			AppVersion:   release.Chart.AppVersion(),
+			Revision:     release.Version,
 		}
 
 		histories, err := action.NewHistory(actionConfig).Run(release.Name)
@@ -109,20 +176,30 @@ func (collector *HelmCollector) Collect() error {
 		} else {
 			r.History = make([]HelmReleaseHistory, 0)
 			for _, history := range histories {
+				// Releases are looked up by name across all namespaces, so same-named releases elsewhere are skipped.
+				if history.Namespace != release.Namespace {
+					continue
+				}
 				h := HelmReleaseHistory{
-					Date:       history.Info.LastDeployed.Time,
-					Message:    history.Info.Description,
-					Status:     history.Info.Status,
-					Revision:   history.Version,
-					AppVersion: history.Chart.AppVersion(),
+					Date:         history.Info.LastDeployed.Time,
+					Message:      history.Info.Description,
+					Status:       history.Info.Status,
+					Revision:     history.Version,
+					ChartVersion: getHelmChartVersion(history.Chart),
+					AppVersion:   history.Chart.AppVersion(),
 				}
 				r.History = append(r.History, h)
 			}
+			sort.Slice(r.History, func(i, j int) bool { return r.History[i].Revision < r.History[j].Revision })
 		}
 
 		result = append(result, r)
+
+		collector.collectReleaseDetails(release, mapper)
 	}
 
+	collector.Releases = result
+
 	b, err := json.Marshal(result)
 
 	if err != nil {
@@ -134,6 +211,328 @@ func (collector *HelmCollector) Collect() error {
 	return nil
 }
 
+// collectReleaseDetails collects the optional details listed in DIAGNOSTIC_HELM_DETAILS_LIST for a release.
+func (collector *HelmCollector) collectReleaseDetails(release *release.Release, mapper meta.RESTMapper) {
+	keyPrefix := fmt.Sprintf("helm/%s_%s_", release.Namespace, release.Name)
+	details := collector.runtimeInfo.HelmDetails
+
+	if utils.Contains(details, "values") {
+		collector.setDetail(keyPrefix+"values", redactHelmValues(release.Config))
+	}
+
+	if utils.Contains(details, "notes") {
+		collector.data[keyPrefix+"notes"] = release.Info.Notes
+	}
+
+	if utils.Contains(details, "hooks") {
+		collector.setDetail(keyPrefix+"hooks", getHelmHooks(release.Hooks))
+	}
+
+	if !utils.Contains(details, "manifest") && !utils.Contains(details, "drift") {
+		return
+	}
+
+	objects, err := parseHelmManifest(release.Manifest)
+	if err != nil {
+		value := fmt.Sprintf("Failed to parse manifest of release %s: %+v\n", release.Name, err)
+		log.Print(value)
+		collector.data[keyPrefix+"manifest"] = value
+		return
+	}
+
+	if utils.Contains(details, "manifest") {
+		redactedObjects := []map[string]interface{}{}
+		for _, object := range objects {
+			redactedObjects = append(redactedObjects, redactSecretData(object))
+		}
+		collector.setDetail(keyPrefix+"manifest", redactedObjects)
+	}
+
+	if utils.Contains(details, "drift") {
+		collector.setDetail(keyPrefix+"drift", collector.getHelmDrift(mapper, release.Namespace, objects))
+	}
+}
+
+func (collector *HelmCollector) setDetail(key string, value interface{}) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		valueString := fmt.Sprintf("Failed to marshall %s to json: %+v\n", key, err)
+		log.Print(valueString)
+		collector.data[key] = valueString
+		return
+	}
+	collector.data[key] = string(valueBytes)
+}
+
+// getHelmDrift compares each object in a release manifest with the live object in the cluster, returning those
+// that are missing or whose fields differ from the manifest.
+func (collector *HelmCollector) getHelmDrift(mapper meta.RESTMapper, releaseNamespace string, objects []map[string]interface{}) []HelmDriftObject {
+	commandRunner := utils.NewKubeCommandRunner(collector.kubeconfig)
+
+	drift := []HelmDriftObject{}
+	for _, object := range objects {
+		expected := &unstructured.Unstructured{Object: object}
+		driftObject := HelmDriftObject{
+			APIVersion: expected.GetAPIVersion(),
+			Kind:       expected.GetKind(),
+			Namespace:  expected.GetNamespace(),
+			Name:       expected.GetName(),
+		}
+
+		// Secrets are not compared, because their contents are never collected.
+		if driftObject.APIVersion == "v1" && driftObject.Kind == "Secret" {
+			continue
+		}
+
+		gvk := expected.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			driftObject.Error = fmt.Sprintf("unable to find resource for %s: %v", gvk.String(), err)
+			drift = append(drift, driftObject)
+			continue
+		}
+
+		// Helm installs namespaced objects without a namespace into the release namespace.
+		namespace := ""
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace = driftObject.Namespace
+			if len(namespace) == 0 {
+				namespace = releaseNamespace
+			}
+			driftObject.Namespace = namespace
+		}
+
+		live, err := commandRunner.GetUnstructuredItem(&mapping.Resource, namespace, driftObject.Name)
+		if err != nil {
+			if k8sErrors.IsNotFound(err) {
+				driftObject.Missing = true
+			} else {
+				driftObject.Error = err.Error()
+			}
+			drift = append(drift, driftObject)
+			continue
+		}
+
+		driftObject.Differences = getHelmObjectDifferences(object, live.Object)
+		if len(driftObject.Differences) > 0 {
+			drift = append(drift, driftObject)
+		}
+	}
+
+	return drift
+}
+
+// getHelmChartVersion returns the version of a chart, which is missing if the chart has no metadata.
+func getHelmChartVersion(ch *chart.Chart) string {
+	if ch.Metadata == nil {
+		return ""
+	}
+	return ch.Metadata.Version
+}
+
+// getActionConfig initializes a helm action configuration for releases in all namespaces.
+func (collector *HelmCollector) getActionConfig() (*action.Configuration, error) {
+	actionConfig := new(action.Configuration)
+
+	if err := actionConfig.Init(collector, "", "", log.Printf); err != nil {
+		return nil, fmt.Errorf("init action configuration: %w", err)
+	}
+
+	return actionConfig, nil
+}
+
 func (collector *HelmCollector) GetData() map[string]interfaces.DataValue {
 	return utils.ToDataValueMap(collector.data)
 }
+
+// isHelmReleaseIncluded returns whether a release is in the configured namespaces and Helm releases, if any.
+func isHelmReleaseIncluded(runtimeInfo *utils.RuntimeInfo, namespace, name string) bool {
+	if len(runtimeInfo.Namespaces) > 0 && !utils.Contains(runtimeInfo.Namespaces, namespace) {
+		return false
+	}
+	if len(runtimeInfo.HelmReleases) > 0 && !utils.Contains(runtimeInfo.HelmReleases, name) {
+		return false
+	}
+	return true
+}
+
+// redactHelmValues copies user-supplied values, replacing any value whose key looks like it holds a credential.
+func redactHelmValues(values map[string]interface{}) map[string]interface{} {
+	redacted := map[string]interface{}{}
+	for key, value := range values {
+		if value != nil && helmSensitiveKeyPattern.MatchString(key) {
+			redacted[key] = helmRedactedValue
+			continue
+		}
+		redacted[key] = redactHelmValue(value)
+	}
+	return redacted
+}
+
+func redactHelmValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		return redactHelmValues(typedValue)
+	case []interface{}:
+		redacted := make([]interface{}, len(typedValue))
+		for i, item := range typedValue {
+			redacted[i] = redactHelmValue(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// redactSecretData copies a manifest object, replacing the values in the data of Secrets.
+func redactSecretData(object map[string]interface{}) map[string]interface{} {
+	if object["apiVersion"] != "v1" || object["kind"] != "Secret" {
+		return object
+	}
+
+	redacted := map[string]interface{}{}
+	for key, value := range object {
+		redacted[key] = value
+	}
+	for _, field := range []string{"data", "stringData"} {
+		data, ok := object[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		redactedData := map[string]interface{}{}
+		for key := range data {
+			redactedData[key] = helmRedactedValue
+		}
+		redacted[field] = redactedData
+	}
+	return redacted
+}
+
+// getHelmHooks summarizes the hooks of a release, without their manifests.
+func getHelmHooks(hooks []*release.Hook) []HelmHook {
+	result := []HelmHook{}
+	for _, hook := range hooks {
+		h := HelmHook{
+			Name:           hook.Name,
+			Kind:           hook.Kind,
+			Path:           hook.Path,
+			Events:         []string{},
+			Weight:         hook.Weight,
+			DeletePolicies: []string{},
+			LastRunPhase:   hook.LastRun.Phase.String(),
+			LastRunStarted: hook.LastRun.StartedAt.Time,
+			LastRunEnded:   hook.LastRun.CompletedAt.Time,
+		}
+		for _, event := range hook.Events {
+			h.Events = append(h.Events, event.String())
+		}
+		for _, policy := range hook.DeletePolicies {
+			h.DeletePolicies = append(h.DeletePolicies, policy.String())
+		}
+		result = append(result, h)
+	}
+	return result
+}
+
+// parseHelmManifest decodes the objects in a multi-document YAML manifest, skipping empty documents.
+func parseHelmManifest(manifest string) ([]map[string]interface{}, error) {
+	objects := []map[string]interface{}{}
+
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	for {
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error decoding manifest: %w", err)
+		}
+
+		if len(object) > 0 {
+			objects = append(objects, object)
+		}
+	}
+
+	return objects, nil
+}
+
+// getHelmObjectDifferences compares the fields set in a manifest object with those of the live object. Fields only
+// set in the live object, such as defaults, metadata added by the API server and status, are ignored.
+func getHelmObjectDifferences(expected, live map[string]interface{}) []HelmDriftDifference {
+	differences := []HelmDriftDifference{}
+	for _, key := range []string{"spec", "data", "rules", "roleRef", "subjects", "webhooks"} {
+		if value, ok := expected[key]; ok {
+			differences = appendHelmValueDifferences(differences, key, value, live[key])
+		}
+	}
+	if metadata, ok := expected["metadata"].(map[string]interface{}); ok {
+		liveMetadata, _ := live["metadata"].(map[string]interface{})
+		for _, key := range []string{"labels", "annotations"} {
+			if value, ok := metadata[key]; ok {
+				differences = appendHelmValueDifferences(differences, "metadata."+key, value, liveMetadata[key])
+			}
+		}
+	}
+	return differences
+}
+
+func appendHelmValueDifferences(differences []HelmDriftDifference, path string, expected, actual interface{}) []HelmDriftDifference {
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if len(expectedValue) == 0 && actual == nil {
+			return differences
+		}
+		if !ok {
+			return append(differences, getHelmValueDifference(path, expected, actual))
+		}
+		keys := []string{}
+		for key := range expectedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			differences = appendHelmValueDifferences(differences, path+"."+key, expectedValue[key], actualValue[key])
+		}
+		return differences
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if len(expectedValue) == 0 && actual == nil {
+			return differences
+		}
+		if !ok || len(actualValue) != len(expectedValue) {
+			return append(differences, getHelmValueDifference(path, expected, actual))
+		}
+		for i := range expectedValue {
+			differences = appendHelmValueDifferences(differences, fmt.Sprintf("%s[%d]", path, i), expectedValue[i], actualValue[i])
+		}
+		return differences
+	case nil:
+		return differences
+	default:
+		// Scalars are compared by their text, because manifests often quote numbers and booleans that the API
+		// server stores without quotes, or the reverse.
+		if formatHelmScalar(expected) != formatHelmScalar(actual) {
+			return append(differences, getHelmValueDifference(path, expected, actual))
+		}
+		return differences
+	}
+}
+
+func formatHelmScalar(value interface{}) string {
+	// Decoded manifests hold all numbers as floats, while live objects hold integers as int64.
+	if floatValue, ok := value.(float64); ok && floatValue == float64(int64(floatValue)) {
+		return fmt.Sprint(int64(floatValue))
+	}
+	return fmt.Sprint(value)
+}
+
+func getHelmValueDifference(path string, expected, actual interface{}) HelmDriftDifference {
+	expectedBytes, _ := json.Marshal(expected)
+	actualBytes, _ := json.Marshal(actual)
+	return HelmDriftDifference{
+		Path:     path,
+		Expected: string(expectedBytes),
+		Actual:   string(actualBytes),
+	}
+}
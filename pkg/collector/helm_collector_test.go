@@ -3,10 +3,17 @@ package collector
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/aks-periscope/pkg/test"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
 	"k8s.io/client-go/rest"
 )
 
@@ -115,3 +122,240 @@ func TestHelmCollectorCollect(t *testing.T) {
 		})
 	}
 }
+
+func TestHelmCollectorCollectDetails(t *testing.T) {
+	clientConfig := setupHelmTest(t)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{"connectedCluster"},
+		HelmReleases:  []string{releaseName},
+		HelmDetails:   []string{"manifest", "values", "notes", "hooks", "drift"},
+	}
+
+	c := NewHelmCollector(clientConfig, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	data := c.GetData()
+	expectedDetails := map[string]*regexp.Regexp{
+		"manifest": regexp.MustCompile(`"kind":"Deployment"`),
+		"values":   regexp.MustCompile(`^\{.*\}$`),
+		"notes":    regexp.MustCompile(`.+`),
+		"hooks":    regexp.MustCompile(`"name":"helmtest-release-testchart-test-connection"`),
+		"drift":    regexp.MustCompile(`^\[.*\]$`),
+	}
+	for detail, pattern := range expectedDetails {
+		found := false
+		for key, value := range data {
+			if !strings.HasPrefix(key, "helm/") || !strings.HasSuffix(key, "_"+releaseName+"_"+detail) {
+				continue
+			}
+			found = true
+			testDataValue(t, value, func(raw string) {
+				if !pattern.MatchString(raw) {
+					t.Errorf("unexpected %s for key %s: %s", detail, key, raw)
+				}
+			})
+		}
+		if !found {
+			t.Errorf("no %s collected for release %s", detail, releaseName)
+		}
+	}
+}
+
+func TestIsHelmReleaseIncluded(t *testing.T) {
+	runtimeInfo := &utils.RuntimeInfo{
+		Namespaces:   []string{"app"},
+		HelmReleases: []string{"web"},
+	}
+
+	if !isHelmReleaseIncluded(runtimeInfo, "app", "web") {
+		t.Errorf("expected app/web to be included")
+	}
+	if isHelmReleaseIncluded(runtimeInfo, "other", "web") {
+		t.Errorf("expected other/web to be excluded")
+	}
+	if isHelmReleaseIncluded(runtimeInfo, "app", "db") {
+		t.Errorf("expected app/db to be excluded")
+	}
+	if !isHelmReleaseIncluded(&utils.RuntimeInfo{}, "other", "db") {
+		t.Errorf("expected all releases to be included without filters")
+	}
+}
+
+func TestGetHelmChartVersion(t *testing.T) {
+	if version := getHelmChartVersion(&chart.Chart{Metadata: &chart.Metadata{Version: "1.2.3"}}); version != "1.2.3" {
+		t.Errorf("unexpected chart version: expected 1.2.3, found %s", version)
+	}
+
+	if version := getHelmChartVersion(&chart.Chart{}); version != "" {
+		t.Errorf("expected empty chart version without metadata, found %s", version)
+	}
+}
+
+func TestRedactHelmValues(t *testing.T) {
+	values := map[string]interface{}{
+		"replicaCount": float64(2),
+		"auth": map[string]interface{}{
+			"username": "admin",
+		},
+		"database": map[string]interface{}{
+			"host":     "db.example.com",
+			"password": "hunter2",
+			"replicas": []interface{}{
+				map[string]interface{}{"name": "replica-1", "apiToken": "abc"},
+			},
+		},
+		"tlsSecretName": nil,
+	}
+
+	expected := map[string]interface{}{
+		"replicaCount": float64(2),
+		"auth":         helmRedactedValue,
+		"database": map[string]interface{}{
+			"host":     "db.example.com",
+			"password": helmRedactedValue,
+			"replicas": []interface{}{
+				map[string]interface{}{"name": "replica-1", "apiToken": helmRedactedValue},
+			},
+		},
+		"tlsSecretName": nil,
+	}
+
+	result := redactHelmValues(values)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected redacted values:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestParseHelmManifestRedactsSecrets(t *testing.T) {
+	manifest := `---
+# Source: app/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: app-credentials
+data:
+  password: aHVudGVyMg==
+stringData:
+  token: abc
+---
+---
+# Source: app/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  mode: production
+`
+
+	objects, err := parseHelmManifest(manifest)
+	if err != nil {
+		t.Fatalf("parseHelmManifest() error = %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, found %d", len(objects))
+	}
+
+	secret := redactSecretData(objects[0])
+	if !reflect.DeepEqual(secret["data"], map[string]interface{}{"password": helmRedactedValue}) ||
+		!reflect.DeepEqual(secret["stringData"], map[string]interface{}{"token": helmRedactedValue}) {
+		t.Errorf("unexpected redacted secret: %+v", secret)
+	}
+	if !reflect.DeepEqual(objects[0]["data"], map[string]interface{}{"password": "aHVudGVyMg=="}) {
+		t.Errorf("expected the original secret not to be modified: %+v", objects[0])
+	}
+
+	configMap := redactSecretData(objects[1])
+	if !reflect.DeepEqual(configMap["data"], map[string]interface{}{"mode": "production"}) {
+		t.Errorf("unexpected config map: %+v", configMap)
+	}
+}
+
+func TestGetHelmHooks(t *testing.T) {
+	started := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	hooks := []*release.Hook{
+		{
+			Name:           "db-migrate",
+			Kind:           "Job",
+			Path:           "app/templates/migrate.yaml",
+			Manifest:       "apiVersion: batch/v1\nkind: Job",
+			Events:         []release.HookEvent{release.HookPreUpgrade},
+			Weight:         -5,
+			DeletePolicies: []release.HookDeletePolicy{release.HookBeforeHookCreation},
+			LastRun:        release.HookExecution{StartedAt: helmtime.Time{Time: started}, Phase: release.HookPhaseFailed},
+		},
+	}
+
+	expected := []HelmHook{
+		{
+			Name:           "db-migrate",
+			Kind:           "Job",
+			Path:           "app/templates/migrate.yaml",
+			Events:         []string{"pre-upgrade"},
+			Weight:         -5,
+			DeletePolicies: []string{"before-hook-creation"},
+			LastRunPhase:   "Failed",
+			LastRunStarted: started,
+		},
+	}
+
+	result := getHelmHooks(hooks)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected hooks:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestGetHelmObjectDifferences(t *testing.T) {
+	expected := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   "web",
+			"labels": map[string]interface{}{"app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": float64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"securityContext": map[string]interface{}{},
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "web:1", "ports": []interface{}{map[string]interface{}{"containerPort": float64(80)}}},
+					},
+				},
+			},
+		},
+	}
+	live := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"labels":          map[string]interface{}{"app": "web", "extra": "label"},
+			"resourceVersion": "123",
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(5),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "web:2", "imagePullPolicy": "IfNotPresent", "ports": []interface{}{map[string]interface{}{"containerPort": int64(80), "protocol": "TCP"}}},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"replicas": int64(5)},
+	}
+
+	want := []HelmDriftDifference{
+		{Path: "spec.replicas", Expected: "2", Actual: "5"},
+		{Path: "spec.template.spec.containers[0].image", Expected: `"web:1"`, Actual: `"web:2"`},
+	}
+
+	result := getHelmObjectDifferences(expected, live)
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("unexpected differences:\nexpected %+v\nfound    %+v", want, result)
+	}
+}
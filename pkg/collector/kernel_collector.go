@@ -0,0 +1,313 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// dmesgTimeLayout is the format of the human-readable timestamps output by `dmesg -T`.
+const dmesgTimeLayout = "Mon Jan _2 15:04:05 2006"
+
+var (
+	dmesgLineRegex        = regexp.MustCompile(`^\[([^\]]+)\]\s?(.*)$`)
+	oomKillRegex          = regexp.MustCompile(`oom-kill:(\S+)`)
+	oomKilledProcessRegex = regexp.MustCompile(`(?:Out of memory|Memory cgroup out of memory): Killed process (\d+) \(([^)]*)\).*?anon-rss:(\d+)kB, file-rss:(\d+)kB, shmem-rss:(\d+)kB`)
+	cgroupPodUIDRegex     = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+	cgroupContainerRegex  = regexp.MustCompile(`(?:cri-containerd-|docker-|crio-)?([0-9a-f]{64})(?:\.scope)?$`)
+)
+
+// sysctlPrefixes are the sysctl keys (or key prefixes) relevant to node networking and memory management.
+var sysctlPrefixes = []string{
+	"net.core.",
+	"net.ipv4.",
+	"net.ipv6.conf.all.",
+	"net.netfilter.nf_conntrack_",
+	"net.bridge.",
+	"vm.",
+	"fs.file-max",
+	"fs.inotify.",
+	"kernel.pid_max",
+	"kernel.threads-max",
+	"kernel.panic",
+}
+
+// OOMKillEvent is a structured representation of an OOM-killer invocation logged by the kernel.
+type OOMKillEvent struct {
+	Timestamp   string `json:"timestamp"`
+	Process     string `json:"process"`
+	PID         int    `json:"pid"`
+	Constraint  string `json:"constraint"`
+	Cgroup      string `json:"cgroup"`
+	PodUID      string `json:"podUID"`
+	ContainerID string `json:"containerID"`
+	AnonRSSKB   int64  `json:"anonRssKB"`
+	FileRSSKB   int64  `json:"fileRssKB"`
+	ShmemRSSKB  int64  `json:"shmemRssKB"`
+}
+
+// KernelEventSummary holds the notable kernel events found in dmesg output.
+type KernelEventSummary struct {
+	WindowStart time.Time      `json:"windowStart"`
+	OOMKills    []OOMKillEvent `json:"oomKills"`
+	HungTasks   []string       `json:"hungTasks"`
+	SoftLockups []string       `json:"softLockups"`
+}
+
+// KernelCollector defines a Kernel Collector struct
+type KernelCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	runtimeInfo  *utils.RuntimeInfo
+	// defaultDmesgWindow is used when DIAGNOSTIC_TIME_WINDOW is not set.
+	defaultDmesgWindow time.Duration
+}
+
+// NewKernelCollector is a constructor
+func NewKernelCollector(osIdentifier utils.OSIdentifier, runtimeInfo *utils.RuntimeInfo, defaultDmesgWindow time.Duration) *KernelCollector {
+	return &KernelCollector{
+		data:               make(map[string]string),
+		osIdentifier:       osIdentifier,
+		runtimeInfo:        runtimeInfo,
+		defaultDmesgWindow: defaultDmesgWindow,
+	}
+}
+
+func (collector *KernelCollector) GetName() string {
+	return "kernel"
+}
+
+func (collector *KernelCollector) CheckSupported() error {
+	// This reads the kernel ring buffer and procfs on the host, neither of which exist on Windows.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *KernelCollector) Collect() error {
+	dmesg, err := utils.RunCommandOnHost("dmesg", "-T")
+	if err != nil {
+		return fmt.Errorf("error reading kernel ring buffer: %w", err)
+	}
+
+	dmesgWindow := collector.runtimeInfo.TimeWindow
+	if dmesgWindow <= 0 {
+		dmesgWindow = collector.defaultDmesgWindow
+	}
+
+	windowStart := time.Now().Add(-dmesgWindow)
+	dmesgLines := filterDmesgLines(strings.Split(dmesg, "\n"), windowStart)
+	collector.data["dmesg"] = strings.Join(dmesgLines, "\n")
+
+	summary := parseKernelEvents(dmesgLines)
+	summary.WindowStart = windowStart
+	summaryBytes, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("marshall kernel events to json: %w", err)
+	}
+	collector.data["kernel_events"] = string(summaryBytes)
+
+	hostFiles := []struct {
+		collectorKey string
+		path         string
+	}{
+		{collectorKey: "pressure_cpu", path: "/proc/pressure/cpu"},
+		{collectorKey: "pressure_memory", path: "/proc/pressure/memory"},
+		{collectorKey: "pressure_io", path: "/proc/pressure/io"},
+		{collectorKey: "meminfo", path: "/proc/meminfo"},
+		{collectorKey: "loadavg", path: "/proc/loadavg"},
+		{collectorKey: "os_release", path: "/etc/os-release"},
+	}
+
+	for _, hostFile := range hostFiles {
+		output, err := utils.RunCommandOnHost("cat", hostFile.path)
+		if err != nil {
+			// Pressure stall information is only available on kernels built with CONFIG_PSI.
+			output = fmt.Sprintf("Failed to read %s: %+v\n", hostFile.path, err)
+			log.Print(output)
+		}
+		collector.data[hostFile.collectorKey] = output
+	}
+
+	kernelVersion, err := utils.RunCommandOnHost("uname", "-a")
+	if err != nil {
+		kernelVersion = fmt.Sprintf("Failed to read kernel version: %+v\n", err)
+		log.Print(kernelVersion)
+	}
+	collector.data["kernel_version"] = kernelVersion
+
+	sysctl, err := utils.RunCommandOnHost("sysctl", "-a")
+	if err != nil {
+		sysctl = fmt.Sprintf("Failed to read sysctl values: %+v\n", err)
+		log.Print(sysctl)
+	} else {
+		sysctl = filterSysctlLines(sysctl)
+	}
+	collector.data["sysctl"] = sysctl
+
+	return nil
+}
+
+func (collector *KernelCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// filterDmesgLines returns the dmesg lines logged at or after windowStart. Lines without a parseable
+// timestamp are treated as continuations of the preceding line.
+func filterDmesgLines(lines []string, windowStart time.Time) []string {
+	result := []string{}
+	include := false
+	for _, line := range lines {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		if match := dmesgLineRegex.FindStringSubmatch(line); match != nil {
+			if timestamp, err := time.ParseInLocation(dmesgTimeLayout, strings.TrimSpace(match[1]), time.Local); err == nil {
+				include = !timestamp.Before(windowStart)
+			}
+		}
+
+		if include {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+// parseKernelEvents extracts OOM kills, hung tasks and soft lockups from `dmesg -T` output lines.
+// The kernel logs an OOM kill as (amongst others) an 'oom-kill:' line containing the memory cgroup,
+// followed by a 'Killed process' line with the memory usage, so these are correlated by PID.
+func parseKernelEvents(lines []string) *KernelEventSummary {
+	summary := &KernelEventSummary{
+		OOMKills:    []OOMKillEvent{},
+		HungTasks:   []string{},
+		SoftLockups: []string{},
+	}
+
+	pendingByPid := map[int]*OOMKillEvent{}
+	pendingPids := []int{}
+	for _, line := range lines {
+		timestamp, message := splitDmesgLine(line)
+
+		switch {
+		case strings.Contains(message, "oom-kill:"):
+			event := parseOOMKillLine(message)
+			event.Timestamp = timestamp
+			pendingByPid[event.PID] = event
+			pendingPids = append(pendingPids, event.PID)
+		case oomKilledProcessRegex.MatchString(message):
+			match := oomKilledProcessRegex.FindStringSubmatch(message)
+			pid, _ := strconv.Atoi(match[1])
+			event, ok := pendingByPid[pid]
+			if !ok {
+				event = &OOMKillEvent{Timestamp: timestamp, PID: pid}
+			}
+			delete(pendingByPid, pid)
+			event.Process = match[2]
+			event.AnonRSSKB, _ = strconv.ParseInt(match[3], 10, 64)
+			event.FileRSSKB, _ = strconv.ParseInt(match[4], 10, 64)
+			event.ShmemRSSKB, _ = strconv.ParseInt(match[5], 10, 64)
+			summary.OOMKills = append(summary.OOMKills, *event)
+		case strings.Contains(message, "blocked for more than"):
+			summary.HungTasks = append(summary.HungTasks, line)
+		case strings.Contains(message, "soft lockup"):
+			summary.SoftLockups = append(summary.SoftLockups, line)
+		}
+	}
+
+	// Include any OOM kills for which we didn't see a corresponding 'Killed process' line.
+	for _, pid := range pendingPids {
+		if event, ok := pendingByPid[pid]; ok {
+			summary.OOMKills = append(summary.OOMKills, *event)
+			delete(pendingByPid, pid)
+		}
+	}
+
+	return summary
+}
+
+func splitDmesgLine(line string) (string, string) {
+	match := dmesgLineRegex.FindStringSubmatch(line)
+	if match == nil {
+		return "", line
+	}
+	return strings.TrimSpace(match[1]), match[2]
+}
+
+// parseOOMKillLine parses the comma-separated key=value pairs of an 'oom-kill:' kernel message, e.g.
+// oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),...,task_memcg=/kubepods/burstable/pod<uid>/<id>,task=stress,pid=1234,uid=0
+func parseOOMKillLine(message string) *OOMKillEvent {
+	event := &OOMKillEvent{}
+	match := oomKillRegex.FindStringSubmatch(message)
+	if match == nil {
+		return event
+	}
+
+	for _, pair := range strings.Split(match[1], ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "constraint":
+			event.Constraint = parts[1]
+		case "task_memcg":
+			event.Cgroup = parts[1]
+		case "task":
+			event.Process = parts[1]
+		case "pid":
+			event.PID, _ = strconv.Atoi(parts[1])
+		}
+	}
+
+	event.PodUID, event.ContainerID = parseCgroupPath(event.Cgroup)
+	return event
+}
+
+// parseCgroupPath extracts the pod UID and container ID from a kubepods cgroup path. This handles both the
+// cgroupfs driver (/kubepods/burstable/pod<uid>/<id>) and the systemd driver
+// (/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<uid_with_underscores>.slice/cri-containerd-<id>.scope).
+func parseCgroupPath(cgroupPath string) (string, string) {
+	podUID := ""
+	if match := cgroupPodUIDRegex.FindStringSubmatch(cgroupPath); match != nil {
+		podUID = strings.ReplaceAll(match[1], "_", "-")
+	}
+
+	containerID := ""
+	segments := strings.Split(cgroupPath, "/")
+	if match := cgroupContainerRegex.FindStringSubmatch(segments[len(segments)-1]); match != nil {
+		containerID = match[1]
+	}
+
+	return podUID, containerID
+}
+
+func filterSysctlLines(sysctl string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(sysctl, "\n") {
+		for _, prefix := range sysctlPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				sb.WriteString(line)
+				sb.WriteString("\n")
+				break
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,198 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestKernelCollectorGetName(t *testing.T) {
+	const expectedName = "kernel"
+
+	c := NewKernelCollector("", nil, 0)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestKernelCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewKernelCollector(tt.osIdentifier, runtimeInfo, time.Hour)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFilterDmesgLines(t *testing.T) {
+	windowStart := time.Date(2022, time.May, 10, 12, 0, 0, 0, time.Local)
+	lines := []string{
+		"[Tue May 10 11:59:59 2022] too old",
+		"continuation of old line",
+		"[Tue May 10 12:00:00 2022] in window",
+		"continuation of new line",
+		"",
+		"[Tue May 10 13:30:00 2022] also in window",
+	}
+
+	result := filterDmesgLines(lines, windowStart)
+	expected := []string{
+		"[Tue May 10 12:00:00 2022] in window",
+		"continuation of new line",
+		"[Tue May 10 13:30:00 2022] also in window",
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("unexpected line count: expected %d, found %d: %v", len(expected), len(result), result)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("unexpected line %d: expected %s, found %s", i, expected[i], result[i])
+		}
+	}
+}
+
+func TestParseKernelEvents(t *testing.T) {
+	lines := []string{
+		"[Tue May 10 12:00:00 2022] stress invoked oom-killer: gfp_mask=0xcc0(GFP_KERNEL), order=0, oom_score_adj=939",
+		"[Tue May 10 12:00:00 2022] oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),cpuset=cri-containerd-3d6f2f4c0c3a0f1e5a5d9a4b1e2c3d4e5f60718293a4b5c6d7e8f90123456789.scope,mems_allowed=0,oom_memcg=/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice,task_memcg=/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice/cri-containerd-3d6f2f4c0c3a0f1e5a5d9a4b1e2c3d4e5f60718293a4b5c6d7e8f90123456789.scope,task=stress,pid=4321,uid=0",
+		"[Tue May 10 12:00:00 2022] Memory cgroup out of memory: Killed process 4321 (stress) total-vm:110000kB, anon-rss:102400kB, file-rss:1024kB, shmem-rss:0kB, UID:0 pgtables:300kB oom_score_adj:939",
+		"[Tue May 10 12:05:00 2022] Out of memory: Killed process 999 (java) total-vm:9000kB, anon-rss:2048kB, file-rss:0kB, shmem-rss:4kB, UID:1000 pgtables:10kB oom_score_adj:0",
+		"[Tue May 10 12:10:00 2022] INFO: task jbd2/sda1-8:312 blocked for more than 120 seconds.",
+		"[Tue May 10 12:11:00 2022] watchdog: BUG: soft lockup - CPU#1 stuck for 22s! [kworker/1:1:123]",
+	}
+
+	summary := parseKernelEvents(lines)
+
+	if len(summary.OOMKills) != 2 {
+		t.Fatalf("unexpected OOM kill count: expected 2, found %d", len(summary.OOMKills))
+	}
+
+	memcgKill := summary.OOMKills[0]
+	if memcgKill.PID != 4321 || memcgKill.Process != "stress" {
+		t.Errorf("unexpected process: found %s (%d)", memcgKill.Process, memcgKill.PID)
+	}
+	if memcgKill.PodUID != "1b4e28ba-2fa1-11d2-883f-0016d3cca427" {
+		t.Errorf("unexpected pod UID: %s", memcgKill.PodUID)
+	}
+	if memcgKill.ContainerID != "3d6f2f4c0c3a0f1e5a5d9a4b1e2c3d4e5f60718293a4b5c6d7e8f90123456789" {
+		t.Errorf("unexpected container ID: %s", memcgKill.ContainerID)
+	}
+	if memcgKill.Constraint != "CONSTRAINT_MEMCG" {
+		t.Errorf("unexpected constraint: %s", memcgKill.Constraint)
+	}
+	if memcgKill.AnonRSSKB != 102400 || memcgKill.FileRSSKB != 1024 {
+		t.Errorf("unexpected RSS values: anon %d, file %d", memcgKill.AnonRSSKB, memcgKill.FileRSSKB)
+	}
+
+	systemKill := summary.OOMKills[1]
+	if systemKill.PID != 999 || systemKill.Process != "java" || systemKill.ShmemRSSKB != 4 {
+		t.Errorf("unexpected system OOM kill: %+v", systemKill)
+	}
+
+	if len(summary.HungTasks) != 1 {
+		t.Errorf("unexpected hung task count: expected 1, found %d", len(summary.HungTasks))
+	}
+	if len(summary.SoftLockups) != 1 {
+		t.Errorf("unexpected soft lockup count: expected 1, found %d", len(summary.SoftLockups))
+	}
+}
+
+func TestParseCgroupPath(t *testing.T) {
+	tests := []struct {
+		name            string
+		cgroupPath      string
+		wantPodUID      string
+		wantContainerID string
+	}{
+		{
+			name:            "cgroupfs driver",
+			cgroupPath:      "/kubepods/burstable/pod1b4e28ba-2fa1-11d2-883f-0016d3cca427/3d6f2f4c0c3a0f1e5a5d9a4b1e2c3d4e5f60718293a4b5c6d7e8f90123456789",
+			wantPodUID:      "1b4e28ba-2fa1-11d2-883f-0016d3cca427",
+			wantContainerID: "3d6f2f4c0c3a0f1e5a5d9a4b1e2c3d4e5f60718293a4b5c6d7e8f90123456789",
+		},
+		{
+			name:            "pod-level cgroup",
+			cgroupPath:      "/kubepods.slice/kubepods-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice",
+			wantPodUID:      "1b4e28ba-2fa1-11d2-883f-0016d3cca427",
+			wantContainerID: "",
+		},
+		{
+			name:            "non-kubernetes cgroup",
+			cgroupPath:      "/system.slice/containerd.service",
+			wantPodUID:      "",
+			wantContainerID: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podUID, containerID := parseCgroupPath(tt.cgroupPath)
+			if podUID != tt.wantPodUID {
+				t.Errorf("unexpected pod UID: expected %s, found %s", tt.wantPodUID, podUID)
+			}
+			if containerID != tt.wantContainerID {
+				t.Errorf("unexpected container ID: expected %s, found %s", tt.wantContainerID, containerID)
+			}
+		})
+	}
+}
+
+func TestKernelCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "get kernel logs",
+			want:    1,
+			wantErr: true,
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+	c := NewKernelCollector(utils.Linux, runtimeInfo, time.Hour)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.Collect()
+			if (err != nil) == tt.wantErr {
+				t.Logf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
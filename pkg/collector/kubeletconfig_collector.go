@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+)
+
+// KubeletConfiguration holds the subset of the kubelet's effective configuration (as returned by
+// its /configz endpoint) that is most useful for diagnosing node-level issues.
+type KubeletConfiguration struct {
+	MaxPods                          int32             `json:"maxPods"`
+	PodsPerCore                      int32             `json:"podsPerCore"`
+	EvictionHard                     map[string]string `json:"evictionHard"`
+	EvictionSoft                     map[string]string `json:"evictionSoft"`
+	EvictionSoftGracePeriod          map[string]string `json:"evictionSoftGracePeriod"`
+	EvictionPressureTransitionPeriod string            `json:"evictionPressureTransitionPeriod"`
+	EvictionMaxPodGracePeriod        int32             `json:"evictionMaxPodGracePeriod"`
+	ImageGCHighThresholdPercent      *int32            `json:"imageGCHighThresholdPercent"`
+	ImageGCLowThresholdPercent       *int32            `json:"imageGCLowThresholdPercent"`
+	ImageMinimumGCAge                string            `json:"imageMinimumGCAge"`
+	KubeReserved                     map[string]string `json:"kubeReserved"`
+	SystemReserved                   map[string]string `json:"systemReserved"`
+	CgroupDriver                     string            `json:"cgroupDriver"`
+	ContainerRuntimeEndpoint         string            `json:"containerRuntimeEndpoint"`
+	ClusterDNS                       []string          `json:"clusterDNS"`
+	ClusterDomain                    string            `json:"clusterDomain"`
+}
+
+// KubeletConfigSummary is the structured output of the KubeletConfigCollector.
+type KubeletConfigSummary struct {
+	NodeName string                `json:"nodeName"`
+	Healthy  bool                  `json:"healthy"`
+	Health   string                `json:"health"`
+	Config   *KubeletConfiguration `json:"config"`
+}
+
+// KubeletConfigCollector defines a KubeletConfig Collector struct
+type KubeletConfigCollector struct {
+	KubeletConfig *KubeletConfiguration
+	data          map[string]string
+	kubeconfig    *restclient.Config
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewKubeletConfigCollector is a constructor
+func NewKubeletConfigCollector(config *restclient.Config, runtimeInfo *utils.RuntimeInfo) *KubeletConfigCollector {
+	return &KubeletConfigCollector{
+		data:        make(map[string]string),
+		kubeconfig:  config,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *KubeletConfigCollector) GetName() string {
+	return "kubeletconfig"
+}
+
+func (collector *KubeletConfigCollector) CheckSupported() error {
+	// The kubelet endpoints are reached through the API server's node proxy, so unlike the KubeletCmdCollector
+	// this works for Windows nodes too.
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *KubeletConfigCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	nodeName := collector.runtimeInfo.HostNodeName
+
+	configz, err := collector.getNodeProxyContent(clientset, nodeName, "configz")
+	if err != nil {
+		return fmt.Errorf("error getting kubelet configz for node %s: %w", nodeName, err)
+	}
+
+	kubeletConfig, err := parseKubeletConfigz(configz)
+	if err != nil {
+		return fmt.Errorf("error parsing kubelet configz for node %s: %w", nodeName, err)
+	}
+
+	collector.KubeletConfig = kubeletConfig
+	collector.data["kubelet_configz"] = indentJson(configz)
+
+	summary := &KubeletConfigSummary{
+		NodeName: nodeName,
+		Config:   kubeletConfig,
+	}
+
+	healthz, err := collector.getNodeProxyContent(clientset, nodeName, "healthz")
+	if err != nil {
+		summary.Health = fmt.Sprintf("Failed to get kubelet healthz for node %s: %v", nodeName, err)
+		log.Print(summary.Health)
+	} else {
+		summary.Health = strings.TrimSpace(string(healthz))
+		summary.Healthy = summary.Health == "ok"
+	}
+	collector.data["kubelet_healthz"] = summary.Health
+
+	statsSummary, err := collector.getNodeProxyContent(clientset, nodeName, "stats/summary")
+	if err != nil {
+		value := fmt.Sprintf("Failed to get kubelet stats summary for node %s: %v", nodeName, err)
+		log.Print(value)
+		collector.data["kubelet_stats_summary"] = value
+	} else {
+		collector.data["kubelet_stats_summary"] = indentJson(statsSummary)
+	}
+
+	summaryBytes, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("marshall kubelet config summary to json: %w", err)
+	}
+
+	collector.data["kubelet_config_summary"] = string(summaryBytes)
+
+	return nil
+}
+
+// getNodeProxyContent requests a path from the kubelet via the API server, equivalent to
+// `kubectl get --raw /api/v1/nodes/[nodeName]/proxy/[path]`.
+func (collector *KubeletConfigCollector) getNodeProxyContent(clientset *kubernetes.Clientset, nodeName, path string) ([]byte, error) {
+	return clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").
+		Name(nodeName).
+		SubResource("proxy").
+		Suffix(path).
+		DoRaw(context.Background())
+}
+
+func parseKubeletConfigz(configz []byte) (*KubeletConfiguration, error) {
+	// The configz endpoint wraps the configuration in a top-level 'kubeletconfig' property.
+	var response struct {
+		KubeletConfig *KubeletConfiguration `json:"kubeletconfig"`
+	}
+	if err := json.Unmarshal(configz, &response); err != nil {
+		return nil, err
+	}
+	if response.KubeletConfig == nil {
+		return nil, fmt.Errorf("no kubeletconfig property found")
+	}
+
+	return response.KubeletConfig, nil
+}
+
+// indentJson formats JSON content for readability, returning the original content if it is not valid JSON.
+func indentJson(content []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, content, "", "  "); err != nil {
+		return string(content)
+	}
+	return buf.String()
+}
+
+func (collector *KubeletConfigCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
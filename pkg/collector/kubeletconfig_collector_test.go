@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestKubeletConfigCollectorGetName(t *testing.T) {
+	const expectedName = "kubeletconfig"
+
+	c := NewKubeletConfigCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestKubeletConfigCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewKubeletConfigCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseKubeletConfigz(t *testing.T) {
+	tests := []struct {
+		name        string
+		configz     string
+		wantErr     bool
+		wantMaxPods int32
+		wantGCHigh  int32
+		wantHardMem string
+	}{
+		{
+			name:    "invalid json",
+			configz: "not json",
+			wantErr: true,
+		},
+		{
+			name:    "missing kubeletconfig",
+			configz: `{"something":{}}`,
+			wantErr: true,
+		},
+		{
+			name:        "valid configz",
+			configz:     `{"kubeletconfig":{"maxPods":30,"evictionHard":{"memory.available":"750Mi","nodefs.available":"10%"},"imageGCHighThresholdPercent":85,"imageGCLowThresholdPercent":80,"imageMinimumGCAge":"2m0s"}}`,
+			wantErr:     false,
+			wantMaxPods: 30,
+			wantGCHigh:  85,
+			wantHardMem: "750Mi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := parseKubeletConfigz([]byte(tt.configz))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseKubeletConfigz() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if config.MaxPods != tt.wantMaxPods {
+				t.Errorf("unexpected maxPods: expected %d, found %d", tt.wantMaxPods, config.MaxPods)
+			}
+			if config.ImageGCHighThresholdPercent == nil || *config.ImageGCHighThresholdPercent != tt.wantGCHigh {
+				t.Errorf("unexpected imageGCHighThresholdPercent: expected %d, found %v", tt.wantGCHigh, config.ImageGCHighThresholdPercent)
+			}
+			if config.EvictionHard["memory.available"] != tt.wantHardMem {
+				t.Errorf("unexpected evictionHard memory.available: expected %s, found %s", tt.wantHardMem, config.EvictionHard["memory.available"])
+			}
+		})
+	}
+}
+
+func TestKubeletConfigCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	nodeNames, err := getNodeNames(fixture)
+	if err != nil {
+		t.Fatalf("Error getting node names: %v", err)
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		HostNodeName:  nodeNames[0],
+		CollectorList: []string{},
+	}
+
+	c := NewKubeletConfigCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err = c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"kubelet_configz":        regexp.MustCompile(`"kubeletconfig"`),
+		"kubelet_healthz":        regexp.MustCompile(`^ok$`),
+		"kubelet_stats_summary":  regexp.MustCompile(`"node"`),
+		"kubelet_config_summary": regexp.MustCompile(`"maxPods":\d+`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+
+	testDataValue(t, c.GetData()["kubelet_config_summary"], func(raw string) {
+		var summary KubeletConfigSummary
+		if err := json.Unmarshal([]byte(raw), &summary); err != nil {
+			t.Errorf("unmarshal GetData(): %v", err)
+		}
+		if !summary.Healthy {
+			t.Errorf("expected kubelet to be healthy, found %s", summary.Health)
+		}
+	})
+}
@@ -45,15 +45,11 @@ func (collector *KubeObjectsCollector) CheckSupported() error {
 
 // Collect implements the interface method
 func (collector *KubeObjectsCollector) Collect() error {
-	// Create a discovery client for querying resource metadata
-	discoveryClient, err := discovery.NewDiscoveryClientForConfig(collector.kubeconfig)
+	_, mapper, err := getDiscoveryClientAndMapper(collector.kubeconfig)
 	if err != nil {
-		return fmt.Errorf("error creating discovery client: %w", err)
+		return err
 	}
 
-	// Create a RESTMapper to handle the mapping between GroupKind and GroupVersionResource
-	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
-
 	for _, kubernetesObject := range collector.runtimeInfo.KubernetesObjects {
 		kubernetesObjectParts := strings.Split(kubernetesObject, "/")
 		if len(kubernetesObjectParts) < 2 {
@@ -122,6 +118,20 @@ func (collector *KubeObjectsCollector) getResourcesInNamespace(mapper meta.RESTM
 	return resourceNames, nil
 }
 
+// getDiscoveryClientAndMapper creates a discovery client for querying resource metadata, and a RESTMapper backed
+// by it to handle the mapping between GroupKind and GroupVersionResource. Discovery results are cached, so the
+// same client and mapper should be reused for all the lookups in a collection.
+func getDiscoveryClientAndMapper(config *restclient.Config) (*discovery.DiscoveryClient, meta.RESTMapper, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating discovery client: %w", err)
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	return discoveryClient, mapper, nil
+}
+
 func (collector *KubeObjectsCollector) GetData() map[string]interfaces.DataValue {
 	return utils.ToDataValueMap(collector.data)
 }
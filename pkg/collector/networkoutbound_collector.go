@@ -1,36 +1,83 @@
 package collector
 
 import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
+const networkOutboundProbeTimeout = 5 * time.Second
+
 type networkOutboundType struct {
 	Type string `json:"Type"`
 	URL  string `json:"URL"`
+
+	// rootCAs are used to verify the server certificate, or the system roots if nil.
+	rootCAs *x509.CertPool
+}
+
+// NetworkOutboundCertificate describes a certificate presented by a server during the TLS handshake.
+type NetworkOutboundCertificate struct {
+	Subject         string    `json:"Subject"`
+	Issuer          string    `json:"Issuer"`
+	NotBefore       time.Time `json:"NotBefore"`
+	NotAfter        time.Time `json:"NotAfter"`
+	DaysUntilExpiry int       `json:"DaysUntilExpiry"`
 }
 
 // NetworkOutboundDatum defines a NetworkOutbound Datum
 type NetworkOutboundDatum struct {
 	TimeStamp time.Time `json:"TimeStamp"`
 	networkOutboundType
-	Status string `json:"Status"`
+	Status         string                       `json:"Status"`
+	FailedStage    string                       `json:"FailedStage,omitempty"`
+	Proxy          string                       `json:"Proxy,omitempty"`
+	ResolvedIPs    []string                     `json:"ResolvedIPs"`
+	DNSLatencyMs   float64                      `json:"DNSLatencyMs"`
+	TCPLatencyMs   float64                      `json:"TCPLatencyMs"`
+	TLSLatencyMs   float64                      `json:"TLSLatencyMs,omitempty"`
+	HTTPLatencyMs  float64                      `json:"HTTPLatencyMs,omitempty"`
+	TLSVersion     string                       `json:"TLSVersion,omitempty"`
+	Certificates   []NetworkOutboundCertificate `json:"Certificates,omitempty"`
+	HTTPStatusCode int                          `json:"HTTPStatusCode,omitempty"`
 }
 
 // NetworkOutboundCollector defines a NetworkOutbound Collector struct
 type NetworkOutboundCollector struct {
-	data map[string]string
+	data        map[string]string
+	kubeconfig  *restclient.Config
+	runtimeInfo *utils.RuntimeInfo
+	filePaths   *utils.KnownFilePaths
 }
 
 // NewNetworkOutboundCollector is a constructor
-func NewNetworkOutboundCollector() *NetworkOutboundCollector {
+func NewNetworkOutboundCollector(config *restclient.Config, runtimeInfo *utils.RuntimeInfo, filePaths *utils.KnownFilePaths) *NetworkOutboundCollector {
 	return &NetworkOutboundCollector{
-		data: make(map[string]string),
+		data:        make(map[string]string),
+		kubeconfig:  config,
+		runtimeInfo: runtimeInfo,
+		filePaths:   filePaths,
 	}
 }
 
@@ -44,58 +91,523 @@ func (collector *NetworkOutboundCollector) CheckSupported() error {
 
 // Collect implements the interface method
 func (collector *NetworkOutboundCollector) Collect() error {
+	outboundTypes := collector.getOutboundTypes()
+
+	// Each sample is stored as a line of JSON, so that the diagnoser can find status changes over the sampling window.
+	samples := map[string][]string{}
+	err := utils.RunSampled(collector.runtimeInfo.SamplingDuration, collector.runtimeInfo.SamplingInterval, func() error {
+		// Probe all targets concurrently so that unreachable targets (which wait for the timeout) don't delay the others.
+		results := make([]*NetworkOutboundDatum, len(outboundTypes))
+		var wg sync.WaitGroup
+		for i, outboundType := range outboundTypes {
+			wg.Add(1)
+			go func(i int, outboundType networkOutboundType) {
+				defer wg.Done()
+				results[i] = probeNetworkOutbound(outboundType, networkOutboundProbeTimeout, http.ProxyFromEnvironment)
+			}(i, outboundType)
+		}
+		wg.Wait()
+
+		for _, data := range results {
+			dataBytes, err := json.Marshal(data)
+			if err != nil {
+				return fmt.Errorf("marshal data: %w", err)
+			}
+
+			samples[data.Type] = append(samples[data.Type], string(dataBytes))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for outboundType, lines := range samples {
+		collector.data[outboundType] = strings.Join(lines, "\n")
+	}
+
+	return nil
+}
+
+func (collector *NetworkOutboundCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// getOutboundTypes returns the targets to probe: the configured targets (or the required egress endpoints
+// for the cloud if none are configured), plus the cluster's API server and any container registries it uses.
+func (collector *NetworkOutboundCollector) getOutboundTypes() []networkOutboundType {
 	outboundTypes := []networkOutboundType{}
-	outboundTypes = append(outboundTypes,
-		networkOutboundType{
-			Type: "Internet",
-			URL:  "google.com:80",
-		},
-	)
-	outboundTypes = append(outboundTypes,
-		networkOutboundType{
-			Type: "AKS API Server",
-			URL:  "kubernetes.default.svc.cluster.local:443",
-		},
-	)
-	outboundTypes = append(outboundTypes,
-		networkOutboundType{
-			Type: "Azure Container Registry",
-			URL:  "azurecr.io:80",
-		},
-	)
-	outboundTypes = append(outboundTypes,
-		networkOutboundType{
-			Type: "Microsoft Container Registry",
-			URL:  "mcr.microsoft.com:80",
-		},
-	)
-
-	for _, outboundType := range outboundTypes {
-		timeout := time.Duration(5 * time.Second)
-		_, err := net.DialTimeout("tcp", outboundType.URL, timeout)
-
-		status := "Connected"
+	if len(collector.runtimeInfo.NetworkOutboundTargets) > 0 {
+		for _, target := range collector.runtimeInfo.NetworkOutboundTargets {
+			outboundTypes = append(outboundTypes, networkOutboundType{Type: getTargetHost(target), URL: target})
+		}
+	} else {
+		cloud := ""
+		if collector.filePaths != nil {
+			cloud = utils.GetAzureCloud(collector.filePaths)
+		}
+		outboundTypes = append(outboundTypes, getEgressOutboundTypes(cloud)...)
+	}
+
+	if collector.kubeconfig == nil {
+		return outboundTypes
+	}
+
+	// In-cluster, the API server address is usually the 'kubernetes' service IP, which does not test egress to
+	// the control plane, so the FQDN that the kubelet connects to is probed where it can be found.
+	apiServerHost, err := collector.getAPIServerFQDNHost()
+	if err != nil {
+		log.Printf("Failed to read API server FQDN from kubelet kubeconfig, so probing %s: %v", collector.kubeconfig.Host, err)
+		apiServerHost = collector.kubeconfig.Host
+	}
+	apiServerType := networkOutboundType{
+		Type: "AKS API Server",
+		URL:  strings.TrimSuffix(apiServerHost, "/") + "/healthz",
+	}
+	if rootCAs, err := getKubeconfigRootCAs(collector.kubeconfig); err != nil {
+		log.Printf("Failed to read API server CA certificate: %v", err)
+	} else {
+		apiServerType.rootCAs = rootCAs
+	}
+	outboundTypes = append(outboundTypes, apiServerType)
+
+	registries, err := collector.getAzureContainerRegistries()
+	if err != nil {
+		log.Printf("Failed to find Azure Container Registries used by the cluster: %v", err)
+	}
+	for _, registry := range registries {
+		outboundTypes = append(outboundTypes, networkOutboundType{
+			Type: fmt.Sprintf("Azure Container Registry %s", registry),
+			URL:  fmt.Sprintf("https://%s/v2/", registry),
+		})
+	}
+
+	return outboundTypes
+}
+
+// getAPIServerFQDNHost returns the API server address from the kubelet kubeconfig on the node, which is the
+// cluster's FQDN on AKS.
+func (collector *NetworkOutboundCollector) getAPIServerFQDNHost() (string, error) {
+	if collector.filePaths == nil || len(collector.filePaths.KubeletKubeconfig) == 0 {
+		return "", fmt.Errorf("kubelet kubeconfig path not known")
+	}
+
+	// The file is mounted in the container on Windows, and otherwise read on the host.
+	content, err := os.ReadFile(collector.filePaths.KubeletKubeconfig)
+	if err != nil {
+		output, hostErr := utils.RunCommandOnHost("cat", collector.filePaths.KubeletKubeconfig)
+		if hostErr != nil {
+			return "", fmt.Errorf("read %s: %w", collector.filePaths.KubeletKubeconfig, hostErr)
+		}
+		content = []byte(output)
+	}
+
+	return getKubeconfigServer(content)
+}
+
+// getKubeconfigServer returns the server address of the current context's cluster in a kubeconfig file, or of its
+// only cluster if there is no current context.
+func getKubeconfigServer(content []byte) (string, error) {
+	config, err := clientcmd.Load(content)
+	if err != nil {
+		return "", fmt.Errorf("parse kubeconfig: %w", err)
+	}
+
+	var cluster *clientcmdapi.Cluster
+	if kubeContext, ok := config.Contexts[config.CurrentContext]; ok {
+		cluster = config.Clusters[kubeContext.Cluster]
+	} else if len(config.Clusters) == 1 {
+		for _, c := range config.Clusters {
+			cluster = c
+		}
+	}
+
+	if cluster == nil || len(cluster.Server) == 0 {
+		return "", fmt.Errorf("no server found in kubeconfig")
+	}
+	return cluster.Server, nil
+}
+
+// getAzureContainerRegistries finds the Azure Container Registries that images in the cluster are pulled from.
+func (collector *NetworkOutboundCollector) getAzureContainerRegistries() ([]string, error) {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("pod list failure: %w", err)
+	}
+
+	images := []string{}
+	for _, pod := range pods.Items {
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			images = append(images, container.Image)
+		}
+	}
+
+	return getAzureContainerRegistryHosts(images), nil
+}
+
+func getAzureContainerRegistryHosts(images []string) []string {
+	registries := map[string]bool{}
+	for _, image := range images {
+		registry := strings.SplitN(image, "/", 2)[0]
+		for _, suffix := range []string{".azurecr.io", ".azurecr.cn", ".azurecr.us"} {
+			if strings.HasSuffix(registry, suffix) {
+				registries[registry] = true
+			}
+		}
+	}
+
+	result := []string{}
+	for registry := range registries {
+		result = append(result, registry)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// getEgressOutboundTypes returns the outbound endpoints required by AKS for the specified cloud, see:
+// https://docs.microsoft.com/en-us/azure/aks/limit-egress-traffic#required-outbound-network-rules-and-fqdns-for-aks-clusters
+func getEgressOutboundTypes(cloud string) []networkOutboundType {
+	internet := networkOutboundType{Type: "Internet", URL: "http://google.com"}
+	packages := networkOutboundType{Type: "Microsoft Packages", URL: "https://packages.microsoft.com"}
+
+	switch {
+	case strings.EqualFold(cloud, utils.AzureStackCloudName):
+		// Azure Stack Hub endpoints are specific to each installation.
+		return []networkOutboundType{internet}
+	case strings.EqualFold(cloud, "AzureChinaCloud"):
+		return []networkOutboundType{
+			internet,
+			{Type: "Microsoft Container Registry", URL: "https://mcr.azure.cn"},
+			{Type: "Azure Management", URL: "https://management.chinacloudapi.cn"},
+			{Type: "Azure Active Directory", URL: "https://login.chinacloudapi.cn"},
+			packages,
+		}
+	case strings.EqualFold(cloud, "AzureUSGovernmentCloud"):
+		return []networkOutboundType{
+			internet,
+			{Type: "Microsoft Container Registry", URL: "https://mcr.microsoft.com"},
+			{Type: "Azure Management", URL: "https://management.usgovcloudapi.net"},
+			{Type: "Azure Active Directory", URL: "https://login.microsoftonline.us"},
+			packages,
+			{Type: "AKS Binaries Mirror", URL: "https://acs-mirror.azureedge.net"},
+		}
+	default:
+		return []networkOutboundType{
+			internet,
+			{Type: "Microsoft Container Registry", URL: "https://mcr.microsoft.com"},
+			{Type: "Azure Management", URL: "https://management.azure.com"},
+			{Type: "Azure Active Directory", URL: "https://login.microsoftonline.com"},
+			packages,
+			{Type: "AKS Binaries Mirror", URL: "https://acs-mirror.azureedge.net"},
+		}
+	}
+}
+
+func getKubeconfigRootCAs(config *restclient.Config) (*x509.CertPool, error) {
+	caData := config.TLSClientConfig.CAData
+	if len(caData) == 0 && len(config.TLSClientConfig.CAFile) > 0 {
+		var err error
+		if caData, err = os.ReadFile(config.TLSClientConfig.CAFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(caData) == 0 {
+		return nil, nil
+	}
+
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caData) {
+		return nil, fmt.Errorf("no certificates found in CA data")
+	}
+	return rootCAs, nil
+}
+
+// getTargetHost returns the host and port of a target, which may be a URL or a bare host:port.
+func getTargetHost(target string) string {
+	if targetURL, err := url.Parse(target); err == nil && len(targetURL.Host) > 0 {
+		return targetURL.Host
+	}
+	return target
+}
+
+// parseNetworkOutboundTarget returns the scheme, hostname and port of a target. Targets may be URLs with
+// a scheme of http, https, tls (TLS handshake only) or tcp (TCP connection only), or a bare host:port.
+func parseNetworkOutboundTarget(target string) (*url.URL, string, error) {
+	if !strings.Contains(target, "://") {
+		target = "tcp://" + target
+	}
+
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, "", err
+	}
+
+	port := targetURL.Port()
+	if len(port) == 0 {
+		switch targetURL.Scheme {
+		case "http":
+			port = "80"
+		case "https", "tls":
+			port = "443"
+		default:
+			return nil, "", fmt.Errorf("no port specified for %s target %s", targetURL.Scheme, target)
+		}
+	}
+
+	switch targetURL.Scheme {
+	case "http", "https", "tls", "tcp":
+	default:
+		return nil, "", fmt.Errorf("unsupported scheme %s in target %s", targetURL.Scheme, target)
+	}
+
+	return targetURL, port, nil
+}
+
+// probeNetworkOutbound checks connectivity to a target in stages (DNS, TCP, proxy, TLS and HTTP), recording the latency
+// of each, so that the stage at which a connection fails can be identified.
+func probeNetworkOutbound(outboundType networkOutboundType, timeout time.Duration, proxy func(*http.Request) (*url.URL, error)) *NetworkOutboundDatum {
+	datum := &NetworkOutboundDatum{
+		TimeStamp:           time.Now().Truncate(1 * time.Second),
+		networkOutboundType: outboundType,
+		ResolvedIPs:         []string{},
+	}
+
+	fail := func(stage string, err error) *NetworkOutboundDatum {
+		datum.FailedStage = stage
+		datum.Status = fmt.Sprintf("Error: %s: %v", stage, err)
+		return datum
+	}
+
+	targetURL, port, err := parseNetworkOutboundTarget(outboundType.URL)
+	if err != nil {
+		return fail("Config", err)
+	}
+	hostPort := net.JoinHostPort(targetURL.Hostname(), port)
+
+	// Only HTTP-based protocols can be sent via an HTTP proxy.
+	var proxyURL *url.URL
+	if targetURL.Scheme != "tcp" {
+		proxyTargetURL := &url.URL{Scheme: "https", Host: hostPort}
+		if targetURL.Scheme == "http" {
+			proxyTargetURL.Scheme = "http"
+		}
+		proxyURL, err = proxy(&http.Request{URL: proxyTargetURL})
 		if err != nil {
-			status = "Error: " + err.Error()
+			return fail("Proxy", err)
+		}
+		if proxyURL != nil && proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+			return fail("Proxy", fmt.Errorf("unsupported proxy scheme %s", proxyURL.Scheme))
+		}
+	}
+
+	dialHost, dialPort := targetURL.Hostname(), port
+	if proxyURL != nil {
+		datum.Proxy = proxyURL.Redacted()
+		dialHost, dialPort = proxyURL.Hostname(), proxyURL.Port()
+		if len(dialPort) == 0 {
+			dialPort = "80"
+			if proxyURL.Scheme == "https" {
+				dialPort = "443"
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	ips, err := net.DefaultResolver.LookupHost(ctx, dialHost)
+	datum.DNSLatencyMs = elapsedMilliseconds(start)
+	if err != nil {
+		return fail("DNS", err)
+	}
+	datum.ResolvedIPs = ips
+
+	start = time.Now()
+	var conn net.Conn
+	dialer := &net.Dialer{}
+	for _, ip := range ips {
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, dialPort))
+		if err == nil {
+			break
+		}
+	}
+	datum.TCPLatencyMs = elapsedMilliseconds(start)
+	if err != nil {
+		return fail("TCP", err)
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	if proxyURL != nil && proxyURL.Scheme == "https" {
+		// The connection to an HTTPS proxy is itself TLS, verified against the system roots, and the
+		// target's own TLS (if any) is tunnelled within it.
+		proxyConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+		if err := proxyConn.HandshakeContext(ctx); err != nil {
+			return fail("Proxy", err)
 		}
+		conn = proxyConn
+	}
 
-		data := &NetworkOutboundDatum{
-			TimeStamp:           time.Now().Truncate(1 * time.Second),
-			networkOutboundType: outboundType,
-			Status:              status,
+	if proxyURL != nil && targetURL.Scheme != "http" {
+		if err := connectViaProxy(conn, proxyURL, hostPort); err != nil {
+			return fail("Proxy", err)
 		}
+	}
 
-		dataBytes, err := json.Marshal(data)
+	if targetURL.Scheme == "https" || targetURL.Scheme == "tls" {
+		start = time.Now()
+		tlsConn, err := tlsHandshake(ctx, conn, targetURL.Hostname(), outboundType.rootCAs, datum)
+		datum.TLSLatencyMs = elapsedMilliseconds(start)
 		if err != nil {
-			return fmt.Errorf("marshal data: %w", err)
+			return fail("TLS", err)
 		}
+		conn = tlsConn
+	}
 
-		collector.data[outboundType.Type] = string(dataBytes)
+	if targetURL.Scheme == "http" || targetURL.Scheme == "https" {
+		start = time.Now()
+		statusCode, err := httpGet(conn, targetURL, proxyURL != nil && targetURL.Scheme == "http")
+		datum.HTTPLatencyMs = elapsedMilliseconds(start)
+		if err != nil {
+			return fail("HTTP", err)
+		}
+		datum.HTTPStatusCode = statusCode
+	}
+
+	datum.Status = "Connected"
+	return datum
+}
+
+// connectViaProxy establishes a tunnel to hostPort through an HTTP proxy using the CONNECT method.
+func connectViaProxy(conn net.Conn, proxyURL *url.URL, hostPort string) error {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: hostPort},
+		Host:   hostPort,
+		Header: http.Header{},
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy returned %s", resp.Status)
+	}
 	return nil
 }
 
-func (collector *NetworkOutboundCollector) GetData() map[string]interfaces.DataValue {
-	return utils.ToDataValueMap(collector.data)
+// tlsHandshake performs a TLS handshake and records the certificate chain presented by the server. The chain is
+// verified after the handshake, rather than during it, so that it is recorded even when it is not trusted
+// (e.g. because a firewall is intercepting TLS traffic).
+func tlsHandshake(ctx context.Context, conn net.Conn, serverName string, rootCAs *x509.CertPool, datum *NetworkOutboundDatum) (*tls.Conn, error) {
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+
+	state := tlsConn.ConnectionState()
+	datum.TLSVersion = tlsVersionName(state.Version)
+	datum.Certificates = []NetworkOutboundCertificate{}
+	for _, cert := range state.PeerCertificates {
+		datum.Certificates = append(datum.Certificates, NetworkOutboundCertificate{
+			Subject:         cert.Subject.String(),
+			Issuer:          cert.Issuer.String(),
+			NotBefore:       cert.NotBefore,
+			NotAfter:        cert.NotAfter,
+			DaysUntilExpiry: int(time.Until(cert.NotAfter).Hours() / 24),
+		})
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("no certificates presented by server")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         rootCAs,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+func httpGet(conn net.Conn, targetURL *url.URL, viaProxy bool) (int, error) {
+	requestURL := *targetURL
+	if len(requestURL.Path) == 0 {
+		requestURL.Path = "/"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, requestURL.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Close = true
+
+	// A plain HTTP request sent to a proxy uses the absolute URL as the request target.
+	if viaProxy {
+		err = req.WriteProxy(conn)
+	} else {
+		err = req.Write(conn)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("0x%04x", version)
+	}
+}
+
+func elapsedMilliseconds(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
 }
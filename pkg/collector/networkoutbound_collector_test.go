@@ -1,13 +1,25 @@
 package collector
 
 import (
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
 )
 
 func TestNetworkOutboundCollectorGetName(t *testing.T) {
 	const expectedName = "networkoutbound"
 
-	c := NewNetworkOutboundCollector()
+	c := NewNetworkOutboundCollector(nil, nil, nil)
 	actualName := c.GetName()
 	if actualName != expectedName {
 		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
@@ -15,7 +27,7 @@ func TestNetworkOutboundCollectorGetName(t *testing.T) {
 }
 
 func TestNetworkOutboundCollectorCheckSupported(t *testing.T) {
-	c := NewNetworkOutboundCollector()
+	c := NewNetworkOutboundCollector(nil, nil, nil)
 	err := c.CheckSupported()
 	if err != nil {
 		t.Errorf("error checking supported: %v", err)
@@ -35,7 +47,13 @@ func TestNetworkOutboundCollectorCollect(t *testing.T) {
 		},
 	}
 
-	c := NewNetworkOutboundCollector()
+	runtimeInfo := &utils.RuntimeInfo{
+		SamplingDuration: 2 * time.Second,
+		SamplingInterval: time.Second,
+	}
+	fixture, _ := test.GetClusterFixture()
+
+	c := NewNetworkOutboundCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo, nil)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -49,6 +67,277 @@ func TestNetworkOutboundCollectorCollect(t *testing.T) {
 			if len(raw) < tt.want {
 				t.Errorf("len(GetData()) = %v, want %v", len(raw), tt.want)
 			}
+
+			for key, value := range raw {
+				testDataValue(t, value, func(data string) {
+					if lines := strings.Split(data, "\n"); len(lines) != 3 {
+						t.Errorf("unexpected sample count for %s: expected 3, found %d", key, len(lines))
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestProbeNetworkOutbound(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(server.Certificate())
+
+	// A listener that is closed immediately gives us a port with nothing listening on it.
+	closedListener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddress := closedListener.Addr().String()
+	closedListener.Close()
+
+	noProxy := func(*http.Request) (*url.URL, error) { return nil, nil }
+
+	tests := []struct {
+		name           string
+		outboundType   networkOutboundType
+		wantStatus     string
+		wantStage      string
+		wantHTTPStatus int
+		wantCerts      bool
+	}{
+		{
+			name:           "https with trusted certificate",
+			outboundType:   networkOutboundType{Type: "test", URL: server.URL + "/healthz", rootCAs: trusted},
+			wantStatus:     "Connected",
+			wantHTTPStatus: http.StatusTeapot,
+			wantCerts:      true,
+		},
+		{
+			name:         "tls only",
+			outboundType: networkOutboundType{Type: "test", URL: strings.Replace(server.URL, "https://", "tls://", 1), rootCAs: trusted},
+			wantStatus:   "Connected",
+			wantCerts:    true,
+		},
+		{
+			name:         "untrusted certificate",
+			outboundType: networkOutboundType{Type: "test", URL: server.URL},
+			wantStage:    "TLS",
+			wantCerts:    true,
+		},
+		{
+			name:         "tcp connection refused",
+			outboundType: networkOutboundType{Type: "test", URL: closedAddress},
+			wantStage:    "TCP",
+		},
+		{
+			name:         "dns failure",
+			outboundType: networkOutboundType{Type: "test", URL: "https://periscope.invalid"},
+			wantStage:    "DNS",
+		},
+		{
+			name:         "unsupported scheme",
+			outboundType: networkOutboundType{Type: "test", URL: "ftp://example.com:21"},
+			wantStage:    "Config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			datum := probeNetworkOutbound(tt.outboundType, 5*time.Second, noProxy)
+			if len(tt.wantStatus) > 0 && datum.Status != tt.wantStatus {
+				t.Errorf("unexpected status: expected %s, found %s", tt.wantStatus, datum.Status)
+			}
+			if datum.FailedStage != tt.wantStage {
+				t.Errorf("unexpected failed stage: expected '%s', found '%s' (%s)", tt.wantStage, datum.FailedStage, datum.Status)
+			}
+			if datum.HTTPStatusCode != tt.wantHTTPStatus {
+				t.Errorf("unexpected HTTP status: expected %d, found %d", tt.wantHTTPStatus, datum.HTTPStatusCode)
+			}
+			if (len(datum.Certificates) > 0) != tt.wantCerts {
+				t.Errorf("unexpected certificates: %v", datum.Certificates)
+			}
+		})
+	}
+}
+
+func TestProbeNetworkOutboundViaProxy(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(server.Certificate())
+
+	connectRequests := 0
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		connectRequests++
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		client, _, _ := w.(http.Hijacker).Hijack()
+		client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go func() {
+			defer upstream.Close()
+			io.Copy(upstream, client)
+		}()
+		go func() {
+			defer client.Close()
+			io.Copy(client, upstream)
+		}()
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	datum := probeNetworkOutbound(
+		networkOutboundType{Type: "test", URL: server.URL, rootCAs: trusted},
+		5*time.Second,
+		func(*http.Request) (*url.URL, error) { return proxyURL, nil },
+	)
+
+	if datum.Status != "Connected" {
+		t.Errorf("unexpected status: %s", datum.Status)
+	}
+	if datum.Proxy != proxy.URL {
+		t.Errorf("unexpected proxy: expected %s, found %s", proxy.URL, datum.Proxy)
+	}
+	if connectRequests != 1 {
+		t.Errorf("unexpected CONNECT request count: expected 1, found %d", connectRequests)
+	}
+}
+
+func TestProbeNetworkOutboundViaHTTPSProxy(t *testing.T) {
+	// The proxy's certificate is not trusted by the system roots, so the handshake with the proxy should fail,
+	// which shows that the connection to it uses TLS.
+	proxy := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	datum := probeNetworkOutbound(
+		networkOutboundType{Type: "test", URL: "https://example.com"},
+		5*time.Second,
+		func(*http.Request) (*url.URL, error) { return proxyURL, nil },
+	)
+
+	if datum.FailedStage != "Proxy" {
+		t.Errorf("unexpected failed stage: expected Proxy, found %s (%s)", datum.FailedStage, datum.Status)
+	}
+	if !strings.Contains(datum.Status, "certificate") {
+		t.Errorf("expected a certificate error, found: %s", datum.Status)
+	}
+}
+
+func TestProbeNetworkOutboundUnsupportedProxy(t *testing.T) {
+	proxyURL, _ := url.Parse("socks5://127.0.0.1:1080")
+	datum := probeNetworkOutbound(
+		networkOutboundType{Type: "test", URL: "https://example.com"},
+		5*time.Second,
+		func(*http.Request) (*url.URL, error) { return proxyURL, nil },
+	)
+
+	expected := "Error: Proxy: unsupported proxy scheme socks5"
+	if datum.Status != expected {
+		t.Errorf("unexpected status: expected %s, found %s", expected, datum.Status)
+	}
+}
+
+func TestGetKubeconfigServer(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "current context",
+			content: `apiVersion: v1
+kind: Config
+clusters:
+- name: other
+  cluster:
+    server: https://other:443
+- name: localcluster
+  cluster:
+    server: https://mycluster-dns-12345678.hcp.eastus.azmk8s.io:443
+contexts:
+- name: localclustercontext
+  context:
+    cluster: localcluster
+    user: client
+current-context: localclustercontext
+`,
+			expected: "https://mycluster-dns-12345678.hcp.eastus.azmk8s.io:443",
+		},
+		{
+			name: "single cluster without current context",
+			content: `apiVersion: v1
+kind: Config
+clusters:
+- name: localcluster
+  cluster:
+    server: https://mycluster-dns-12345678.hcp.eastus.azmk8s.io:443
+`,
+			expected: "https://mycluster-dns-12345678.hcp.eastus.azmk8s.io:443",
+		},
+		{
+			name:    "no clusters",
+			content: "apiVersion: v1\nkind: Config\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			content: "not: [a kubeconfig",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := getKubeconfigServer([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getKubeconfigServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("unexpected server: expected %s, found %s", tt.expected, result)
+			}
 		})
 	}
 }
+
+func TestGetAzureContainerRegistryHosts(t *testing.T) {
+	images := []string{
+		"myacr.azurecr.io/app:v1",
+		"mcr.microsoft.com/oss/kubernetes/coredns:v1.8.6",
+		"myacr.azurecr.io/sidecar@sha256:abcdef",
+		"otheracr.azurecr.cn/app",
+		"nginx",
+	}
+	expected := []string{"myacr.azurecr.io", "otheracr.azurecr.cn"}
+
+	result := getAzureContainerRegistryHosts(images)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected registries: expected %v, found %v", expected, result)
+	}
+}
+
+func TestGetOutboundTypesFromConfig(t *testing.T) {
+	runtimeInfo := &utils.RuntimeInfo{
+		NetworkOutboundTargets: []string{"https://example.com", "tcp://10.0.0.1:22", "example.org:8080"},
+	}
+	c := NewNetworkOutboundCollector(nil, runtimeInfo, nil)
+
+	var types []string
+	for _, outboundType := range c.getOutboundTypes() {
+		types = append(types, outboundType.Type)
+	}
+	expected := []string{"example.com", "10.0.0.1:22", "example.org:8080"}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("unexpected outbound types: expected %v, found %v", expected, types)
+	}
+}
@@ -0,0 +1,282 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// NetworkPolicyPeer is a source (for ingress) or destination (for egress) allowed by a rule.
+// Selectors that match everything are shown as "<all>"; a peer without a namespace selector is in the policy's namespace.
+type NetworkPolicyPeer struct {
+	Namespace         string   `json:"namespace,omitempty"`
+	NamespaceSelector string   `json:"namespaceSelector,omitempty"`
+	PodSelector       string   `json:"podSelector,omitempty"`
+	IPBlock           string   `json:"ipBlock,omitempty"`
+	Except            []string `json:"except,omitempty"`
+}
+
+// NetworkPolicyPort is a port allowed by a rule.
+type NetworkPolicyPort struct {
+	Protocol string `json:"protocol"`
+	Port     string `json:"port"`
+	EndPort  int32  `json:"endPort,omitempty"`
+}
+
+// NetworkPolicyRule is a single ingress or egress rule of a policy.
+type NetworkPolicyRule struct {
+	Policy   string              `json:"policy"`
+	AllPeers bool                `json:"allPeers"`
+	Peers    []NetworkPolicyPeer `json:"peers"`
+	AllPorts bool                `json:"allPorts"`
+	Ports    []NetworkPolicyPort `json:"ports"`
+}
+
+// NetworkPolicyDirection is the effective policy for ingress or egress traffic of a pod.
+// Traffic is allowed if the pod is not isolated, or if any of the rules allows it.
+type NetworkPolicyDirection struct {
+	Isolated    bool                `json:"isolated"`
+	DefaultDeny bool                `json:"defaultDeny"`
+	Rules       []NetworkPolicyRule `json:"rules"`
+}
+
+// PodNetworkPolicySummary is the effective NetworkPolicy of a pod.
+type PodNetworkPolicySummary struct {
+	Namespace   string                 `json:"namespace"`
+	Name        string                 `json:"name"`
+	HostNetwork bool                   `json:"hostNetwork"`
+	Policies    []string               `json:"policies"`
+	Ingress     NetworkPolicyDirection `json:"ingress"`
+	Egress      NetworkPolicyDirection `json:"egress"`
+}
+
+// NetworkPolicyCollector defines a NetworkPolicy Collector struct
+type NetworkPolicyCollector struct {
+	data          map[string]string
+	kubeconfig    *rest.Config
+	commandRunner *utils.KubeCommandRunner
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewNetworkPolicyCollector is a constructor
+func NewNetworkPolicyCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *NetworkPolicyCollector {
+	return &NetworkPolicyCollector{
+		data:          make(map[string]string),
+		kubeconfig:    config,
+		commandRunner: utils.NewKubeCommandRunner(config),
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *NetworkPolicyCollector) GetName() string {
+	return "networkpolicy"
+}
+
+func (collector *NetworkPolicyCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *NetworkPolicyCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	gvr := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}
+
+	summaries := []PodNetworkPolicySummary{}
+	for _, namespace := range getConfiguredNamespaces(collector.runtimeInfo) {
+		keySuffix := ""
+		if len(namespace) > 0 {
+			keySuffix = "_" + namespace
+		}
+
+		value, err := collector.commandRunner.GetJsonListOutput(&gvr, namespace, &metav1.ListOptions{})
+		if err != nil {
+			value = fmt.Sprintf("Failed to collect networkpolicies in namespace '%s': %+v\n", namespace, err)
+			log.Print(value)
+		}
+		collector.data["networkpolicies"+keySuffix] = value
+
+		policies, err := clientset.NetworkingV1().NetworkPolicies(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing networkpolicies in namespace '%s': %w", namespace, err)
+		}
+
+		pods, err := clientset.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
+			FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+		})
+		if err != nil {
+			return fmt.Errorf("error listing pods on node %s in namespace '%s': %w", collector.runtimeInfo.HostNodeName, namespace, err)
+		}
+
+		for _, pod := range pods.Items {
+			summaries = append(summaries, evaluatePodNetworkPolicies(pod, policies.Items))
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+
+	summariesBytes, err := json.Marshal(summaries)
+	if err != nil {
+		return fmt.Errorf("marshall pod network policies to json: %w", err)
+	}
+	collector.data["networkpolicy_pods"] = string(summariesBytes)
+
+	return nil
+}
+
+func (collector *NetworkPolicyCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// evaluatePodNetworkPolicies works out which of the given policies select a pod, and combines their rules
+// into the traffic allowed to and from the pod.
+func evaluatePodNetworkPolicies(pod corev1.Pod, policies []networkingv1.NetworkPolicy) PodNetworkPolicySummary {
+	summary := PodNetworkPolicySummary{
+		Namespace:   pod.Namespace,
+		Name:        pod.Name,
+		HostNetwork: pod.Spec.HostNetwork,
+		Policies:    []string{},
+		Ingress:     NetworkPolicyDirection{Rules: []NetworkPolicyRule{}},
+		Egress:      NetworkPolicyDirection{Rules: []NetworkPolicyRule{}},
+	}
+
+	// NetworkPolicies do not apply to pods using the node's network namespace.
+	if pod.Spec.HostNetwork {
+		return summary
+	}
+
+	for _, policy := range policies {
+		if policy.Namespace != pod.Namespace {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil {
+			log.Printf("Failed to parse pod selector of networkpolicy %s/%s: %v", policy.Namespace, policy.Name, err)
+			continue
+		}
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+
+		summary.Policies = append(summary.Policies, policy.Name)
+
+		appliesToIngress, appliesToEgress := getNetworkPolicyTypes(policy)
+		if appliesToIngress {
+			summary.Ingress.Isolated = true
+			for _, rule := range policy.Spec.Ingress {
+				summary.Ingress.Rules = append(summary.Ingress.Rules, getNetworkPolicyRule(policy, rule.From, rule.Ports))
+			}
+		}
+		if appliesToEgress {
+			summary.Egress.Isolated = true
+			for _, rule := range policy.Spec.Egress {
+				summary.Egress.Rules = append(summary.Egress.Rules, getNetworkPolicyRule(policy, rule.To, rule.Ports))
+			}
+		}
+	}
+
+	summary.Ingress.DefaultDeny = summary.Ingress.Isolated && len(summary.Ingress.Rules) == 0
+	summary.Egress.DefaultDeny = summary.Egress.Isolated && len(summary.Egress.Rules) == 0
+
+	sort.Strings(summary.Policies)
+
+	return summary
+}
+
+// getNetworkPolicyTypes returns whether a policy applies to ingress and egress traffic. When policyTypes is not
+// specified, a policy always applies to ingress, and applies to egress only if it has egress rules.
+func getNetworkPolicyTypes(policy networkingv1.NetworkPolicy) (bool, bool) {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return true, len(policy.Spec.Egress) > 0
+	}
+
+	ingress, egress := false, false
+	for _, policyType := range policy.Spec.PolicyTypes {
+		switch policyType {
+		case networkingv1.PolicyTypeIngress:
+			ingress = true
+		case networkingv1.PolicyTypeEgress:
+			egress = true
+		}
+	}
+	return ingress, egress
+}
+
+func getNetworkPolicyRule(policy networkingv1.NetworkPolicy, peers []networkingv1.NetworkPolicyPeer, ports []networkingv1.NetworkPolicyPort) NetworkPolicyRule {
+	rule := NetworkPolicyRule{
+		Policy:   policy.Name,
+		AllPeers: len(peers) == 0,
+		Peers:    []NetworkPolicyPeer{},
+		AllPorts: len(ports) == 0,
+		Ports:    []NetworkPolicyPort{},
+	}
+
+	for _, peer := range peers {
+		result := NetworkPolicyPeer{}
+		if peer.IPBlock != nil {
+			result.IPBlock = peer.IPBlock.CIDR
+			result.Except = peer.IPBlock.Except
+		} else {
+			if peer.NamespaceSelector != nil {
+				result.NamespaceSelector = formatNetworkPolicySelector(peer.NamespaceSelector)
+			} else {
+				result.Namespace = policy.Namespace
+			}
+			// A peer with only a namespace selector allows all pods in the selected namespaces.
+			result.PodSelector = formatNetworkPolicySelector(peer.PodSelector)
+			if peer.PodSelector == nil {
+				result.PodSelector = "<all>"
+			}
+		}
+		rule.Peers = append(rule.Peers, result)
+	}
+
+	for _, port := range ports {
+		result := NetworkPolicyPort{Protocol: string(corev1.ProtocolTCP), Port: "*"}
+		if port.Protocol != nil {
+			result.Protocol = string(*port.Protocol)
+		}
+		if port.Port != nil {
+			result.Port = port.Port.String()
+		}
+		if port.EndPort != nil {
+			result.EndPort = *port.EndPort
+		}
+		rule.Ports = append(rule.Ports, result)
+	}
+
+	return rule
+}
+
+// formatNetworkPolicySelector formats a label selector, distinguishing an empty selector (which matches everything)
+// from an absent one.
+func formatNetworkPolicySelector(selector *metav1.LabelSelector) string {
+	if selector == nil {
+		return ""
+	}
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return "<all>"
+	}
+	return metav1.FormatLabelSelector(selector)
+}
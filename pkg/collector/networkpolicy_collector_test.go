@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestNetworkPolicyCollectorGetName(t *testing.T) {
+	const expectedName = "networkpolicy"
+
+	c := NewNetworkPolicyCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestNetworkPolicyCollectorCheckSupported(t *testing.T) {
+	c := NewNetworkPolicyCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestNetworkPolicyCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	nodeNames, err := getNodeNames(fixture)
+	if err != nil {
+		t.Fatalf("Error getting node names: %v", err)
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		HostNodeName:  nodeNames[0],
+		CollectorList: []string{},
+		Namespaces:    []string{"kube-system"},
+	}
+
+	c := NewNetworkPolicyCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err = c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"networkpolicies_kube-system": regexp.MustCompile(`"kind":"List"`),
+		"networkpolicy_pods":          regexp.MustCompile(`"namespace":"kube-system"`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestEvaluatePodNetworkPolicies(t *testing.T) {
+	tcp := corev1.ProtocolTCP
+	udp := corev1.ProtocolUDP
+	port80 := intstr.FromInt(80)
+	port53 := intstr.FromInt(53)
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web-1", Labels: map[string]string{"app": "web"}},
+	}
+
+	policies := []networkingv1.NetworkPolicy{
+		{
+			// Selects all pods, with no rules, so denies all ingress by default.
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "default-deny"},
+			Spec:       networkingv1.NetworkPolicySpec{PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "allow-frontend"},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Ingress: []networkingv1.NetworkPolicyIngressRule{
+					{
+						From: []networkingv1.NetworkPolicyPeer{
+							{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "frontend"}}},
+							{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ops"}}},
+							{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+						},
+						Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &port80}},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "egress-dns-only"},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{},
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+				Egress: []networkingv1.NetworkPolicyEgressRule{
+					{Ports: []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: &port53}}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "other-pods"},
+			Spec:       networkingv1.NetworkPolicySpec{PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "other-namespace"},
+			Spec:       networkingv1.NetworkPolicySpec{},
+		},
+	}
+
+	expected := PodNetworkPolicySummary{
+		Namespace: "app",
+		Name:      "web-1",
+		Policies:  []string{"allow-frontend", "default-deny", "egress-dns-only"},
+		Ingress: NetworkPolicyDirection{
+			Isolated:    true,
+			DefaultDeny: false,
+			Rules: []NetworkPolicyRule{
+				{
+					Policy:   "allow-frontend",
+					AllPeers: false,
+					Peers: []NetworkPolicyPeer{
+						{Namespace: "app", PodSelector: "role=frontend"},
+						{NamespaceSelector: "team=ops", PodSelector: "<all>"},
+						{IPBlock: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}},
+					},
+					AllPorts: false,
+					Ports:    []NetworkPolicyPort{{Protocol: "TCP", Port: "80"}},
+				},
+			},
+		},
+		Egress: NetworkPolicyDirection{
+			Isolated:    true,
+			DefaultDeny: false,
+			Rules: []NetworkPolicyRule{
+				{
+					Policy:   "egress-dns-only",
+					AllPeers: true,
+					Peers:    []NetworkPolicyPeer{},
+					AllPorts: false,
+					Ports:    []NetworkPolicyPort{{Protocol: "UDP", Port: "53"}},
+				},
+			},
+		},
+	}
+
+	result := evaluatePodNetworkPolicies(pod, policies)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected summary:\nexpected %+v\nfound    %+v", expected, result)
+	}
+
+	// With only the default-deny policy, ingress is denied and egress is unrestricted.
+	result = evaluatePodNetworkPolicies(pod, policies[:1])
+	if !result.Ingress.Isolated || !result.Ingress.DefaultDeny || result.Egress.Isolated || result.Egress.DefaultDeny {
+		t.Errorf("unexpected default deny evaluation: ingress %+v, egress %+v", result.Ingress, result.Egress)
+	}
+
+	// Policies do not apply to host network pods.
+	hostPod := pod
+	hostPod.Spec.HostNetwork = true
+	result = evaluatePodNetworkPolicies(hostPod, policies)
+	if len(result.Policies) != 0 || result.Ingress.Isolated || result.Egress.Isolated {
+		t.Errorf("unexpected evaluation for host network pod: %+v", result)
+	}
+}
@@ -0,0 +1,229 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// NodeCondition is a condition of the node, such as Ready or MemoryPressure.
+type NodeCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason"`
+	Message            string    `json:"message"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// NodeTaint is a taint on the node.
+type NodeTaint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Effect string `json:"effect"`
+}
+
+// NodeSummary is the state of the host node, as recorded in its Node object.
+type NodeSummary struct {
+	Name                    string            `json:"name"`
+	AgentPool               string            `json:"agentPool"`
+	VMSize                  string            `json:"vmSize"`
+	Zone                    string            `json:"zone"`
+	CreationTimestamp       time.Time         `json:"creationTimestamp"`
+	Unschedulable           bool              `json:"unschedulable"`
+	Conditions              []NodeCondition   `json:"conditions"`
+	Taints                  []NodeTaint       `json:"taints"`
+	Labels                  map[string]string `json:"labels"`
+	Capacity                map[string]string `json:"capacity"`
+	Allocatable             map[string]string `json:"allocatable"`
+	KubeletVersion          string            `json:"kubeletVersion"`
+	KernelVersion           string            `json:"kernelVersion"`
+	OSImage                 string            `json:"osImage"`
+	ContainerRuntimeVersion string            `json:"containerRuntimeVersion"`
+	ImageCount              int               `json:"imageCount"`
+	ImagesSizeBytes         int64             `json:"imagesSizeBytes"`
+	VolumesInUse            []string          `json:"volumesInUse"`
+	VolumesAttached         []string          `json:"volumesAttached"`
+}
+
+// NodePod is a pod scheduled on the host node.
+type NodePod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Phase     string `json:"phase"`
+	QOSClass  string `json:"qosClass"`
+	Ready     bool   `json:"ready"`
+	Restarts  int32  `json:"restarts"`
+}
+
+// NodeCollector defines a Node Collector struct
+type NodeCollector struct {
+	data        map[string]string
+	kubeconfig  *rest.Config
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewNodeCollector is a constructor
+func NewNodeCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *NodeCollector {
+	return &NodeCollector{
+		data:        make(map[string]string),
+		kubeconfig:  config,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *NodeCollector) GetName() string {
+	return "node"
+}
+
+func (collector *NodeCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *NodeCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), collector.runtimeInfo.HostNodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting node %s: %w", collector.runtimeInfo.HostNodeName, err)
+	}
+
+	nodeBytes, err := json.Marshal(getNodeSummary(node))
+	if err != nil {
+		return fmt.Errorf("marshall node summary to json: %w", err)
+	}
+	collector.data["node_summary"] = string(nodeBytes)
+
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing pods on node %s: %w", collector.runtimeInfo.HostNodeName, err)
+	}
+
+	podsBytes, err := json.Marshal(getNodePods(pods.Items))
+	if err != nil {
+		return fmt.Errorf("marshall node pods to json: %w", err)
+	}
+	collector.data["node_pods"] = string(podsBytes)
+
+	return nil
+}
+
+func (collector *NodeCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+func getNodeSummary(node *corev1.Node) NodeSummary {
+	summary := NodeSummary{
+		Name:                    node.Name,
+		AgentPool:               getFirstLabel(node.Labels, "kubernetes.azure.com/agentpool", "agentpool"),
+		VMSize:                  getFirstLabel(node.Labels, corev1.LabelInstanceTypeStable, corev1.LabelInstanceType),
+		Zone:                    getFirstLabel(node.Labels, corev1.LabelTopologyZone, corev1.LabelFailureDomainBetaZone),
+		CreationTimestamp:       node.CreationTimestamp.Time,
+		Unschedulable:           node.Spec.Unschedulable,
+		Conditions:              []NodeCondition{},
+		Taints:                  []NodeTaint{},
+		Labels:                  node.Labels,
+		Capacity:                getResourceListStrings(node.Status.Capacity),
+		Allocatable:             getResourceListStrings(node.Status.Allocatable),
+		KubeletVersion:          node.Status.NodeInfo.KubeletVersion,
+		KernelVersion:           node.Status.NodeInfo.KernelVersion,
+		OSImage:                 node.Status.NodeInfo.OSImage,
+		ContainerRuntimeVersion: node.Status.NodeInfo.ContainerRuntimeVersion,
+		ImageCount:              len(node.Status.Images),
+		VolumesInUse:            []string{},
+		VolumesAttached:         []string{},
+	}
+
+	for _, condition := range node.Status.Conditions {
+		summary.Conditions = append(summary.Conditions, NodeCondition{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime.Time,
+		})
+	}
+
+	for _, taint := range node.Spec.Taints {
+		summary.Taints = append(summary.Taints, NodeTaint{Key: taint.Key, Value: taint.Value, Effect: string(taint.Effect)})
+	}
+
+	for _, image := range node.Status.Images {
+		summary.ImagesSizeBytes += image.SizeBytes
+	}
+
+	for _, volume := range node.Status.VolumesInUse {
+		summary.VolumesInUse = append(summary.VolumesInUse, string(volume))
+	}
+
+	for _, volume := range node.Status.VolumesAttached {
+		summary.VolumesAttached = append(summary.VolumesAttached, string(volume.Name))
+	}
+
+	return summary
+}
+
+func getNodePods(pods []corev1.Pod) []NodePod {
+	result := []NodePod{}
+	for _, pod := range pods {
+		nodePod := NodePod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Phase:     string(pod.Status.Phase),
+			QOSClass:  string(pod.Status.QOSClass),
+		}
+
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady {
+				nodePod.Ready = condition.Status == corev1.ConditionTrue
+			}
+		}
+
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			nodePod.Restarts += containerStatus.RestartCount
+		}
+
+		result = append(result, nodePod)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// getFirstLabel returns the value of the first of the given labels that is set, allowing for deprecated label names.
+func getFirstLabel(labels map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := labels[key]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func getResourceListStrings(resources corev1.ResourceList) map[string]string {
+	result := map[string]string{}
+	for name, quantity := range resources {
+		result[string(name)] = quantity.String()
+	}
+	return result
+}
@@ -0,0 +1,159 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeCollectorGetName(t *testing.T) {
+	const expectedName = "node"
+
+	c := NewNodeCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestNodeCollectorCheckSupported(t *testing.T) {
+	c := NewNodeCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestNodeCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	nodeNames, err := getNodeNames(fixture)
+	if err != nil {
+		t.Fatalf("Error getting node names: %v", err)
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		HostNodeName:  nodeNames[0],
+		CollectorList: []string{},
+	}
+
+	c := NewNodeCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err = c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"node_summary": regexp.MustCompile(`"conditions":\[\{"type":`),
+		"node_pods":    regexp.MustCompile(`"namespace":"kube-system"`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestGetNodeSummary(t *testing.T) {
+	transitionTime := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "aks-nodepool1-12345678-vmss000000",
+			Labels: map[string]string{
+				"agentpool":                        "nodepool1",
+				"kubernetes.azure.com/agentpool":   "nodepool1",
+				"node.kubernetes.io/instance-type": "Standard_DS2_v2",
+				"topology.kubernetes.io/zone":      "eastus-1",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: "CriticalAddonsOnly", Value: "true", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("7116Mi")},
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1900m"), corev1.ResourceMemory: resource.MustParse("4670Mi")},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse, Reason: "KubeletHasSufficientMemory", LastTransitionTime: metav1.NewTime(transitionTime)},
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Reason: "KubeletReady", Message: "kubelet is posting ready status", LastTransitionTime: metav1.NewTime(transitionTime)},
+			},
+			NodeInfo: corev1.NodeSystemInfo{
+				KubeletVersion:          "v1.21.2",
+				KernelVersion:           "5.4.0-1059-azure",
+				OSImage:                 "Ubuntu 18.04.6 LTS",
+				ContainerRuntimeVersion: "containerd://1.4.9+azure",
+			},
+			Images: []corev1.ContainerImage{
+				{Names: []string{"mcr.microsoft.com/oss/kubernetes/pause:3.5"}, SizeBytes: 300000},
+				{Names: []string{"mcr.microsoft.com/aks/periscope:0.5"}, SizeBytes: 20000000},
+			},
+			VolumesInUse:    []corev1.UniqueVolumeName{"kubernetes.io/csi/disk.csi.azure.com^disk1"},
+			VolumesAttached: []corev1.AttachedVolume{{Name: "kubernetes.io/csi/disk.csi.azure.com^disk1", DevicePath: ""}},
+		},
+	}
+
+	summary := getNodeSummary(node)
+
+	if summary.AgentPool != "nodepool1" || summary.VMSize != "Standard_DS2_v2" || summary.Zone != "eastus-1" {
+		t.Errorf("unexpected node identity: pool %s, size %s, zone %s", summary.AgentPool, summary.VMSize, summary.Zone)
+	}
+
+	expectedConditions := []NodeCondition{
+		{Type: "MemoryPressure", Status: "False", Reason: "KubeletHasSufficientMemory", LastTransitionTime: transitionTime},
+		{Type: "Ready", Status: "True", Reason: "KubeletReady", Message: "kubelet is posting ready status", LastTransitionTime: transitionTime},
+	}
+	if !reflect.DeepEqual(summary.Conditions, expectedConditions) {
+		t.Errorf("unexpected conditions:\nexpected %+v\nfound    %+v", expectedConditions, summary.Conditions)
+	}
+
+	if !reflect.DeepEqual(summary.Taints, []NodeTaint{{Key: "CriticalAddonsOnly", Value: "true", Effect: "NoSchedule"}}) {
+		t.Errorf("unexpected taints: %+v", summary.Taints)
+	}
+
+	if summary.Capacity["cpu"] != "2" || summary.Allocatable["cpu"] != "1900m" || summary.Allocatable["memory"] != "4670Mi" {
+		t.Errorf("unexpected resources: capacity %v, allocatable %v", summary.Capacity, summary.Allocatable)
+	}
+
+	if summary.ImageCount != 2 || summary.ImagesSizeBytes != 20300000 {
+		t.Errorf("unexpected images: count %d, size %d", summary.ImageCount, summary.ImagesSizeBytes)
+	}
+
+	if !reflect.DeepEqual(summary.VolumesAttached, []string{"kubernetes.io/csi/disk.csi.azure.com^disk1"}) {
+		t.Errorf("unexpected attached volumes: %v", summary.VolumesAttached)
+	}
+}
+
+func TestGetNodePods(t *testing.T) {
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "coredns-1"},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				QOSClass:          corev1.PodQOSBurstable,
+				Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				ContainerStatuses: []corev1.ContainerStatus{{RestartCount: 2}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodPending,
+				QOSClass:   corev1.PodQOSBestEffort,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+			},
+		},
+	}
+
+	expected := []NodePod{
+		{Namespace: "default", Name: "app", Phase: "Pending", QOSClass: "BestEffort", Ready: false, Restarts: 0},
+		{Namespace: "kube-system", Name: "coredns-1", Phase: "Running", QOSClass: "Burstable", Ready: true, Restarts: 2},
+	}
+
+	result := getNodePods(pods)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected pods:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
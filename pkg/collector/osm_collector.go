@@ -7,8 +7,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net/http"
-	"net/url"
 	"regexp"
 	"strings"
 
@@ -21,8 +19,6 @@ import (
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/portforward"
-	"k8s.io/client-go/transport/spdy"
 )
 
 // OsmCollector defines an OSM Collector struct
@@ -209,7 +205,7 @@ func (collector *OsmCollector) portForwardAndRunEnvoyQueries(meshName, namespace
 	defer close(stopChan)
 
 	go func() {
-		err := collector.portForward(&portForwardParams{
+		err := portForward(collector.kubeconfig, &portForwardParams{
 			namespace: namespace,
 			podName:   podName,
 			localPort: localPort,
@@ -252,38 +248,6 @@ func (collector *OsmCollector) runEnvoyQueries(meshName, namespace, podName stri
 	}
 }
 
-type portForwardParams struct {
-	namespace string
-	podName   string
-	localPort int
-	podPort   int
-	outStream io.Writer
-	errStream io.Writer
-	readyChan chan struct{}
-	stopChan  <-chan struct{}
-}
-
-func (collector *OsmCollector) portForward(params *portForwardParams) error {
-	endpoint, err := url.Parse(collector.kubeconfig.Host)
-	if err != nil {
-		return fmt.Errorf("error parsing host URL (%s): %w", collector.kubeconfig.Host, err)
-	}
-	endpoint.Path = fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", params.namespace, params.podName)
-
-	transport, upgrader, err := spdy.RoundTripperFor(collector.kubeconfig)
-	if err != nil {
-		return err
-	}
-
-	portMap := fmt.Sprintf("%d:%d", params.localPort, params.podPort)
-	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, endpoint)
-	fw, err := portforward.New(dialer, []string{portMap}, params.stopChan, params.readyChan, params.outStream, params.errStream)
-	if err != nil {
-		return err
-	}
-	return fw.ForwardPorts()
-}
-
 // collectPodLogs collects logs of every pod in a given namespace
 func (collector *OsmCollector) collectPodLogs(clientset *kubernetes.Clientset, namespace string, meshName string) error {
 	pods, err := clientset.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{})
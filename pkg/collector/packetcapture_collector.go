@@ -0,0 +1,155 @@
+package collector
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+const (
+	defaultPacketCaptureDuration   = 30 * time.Second
+	defaultPacketCaptureInterface  = "any"
+	defaultPacketCaptureMaxBytes   = 100 * 1024 * 1024
+	defaultPacketCaptureMaxPackets = 100000
+)
+
+// packetCaptureScript runs tcpdump for a bounded time and packet count. tcpdump can rotate its output by size, but
+// not stop at a size, so the capture is streamed through head to cap its bytes (which may truncate the final packet).
+// The filter is passed as a single argument, which tcpdump accepts, so that it is never interpreted by the shell.
+const packetCaptureScript = `timeout --preserve-status -s INT "$1" tcpdump -n -U -Z root -i "$2" -c "$3" -w - ${4:+"$4"} | head -c "$5" > "$6"`
+
+// PacketCaptureCollector defines a PacketCapture Collector struct
+type PacketCaptureCollector struct {
+	data         map[string]interfaces.DataValue
+	osIdentifier utils.OSIdentifier
+	runtimeInfo  *utils.RuntimeInfo
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	// capturePath is set once a capture has been started, so that Cleanup knows there is a file to remove.
+	capturePath string
+}
+
+// NewPacketCaptureCollector is a constructor
+func NewPacketCaptureCollector(osIdentifier utils.OSIdentifier, runtimeInfo *utils.RuntimeInfo, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor) *PacketCaptureCollector {
+	return &PacketCaptureCollector{
+		data:         make(map[string]interfaces.DataValue),
+		osIdentifier: osIdentifier,
+		runtimeInfo:  runtimeInfo,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+	}
+}
+
+func (collector *PacketCaptureCollector) GetName() string {
+	return "packetcapture"
+}
+
+func (collector *PacketCaptureCollector) CheckSupported() error {
+	// This runs `tcpdump` on the host, which is not possible from a Windows container.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	// Packet captures can contain the payloads of unencrypted traffic, so are only taken when explicitly requested.
+	if !utils.Contains(collector.runtimeInfo.CollectorList, "PacketCapture") {
+		return fmt.Errorf("not included because 'PacketCapture' not in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *PacketCaptureCollector) Collect() error {
+	duration := collector.runtimeInfo.PacketCaptureDuration
+	if duration <= 0 {
+		duration = defaultPacketCaptureDuration
+	}
+
+	networkInterface := collector.runtimeInfo.PacketCaptureInterface
+	if len(networkInterface) == 0 {
+		networkInterface = defaultPacketCaptureInterface
+	}
+
+	maxBytes := collector.runtimeInfo.PacketCaptureMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultPacketCaptureMaxBytes
+	}
+
+	maxPackets := collector.runtimeInfo.PacketCaptureMaxPackets
+	if maxPackets <= 0 {
+		maxPackets = defaultPacketCaptureMaxPackets
+	}
+
+	filter := collector.runtimeInfo.PacketCaptureFilter
+
+	log.Printf("WARNING: packet capture is enabled. Capturing up to %d packets (%d bytes) on interface '%s' matching filter '%s' for %s. The capture may include the contents of unencrypted traffic.",
+		maxPackets, maxBytes, networkInterface, filter, duration)
+
+	capturePath := collector.filePaths.PacketCapture
+	collector.capturePath = capturePath
+
+	args := getPacketCaptureArgs(duration, networkInterface, filter, maxPackets, maxBytes, capturePath)
+	output, err := utils.RunCommandOnHost("sh", args...)
+	if err != nil {
+		return fmt.Errorf("error running tcpdump: %w", err)
+	}
+
+	collector.data["packet_capture_output"] = utils.NewStringDataValue(output)
+
+	size, err := collector.fileSystem.GetFileSize(capturePath)
+	if err != nil {
+		return fmt.Errorf("error getting file size for %s: %w", capturePath, err)
+	}
+	if size == 0 {
+		return fmt.Errorf("no packets were captured: %s", output)
+	}
+
+	// The capture is streamed from the node when it is exported, rather than held in memory, since it can be large.
+	collector.data["packet_capture.pcap"] = utils.NewFilePathDataValue(collector.fileSystem, capturePath, size)
+
+	return nil
+}
+
+func (collector *PacketCaptureCollector) GetData() map[string]interfaces.DataValue {
+	return collector.data
+}
+
+// Cleanup removes the capture from the node, so that raw traffic is never left behind on the host. It must only be
+// called once the data has been exported.
+func (collector *PacketCaptureCollector) Cleanup() {
+	if len(collector.capturePath) == 0 {
+		return
+	}
+
+	if _, err := utils.RunCommandOnHost("rm", "-f", collector.capturePath); err != nil {
+		log.Printf("Failed to remove packet capture %s: %v", collector.capturePath, err)
+	}
+	collector.capturePath = ""
+}
+
+// getPacketCaptureArgs returns the arguments to `sh` for running packetCaptureScript.
+func getPacketCaptureArgs(duration time.Duration, networkInterface, filter string, maxPackets, maxBytes int64, outputPath string) []string {
+	seconds := int64(duration.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return []string{
+		"-c", packetCaptureScript, "packetcapture",
+		fmt.Sprintf("%ds", seconds),
+		networkInterface,
+		strconv.FormatInt(maxPackets, 10),
+		filter,
+		strconv.FormatInt(maxBytes, 10),
+		outputPath,
+	}
+}
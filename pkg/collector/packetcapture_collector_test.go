@@ -0,0 +1,146 @@
+package collector
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestPacketCaptureCollectorGetName(t *testing.T) {
+	const expectedName = "packetcapture"
+
+	c := NewPacketCaptureCollector("", nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestPacketCaptureCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{"PacketCapture"},
+			wantErr:       true,
+		},
+		{
+			name:          "'PacketCapture' not in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'PacketCapture' and 'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"PacketCapture", "connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'PacketCapture' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"PacketCapture"},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewPacketCaptureCollector(tt.osIdentifier, runtimeInfo, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPacketCaptureCollectorCollect(t *testing.T) {
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList:         []string{"PacketCapture"},
+		PacketCaptureDuration: time.Second,
+	}
+	filePaths := &utils.KnownFilePaths{PacketCapture: filepath.Join(t.TempDir(), "capture.pcap")}
+	c := NewPacketCaptureCollector(utils.Linux, runtimeInfo, filePaths, utils.NewFileSystem())
+
+	// There is no host to run tcpdump on in the test environment, so this only checks that the failure is reported.
+	if err := c.Collect(); err == nil {
+		t.Errorf("Collect() expected an error without a host to run tcpdump on")
+	}
+	if _, ok := c.GetData()["packet_capture.pcap"]; ok {
+		t.Errorf("expected no packet_capture.pcap data after a failed capture")
+	}
+}
+
+func TestPacketCaptureScript(t *testing.T) {
+	// A fake tcpdump records its arguments and writes more data than the byte limit.
+	binDir := t.TempDir()
+	argsPath := filepath.Join(binDir, "args")
+	fakeTcpdump := "#!/bin/sh\nprintf '%s\\n' \"$@\" >> " + argsPath + "\nhead -c 4096 /dev/zero\necho '3 packets captured' >&2\n"
+	if err := os.WriteFile(filepath.Join(binDir, "tcpdump"), []byte(fakeTcpdump), 0755); err != nil {
+		t.Fatalf("error writing fake tcpdump: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   string
+		wantArgs []string
+	}{
+		{
+			name:     "with filter",
+			filter:   "host 10.0.0.1 and port 53; rm -rf /",
+			wantArgs: []string{"-n", "-U", "-Z", "root", "-i", "eth0", "-c", "50", "-w", "-", "host 10.0.0.1 and port 53; rm -rf /"},
+		},
+		{
+			name:     "without filter",
+			filter:   "",
+			wantArgs: []string{"-n", "-U", "-Z", "root", "-i", "eth0", "-c", "50", "-w", "-"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(argsPath)
+			outputPath := filepath.Join(t.TempDir(), "capture.pcap")
+
+			cmd := exec.Command("sh", getPacketCaptureArgs(5*time.Second, "eth0", tt.filter, 50, 1000, outputPath)...)
+			cmd.Env = append(os.Environ(), "PATH="+binDir+":"+os.Getenv("PATH"))
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("error running script: %v\n%s", err, output)
+			}
+
+			if !strings.Contains(string(output), "3 packets captured") {
+				t.Errorf("expected tcpdump output, found: %s", output)
+			}
+
+			capture, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatalf("error reading capture: %v", err)
+			}
+			if len(capture) != 1000 {
+				t.Errorf("unexpected capture size: expected 1000, found %d", len(capture))
+			}
+
+			args, err := os.ReadFile(argsPath)
+			if err != nil {
+				t.Fatalf("error reading tcpdump args: %v", err)
+			}
+			actualArgs := strings.Split(strings.TrimSuffix(string(args), "\n"), "\n")
+			if strings.Join(actualArgs, "|") != strings.Join(tt.wantArgs, "|") {
+				t.Errorf("unexpected tcpdump args:\nexpected %q\nfound    %q", tt.wantArgs, actualArgs)
+			}
+		})
+	}
+}
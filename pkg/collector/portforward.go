@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+type portForwardParams struct {
+	namespace string
+	podName   string
+	localPort int
+	podPort   int
+	outStream io.Writer
+	errStream io.Writer
+	readyChan chan struct{}
+	stopChan  <-chan struct{}
+}
+
+// portForward forwards a local port to a port on a pod through the API server, blocking until stopChan is closed.
+func portForward(config *rest.Config, params *portForwardParams) error {
+	fw, err := newPortForwarder(config, params)
+	if err != nil {
+		return err
+	}
+	return fw.ForwardPorts()
+}
+
+// newPortForwarder creates a port forwarder without starting it. A localPort of 0 chooses a free local port,
+// which can be read from the forwarder's GetPorts once readyChan is closed.
+func newPortForwarder(config *rest.Config, params *portForwardParams) (*portforward.PortForwarder, error) {
+	endpoint, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing host URL (%s): %w", config.Host, err)
+	}
+	endpoint.Path = fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", params.namespace, params.podName)
+
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	portMap := fmt.Sprintf("%d:%d", params.localPort, params.podPort)
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, endpoint)
+	return portforward.New(dialer, []string{portMap}, params.stopChan, params.readyChan, params.outStream, params.errStream)
+}
@@ -0,0 +1,41 @@
+package collector
+
+import (
+	"context"
+	"log"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// periscopePodLabelSelector selects the pods of the Periscope DaemonSets.
+const periscopePodLabelSelector = "app=aks-periscope"
+
+// isPrimaryNode returns whether this node should run the cluster-wide checks that only need to run once per
+// collection, rather than on every node. The primary node is the first, by name, with a running Periscope pod.
+// If the Periscope pods cannot be found, for example when running outside the DaemonSets, every node is primary.
+func isPrimaryNode(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) bool {
+	pods, err := clientset.CoreV1().Pods(runtimeInfo.PodNamespace).List(context.Background(), metav1.ListOptions{LabelSelector: periscopePodLabelSelector})
+	if err != nil {
+		log.Printf("Failed to list Periscope pods, so running cluster-wide checks on this node: %v", err)
+		return true
+	}
+
+	primaryNodeName := getPrimaryNodeName(pods.Items)
+	return len(primaryNodeName) == 0 || primaryNodeName == runtimeInfo.HostNodeName
+}
+
+func getPrimaryNodeName(pods []corev1.Pod) string {
+	primaryNodeName := ""
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning || len(pod.Spec.NodeName) == 0 {
+			continue
+		}
+		if len(primaryNodeName) == 0 || pod.Spec.NodeName < primaryNodeName {
+			primaryNodeName = pod.Spec.NodeName
+		}
+	}
+	return primaryNodeName
+}
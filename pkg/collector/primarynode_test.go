@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetPrimaryNodeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		expected string
+	}{
+		{
+			name:     "no pods",
+			pods:     []corev1.Pod{},
+			expected: "",
+		},
+		{
+			name: "first running node by name",
+			pods: []corev1.Pod{
+				{Spec: corev1.PodSpec{NodeName: "aks-nodepool1-2"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+				{Spec: corev1.PodSpec{NodeName: "aks-nodepool1-0"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+				{Spec: corev1.PodSpec{NodeName: "aks-nodepool1-1"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+				{Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+			},
+			expected: "aks-nodepool1-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := getPrimaryNodeName(tt.pods); result != tt.expected {
+				t.Errorf("unexpected primary node: expected %s, found %s", tt.expected, result)
+			}
+		})
+	}
+}
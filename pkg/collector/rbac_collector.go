@@ -0,0 +1,280 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// accessCheckResource is a resource whose permissions are checked for each ServiceAccount.
+type accessCheckResource struct {
+	group       string
+	resource    string
+	subresource string
+	namespaced  bool
+	verbs       []string
+}
+
+var (
+	readVerbs  = []string{"get", "list", "watch"}
+	writeVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+
+	// accessCheckResources are the resources most commonly involved in "forbidden" errors.
+	accessCheckResources = []accessCheckResource{
+		{group: "", resource: "pods", namespaced: true, verbs: writeVerbs},
+		{group: "", resource: "pods", subresource: "log", namespaced: true, verbs: []string{"get"}},
+		{group: "", resource: "pods", subresource: "exec", namespaced: true, verbs: []string{"create"}},
+		{group: "", resource: "services", namespaced: true, verbs: writeVerbs},
+		{group: "", resource: "endpoints", namespaced: true, verbs: readVerbs},
+		{group: "", resource: "configmaps", namespaced: true, verbs: writeVerbs},
+		{group: "", resource: "secrets", namespaced: true, verbs: writeVerbs},
+		{group: "", resource: "persistentvolumeclaims", namespaced: true, verbs: writeVerbs},
+		{group: "", resource: "serviceaccounts", namespaced: true, verbs: readVerbs},
+		{group: "", resource: "events", namespaced: true, verbs: []string{"get", "list", "watch", "create", "patch"}},
+		{group: "apps", resource: "deployments", namespaced: true, verbs: writeVerbs},
+		{group: "apps", resource: "statefulsets", namespaced: true, verbs: writeVerbs},
+		{group: "apps", resource: "daemonsets", namespaced: true, verbs: writeVerbs},
+		{group: "batch", resource: "jobs", namespaced: true, verbs: writeVerbs},
+		{group: "coordination.k8s.io", resource: "leases", namespaced: true, verbs: writeVerbs},
+		{group: "networking.k8s.io", resource: "ingresses", namespaced: true, verbs: readVerbs},
+		{group: "", resource: "namespaces", namespaced: false, verbs: readVerbs},
+		{group: "", resource: "nodes", namespaced: false, verbs: readVerbs},
+		{group: "", resource: "persistentvolumes", namespaced: false, verbs: readVerbs},
+	}
+)
+
+// AccessCheck is the result of checking whether a subject may perform a verb on a resource.
+type AccessCheck struct {
+	Group       string `json:"group"`
+	Resource    string `json:"resource"`
+	Subresource string `json:"subresource,omitempty"`
+	Namespace   string `json:"namespace"`
+	Verb        string `json:"verb"`
+	Allowed     bool   `json:"allowed"`
+	Denied      bool   `json:"denied"`
+	Reason      string `json:"reason,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ServiceAccountAccess is the effective permissions of a ServiceAccount.
+type ServiceAccountAccess struct {
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	User      string        `json:"user"`
+	Checks    []AccessCheck `json:"checks"`
+}
+
+// RBACCollector defines a RBAC Collector struct
+type RBACCollector struct {
+	data          map[string]string
+	kubeconfig    *rest.Config
+	commandRunner *utils.KubeCommandRunner
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewRBACCollector is a constructor
+func NewRBACCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *RBACCollector {
+	return &RBACCollector{
+		data:          make(map[string]string),
+		kubeconfig:    config,
+		commandRunner: utils.NewKubeCommandRunner(config),
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *RBACCollector) GetName() string {
+	return "rbac"
+}
+
+func (collector *RBACCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *RBACCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	clusterResources := []struct {
+		collectorKey string
+		schema.GroupVersionResource
+	}{
+		{collectorKey: "rbac_clusterroles", GroupVersionResource: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}},
+		{collectorKey: "rbac_clusterrolebindings", GroupVersionResource: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}},
+	}
+	for _, resource := range clusterResources {
+		value, err := collector.commandRunner.GetJsonListOutput(&resource.GroupVersionResource, "", &metav1.ListOptions{})
+		if err != nil {
+			value = fmt.Sprintf("Failed to collect %s: %+v\n", resource.Resource, err)
+			log.Print(value)
+		}
+		collector.data[resource.collectorKey] = value
+	}
+
+	namespacedResources := []struct {
+		collectorKey string
+		schema.GroupVersionResource
+	}{
+		{collectorKey: "rbac_roles", GroupVersionResource: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"}},
+		{collectorKey: "rbac_rolebindings", GroupVersionResource: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"}},
+	}
+	for _, namespace := range getConfiguredNamespaces(collector.runtimeInfo) {
+		keySuffix := ""
+		if len(namespace) > 0 {
+			keySuffix = "_" + namespace
+		}
+
+		for _, resource := range namespacedResources {
+			value, err := collector.commandRunner.GetJsonListOutput(&resource.GroupVersionResource, namespace, &metav1.ListOptions{})
+			if err != nil {
+				value = fmt.Sprintf("Failed to collect %s in namespace '%s': %+v\n", resource.Resource, namespace, err)
+				log.Print(value)
+			}
+			collector.data[resource.collectorKey+keySuffix] = value
+		}
+	}
+
+	// The access checks give the same results on every node, so they are only run once per collection.
+	if !isPrimaryNode(clientset, collector.runtimeInfo) {
+		return nil
+	}
+
+	// Periscope's own permissions explain which of the collectors' requests were forbidden. Namespaced resources
+	// are checked in the namespace Periscope runs in.
+	selfNamespace := collector.runtimeInfo.PodNamespace
+	if len(selfNamespace) == 0 {
+		selfNamespace = metav1.NamespaceDefault
+	}
+	selfChecks := []AccessCheck{}
+	for _, check := range getAccessChecks(selfNamespace) {
+		selfChecks = append(selfChecks, runSelfSubjectAccessReview(clientset, check))
+	}
+	selfBytes, err := json.Marshal(selfChecks)
+	if err != nil {
+		return fmt.Errorf("marshall self access checks to json: %w", err)
+	}
+	collector.data["rbac_self_access"] = string(selfBytes)
+
+	for _, serviceAccount := range collector.runtimeInfo.ServiceAccounts {
+		namespace, name, err := parseServiceAccountName(serviceAccount)
+		if err != nil {
+			log.Printf("Skipping ServiceAccount: %v", err)
+			continue
+		}
+
+		access := ServiceAccountAccess{
+			Namespace: namespace,
+			Name:      name,
+			User:      fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name),
+			Checks:    []AccessCheck{},
+		}
+		groups := []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"}
+		for _, check := range getAccessChecks(namespace) {
+			access.Checks = append(access.Checks, runSubjectAccessReview(clientset, access.User, groups, check))
+		}
+
+		accessBytes, err := json.Marshal(access)
+		if err != nil {
+			return fmt.Errorf("marshall access checks for ServiceAccount %s to json: %w", serviceAccount, err)
+		}
+		collector.data[fmt.Sprintf("rbac_serviceaccount_%s_%s", namespace, name)] = string(accessBytes)
+	}
+
+	return nil
+}
+
+func (collector *RBACCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// parseServiceAccountName splits a ServiceAccount configured as namespace/name.
+func parseServiceAccountName(value string) (string, string, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", fmt.Errorf("expected ServiceAccount as namespace/name, found '%s'", value)
+	}
+	return parts[0], parts[1], nil
+}
+
+// getAccessChecks lists the checks to run for a subject, with namespaced resources checked in the given namespace.
+func getAccessChecks(namespace string) []AccessCheck {
+	checks := []AccessCheck{}
+	for _, resource := range accessCheckResources {
+		checkNamespace := ""
+		if resource.namespaced {
+			checkNamespace = namespace
+		}
+		for _, verb := range resource.verbs {
+			checks = append(checks, AccessCheck{
+				Group:       resource.group,
+				Resource:    resource.resource,
+				Subresource: resource.subresource,
+				Namespace:   checkNamespace,
+				Verb:        verb,
+			})
+		}
+	}
+	return checks
+}
+
+func getResourceAttributes(check AccessCheck) *authorizationv1.ResourceAttributes {
+	return &authorizationv1.ResourceAttributes{
+		Namespace:   check.Namespace,
+		Verb:        check.Verb,
+		Group:       check.Group,
+		Resource:    check.Resource,
+		Subresource: check.Subresource,
+	}
+}
+
+func setAccessCheckStatus(check AccessCheck, status authorizationv1.SubjectAccessReviewStatus) AccessCheck {
+	check.Allowed = status.Allowed
+	check.Denied = status.Denied
+	check.Reason = status.Reason
+	check.Error = status.EvaluationError
+	return check
+}
+
+func runSelfSubjectAccessReview(clientset *kubernetes.Clientset, check AccessCheck) AccessCheck {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: getResourceAttributes(check)},
+	}
+
+	result, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(context.Background(), review, metav1.CreateOptions{})
+	if err != nil {
+		check.Error = fmt.Sprintf("error creating SelfSubjectAccessReview: %v", err)
+		return check
+	}
+
+	return setAccessCheckStatus(check, result.Status)
+}
+
+func runSubjectAccessReview(clientset *kubernetes.Clientset, user string, groups []string, check AccessCheck) AccessCheck {
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:               user,
+			Groups:             groups,
+			ResourceAttributes: getResourceAttributes(check),
+		},
+	}
+
+	result, err := clientset.AuthorizationV1().SubjectAccessReviews().Create(context.Background(), review, metav1.CreateOptions{})
+	if err != nil {
+		check.Error = fmt.Sprintf("error creating SubjectAccessReview: %v", err)
+		return check
+	}
+
+	return setAccessCheckStatus(check, result.Status)
+}
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestRBACCollectorGetName(t *testing.T) {
+	const expectedName = "rbac"
+
+	c := NewRBACCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestRBACCollectorCheckSupported(t *testing.T) {
+	c := NewRBACCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestRBACCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList:   []string{},
+		Namespaces:      []string{"kube-system"},
+		ServiceAccounts: []string{"kube-system/coredns", "invalid"},
+	}
+
+	c := NewRBACCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"rbac_clusterroles":                       regexp.MustCompile(`"kind":"List"`),
+		"rbac_clusterrolebindings":                regexp.MustCompile(`"kind":"List"`),
+		"rbac_roles_kube-system":                  regexp.MustCompile(`"kind":"List"`),
+		"rbac_rolebindings_kube-system":           regexp.MustCompile(`"kind":"List"`),
+		"rbac_self_access":                        regexp.MustCompile(`"resource":"nodes","namespace":"","verb":"list","allowed":true`),
+		"rbac_serviceaccount_kube-system_coredns": regexp.MustCompile(`"user":"system:serviceaccount:kube-system:coredns".*"resource":"secrets","namespace":"kube-system","verb":"delete","allowed":false`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestParseServiceAccountName(t *testing.T) {
+	tests := []struct {
+		value         string
+		wantNamespace string
+		wantName      string
+		wantErr       bool
+	}{
+		{value: "kube-system/coredns", wantNamespace: "kube-system", wantName: "coredns", wantErr: false},
+		{value: "coredns", wantErr: true},
+		{value: "kube-system/", wantErr: true},
+		{value: "a/b/c", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			namespace, name, err := parseServiceAccountName(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseServiceAccountName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if namespace != tt.wantNamespace || name != tt.wantName {
+				t.Errorf("unexpected result: expected %s/%s, found %s/%s", tt.wantNamespace, tt.wantName, namespace, name)
+			}
+		})
+	}
+}
+
+func TestGetAccessChecks(t *testing.T) {
+	checks := getAccessChecks("app")
+
+	expectedCount := 0
+	for _, resource := range accessCheckResources {
+		expectedCount += len(resource.verbs)
+	}
+	if len(checks) != expectedCount {
+		t.Errorf("unexpected number of checks: expected %d, found %d", expectedCount, len(checks))
+	}
+
+	expectedChecks := []AccessCheck{
+		{Group: "", Resource: "pods", Subresource: "exec", Namespace: "app", Verb: "create"},
+		{Group: "", Resource: "nodes", Namespace: "", Verb: "list"},
+	}
+	for _, expected := range expectedChecks {
+		found := false
+		for _, check := range checks {
+			if reflect.DeepEqual(check, expected) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected check %+v not found", expected)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ResourceQuotaItem is the usage of a single resource limited by a ResourceQuota.
+type ResourceQuotaItem struct {
+	Resource string  `json:"resource"`
+	Hard     string  `json:"hard"`
+	Used     string  `json:"used"`
+	Ratio    float64 `json:"ratio"`
+}
+
+// ResourceQuotaUsage is the usage of each resource limited by a ResourceQuota.
+type ResourceQuotaUsage struct {
+	Namespace string              `json:"namespace"`
+	Name      string              `json:"name"`
+	Items     []ResourceQuotaItem `json:"items"`
+}
+
+// ResourceQuotaCollector defines a ResourceQuota Collector struct
+type ResourceQuotaCollector struct {
+	data               map[string]string
+	kubeconfig         *rest.Config
+	commandRunner      *utils.KubeCommandRunner
+	runtimeInfo        *utils.RuntimeInfo
+	Quotas             []ResourceQuotaUsage
+	FailedCreateEvents []KubeEvent
+}
+
+// NewResourceQuotaCollector is a constructor
+func NewResourceQuotaCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *ResourceQuotaCollector {
+	return &ResourceQuotaCollector{
+		data:          make(map[string]string),
+		kubeconfig:    config,
+		commandRunner: utils.NewKubeCommandRunner(config),
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *ResourceQuotaCollector) GetName() string {
+	return "resourcequota"
+}
+
+func (collector *ResourceQuotaCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *ResourceQuotaCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	window := collector.runtimeInfo.TimeWindow
+	if window <= 0 {
+		window = defaultTimeWindow
+	}
+	windowStart := time.Now().Add(-window)
+
+	limitRangeGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "limitranges"}
+
+	collector.Quotas = []ResourceQuotaUsage{}
+	events := []KubeEvent{}
+	for _, namespace := range getConfiguredNamespaces(collector.runtimeInfo) {
+		keySuffix := ""
+		if len(namespace) > 0 {
+			keySuffix = "_" + namespace
+		}
+
+		value, err := collector.commandRunner.GetJsonListOutput(&limitRangeGVR, namespace, &metav1.ListOptions{})
+		if err != nil {
+			value = fmt.Sprintf("Failed to collect limitranges in namespace '%s': %+v\n", namespace, err)
+			log.Print(value)
+		}
+		collector.data["resourcequota_limitranges"+keySuffix] = value
+
+		quotas, err := clientset.CoreV1().ResourceQuotas(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing resourcequotas in namespace '%s': %w", namespace, err)
+		}
+		for _, quota := range quotas.Items {
+			collector.Quotas = append(collector.Quotas, getResourceQuotaUsage(quota))
+		}
+
+		// Controllers report quota rejections as FailedCreate events on themselves, because the pods never exist.
+		failedCreateEvents, err := clientset.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{
+			FieldSelector: "reason=FailedCreate",
+		})
+		if err != nil {
+			return fmt.Errorf("error listing FailedCreate events in namespace '%s': %w", namespace, err)
+		}
+		for i := range failedCreateEvents.Items {
+			events = append(events, fromCoreEvent(&failedCreateEvents.Items[i]))
+		}
+	}
+	collector.FailedCreateEvents = filterEventsByTime(dedupeEvents(events), windowStart)
+
+	quotasBytes, err := json.Marshal(collector.Quotas)
+	if err != nil {
+		return fmt.Errorf("marshall resourcequotas to json: %w", err)
+	}
+	collector.data["resourcequota_usage"] = string(quotasBytes)
+
+	eventsBytes, err := json.Marshal(collector.FailedCreateEvents)
+	if err != nil {
+		return fmt.Errorf("marshall FailedCreate events to json: %w", err)
+	}
+	collector.data["resourcequota_failedcreate_events"] = string(eventsBytes)
+
+	return nil
+}
+
+func (collector *ResourceQuotaCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// getResourceQuotaUsage works out the fraction of each hard limit of a quota that is used.
+func getResourceQuotaUsage(quota corev1.ResourceQuota) ResourceQuotaUsage {
+	usage := ResourceQuotaUsage{
+		Namespace: quota.Namespace,
+		Name:      quota.Name,
+		Items:     []ResourceQuotaItem{},
+	}
+
+	for name, hard := range quota.Status.Hard {
+		used := quota.Status.Used[name]
+		item := ResourceQuotaItem{
+			Resource: string(name),
+			Hard:     hard.String(),
+			Used:     used.String(),
+		}
+
+		// A zero limit forbids the resource altogether, so any use of it is over the limit.
+		if hard.IsZero() {
+			if !used.IsZero() {
+				item.Ratio = 1
+			}
+		} else {
+			item.Ratio = float64(used.MilliValue()) / float64(hard.MilliValue())
+		}
+
+		usage.Items = append(usage.Items, item)
+	}
+
+	sort.Slice(usage.Items, func(i, j int) bool { return usage.Items[i].Resource < usage.Items[j].Resource })
+
+	return usage
+}
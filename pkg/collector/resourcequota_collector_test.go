@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResourceQuotaCollectorGetName(t *testing.T) {
+	const expectedName = "resourcequota"
+
+	c := NewResourceQuotaCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestResourceQuotaCollectorCheckSupported(t *testing.T) {
+	c := NewResourceQuotaCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestResourceQuotaCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		Namespaces:    []string{"default"},
+	}
+
+	c := NewResourceQuotaCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"resourcequota_limitranges_default": regexp.MustCompile(`"kind":"List"`),
+		"resourcequota_usage":               regexp.MustCompile(`^\[.*\]$`),
+		"resourcequota_failedcreate_events": regexp.MustCompile(`^\[.*\]$`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestGetResourceQuotaUsage(t *testing.T) {
+	quota := corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "compute"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{
+				corev1.ResourceRequestsCPU:           resource.MustParse("2"),
+				corev1.ResourceRequestsMemory:        resource.MustParse("4Gi"),
+				corev1.ResourcePods:                  resource.MustParse("10"),
+				corev1.ResourceServicesLoadBalancers: resource.MustParse("0"),
+			},
+			Used: corev1.ResourceList{
+				corev1.ResourceRequestsCPU:    resource.MustParse("1900m"),
+				corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+				corev1.ResourcePods:           resource.MustParse("10"),
+			},
+		},
+	}
+
+	expected := ResourceQuotaUsage{
+		Namespace: "app",
+		Name:      "compute",
+		Items: []ResourceQuotaItem{
+			{Resource: "pods", Hard: "10", Used: "10", Ratio: 1},
+			{Resource: "requests.cpu", Hard: "2", Used: "1900m", Ratio: 0.95},
+			{Resource: "requests.memory", Hard: "4Gi", Used: "1Gi", Ratio: 0.25},
+			{Resource: "services.loadbalancers", Hard: "0", Used: "0", Ratio: 0},
+		},
+	}
+
+	result := getResourceQuotaUsage(quota)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected quota usage:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
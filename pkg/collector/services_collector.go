@@ -0,0 +1,343 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ServiceFinding is an inconsistency between Services, their pods and endpoints, and the Ingresses using them.
+type ServiceFinding struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+// ServicesCollector defines a Services Collector struct
+type ServicesCollector struct {
+	data          map[string]string
+	kubeconfig    *rest.Config
+	commandRunner *utils.KubeCommandRunner
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewServicesCollector is a constructor
+func NewServicesCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *ServicesCollector {
+	return &ServicesCollector{
+		data:          make(map[string]string),
+		kubeconfig:    config,
+		commandRunner: utils.NewKubeCommandRunner(config),
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *ServicesCollector) GetName() string {
+	return "services"
+}
+
+func (collector *ServicesCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *ServicesCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	ingressClassGVR := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingressclasses"}
+	value, err := collector.commandRunner.GetJsonListOutput(&ingressClassGVR, "", &metav1.ListOptions{})
+	if err != nil {
+		value = fmt.Sprintf("Failed to collect ingressclasses: %+v\n", err)
+		log.Print(value)
+	}
+	collector.data["services_ingressclasses"] = value
+
+	namespacedResources := []struct {
+		collectorKey string
+		schema.GroupVersionResource
+	}{
+		{collectorKey: "services", GroupVersionResource: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}},
+		{collectorKey: "services_endpointslices", GroupVersionResource: schema.GroupVersionResource{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"}},
+		{collectorKey: "services_ingresses", GroupVersionResource: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}},
+	}
+
+	namespaces := getConfiguredNamespaces(collector.runtimeInfo)
+	for _, namespace := range namespaces {
+		keySuffix := ""
+		if len(namespace) > 0 {
+			keySuffix = "_" + namespace
+		}
+
+		for _, resource := range namespacedResources {
+			value, err := collector.commandRunner.GetJsonListOutput(&resource.GroupVersionResource, namespace, &metav1.ListOptions{})
+			if err != nil {
+				value = fmt.Sprintf("Failed to collect %s in namespace '%s': %+v\n", resource.Resource, namespace, err)
+				log.Print(value)
+			}
+			collector.data[resource.collectorKey+keySuffix] = value
+		}
+	}
+
+	// The cross-check lists every pod in the configured namespaces, which is the same on every node.
+	if !isPrimaryNode(clientset, collector.runtimeInfo) {
+		return nil
+	}
+
+	findings := collector.getFindings(clientset, namespaces)
+	findingsBytes, err := json.Marshal(findings)
+	if err != nil {
+		return fmt.Errorf("marshall service findings to json: %w", err)
+	}
+	collector.data["services_findings"] = string(findingsBytes)
+
+	return nil
+}
+
+// getFindings runs the cross-check for each namespace. A list that fails is logged, and only the checks that need
+// it are skipped.
+func (collector *ServicesCollector) getFindings(clientset *kubernetes.Clientset, namespaces []string) []ServiceFinding {
+	var ingressClasses []networkingv1.IngressClass
+	ingressClassList, err := clientset.NetworkingV1().IngressClasses().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Failed to list ingressclasses: %v", err)
+	} else {
+		ingressClasses = append([]networkingv1.IngressClass{}, ingressClassList.Items...)
+	}
+
+	findings := []ServiceFinding{}
+	for _, namespace := range namespaces {
+		var services []corev1.Service
+		serviceList, err := clientset.CoreV1().Services(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			log.Printf("Failed to list services in namespace '%s': %v", namespace, err)
+		} else {
+			services = append([]corev1.Service{}, serviceList.Items...)
+		}
+
+		var pods []corev1.Pod
+		podList, err := clientset.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			log.Printf("Failed to list pods in namespace '%s': %v", namespace, err)
+		} else {
+			pods = append([]corev1.Pod{}, podList.Items...)
+		}
+
+		var endpointSlices []discoveryv1.EndpointSlice
+		endpointSliceList, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			log.Printf("Failed to list endpointslices in namespace '%s': %v", namespace, err)
+		} else {
+			endpointSlices = append([]discoveryv1.EndpointSlice{}, endpointSliceList.Items...)
+		}
+
+		var ingresses []networkingv1.Ingress
+		ingressList, err := clientset.NetworkingV1().Ingresses(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			log.Printf("Failed to list ingresses in namespace '%s': %v", namespace, err)
+		} else {
+			ingresses = append([]networkingv1.Ingress{}, ingressList.Items...)
+		}
+
+		findings = append(findings, getServiceFindings(services, pods, endpointSlices, ingresses, ingressClasses)...)
+	}
+
+	return findings
+}
+
+func (collector *ServicesCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// getServiceFindings cross-checks Services against the pods they select, their EndpointSlices, and the Ingresses
+// that route to them. Resources are matched by namespace, so they may span several namespaces. A nil list is one
+// that could not be collected, and the checks that need it are skipped.
+func getServiceFindings(services []corev1.Service, pods []corev1.Pod, endpointSlices []discoveryv1.EndpointSlice, ingresses []networkingv1.Ingress, ingressClasses []networkingv1.IngressClass) []ServiceFinding {
+	findings := []ServiceFinding{}
+
+	servicesByName := map[string]corev1.Service{}
+	for _, service := range services {
+		servicesByName[service.Namespace+"/"+service.Name] = service
+	}
+
+	readyEndpoints := map[string]int{}
+	for _, slice := range endpointSlices {
+		serviceName, ok := slice.Labels[discoveryv1.LabelServiceName]
+		if !ok {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			// A nil ready condition is interpreted as ready.
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				readyEndpoints[slice.Namespace+"/"+serviceName]++
+			}
+		}
+	}
+
+	for _, service := range services {
+		// ExternalName services are DNS aliases, with no pods or endpoints.
+		if service.Spec.Type == corev1.ServiceTypeExternalName {
+			continue
+		}
+
+		addFinding := func(reason string) {
+			findings = append(findings, ServiceFinding{Kind: "Service", Namespace: service.Namespace, Name: service.Name, Reason: reason})
+		}
+
+		// Services without selectors have their endpoints managed by something other than the endpoints controller.
+		if len(service.Spec.Selector) > 0 && pods != nil {
+			selectedPods := getServiceSelectedPods(service, pods)
+			if len(selectedPods) == 0 {
+				addFinding(fmt.Sprintf("selector %s matches no pods", labels.SelectorFromSet(service.Spec.Selector).String()))
+			} else {
+				for _, port := range service.Spec.Ports {
+					if reason := getMissingTargetPortReason(port, selectedPods); reason != "" {
+						addFinding(reason)
+					}
+				}
+			}
+		}
+
+		if endpointSlices != nil && readyEndpoints[service.Namespace+"/"+service.Name] == 0 {
+			addFinding("has no ready endpoints")
+		}
+	}
+
+	ingressClassNames := map[string]bool{}
+	for _, ingressClass := range ingressClasses {
+		ingressClassNames[ingressClass.Name] = true
+	}
+
+	for _, ingress := range ingresses {
+		addFinding := func(reason string) {
+			findings = append(findings, ServiceFinding{Kind: "Ingress", Namespace: ingress.Namespace, Name: ingress.Name, Reason: reason})
+		}
+
+		if ingress.Spec.IngressClassName != nil && ingressClasses != nil && !ingressClassNames[*ingress.Spec.IngressClassName] {
+			addFinding(fmt.Sprintf("ingressClassName %s does not exist", *ingress.Spec.IngressClassName))
+		}
+
+		backends := []*networkingv1.IngressBackend{}
+		if ingress.Spec.DefaultBackend != nil {
+			backends = append(backends, ingress.Spec.DefaultBackend)
+		}
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for i := range rule.HTTP.Paths {
+				backends = append(backends, &rule.HTTP.Paths[i].Backend)
+			}
+		}
+
+		for _, backend := range backends {
+			// Resource backends refer to objects other than Services, which are not checked.
+			if backend.Service == nil || services == nil {
+				continue
+			}
+			if reason := getMissingIngressBackendReason(backend.Service, servicesByName[ingress.Namespace+"/"+backend.Service.Name]); reason != "" {
+				addFinding(reason)
+			}
+		}
+	}
+
+	return findings
+}
+
+func getServiceSelectedPods(service corev1.Service, pods []corev1.Pod) []corev1.Pod {
+	selector := labels.SelectorFromSet(service.Spec.Selector)
+	result := []corev1.Pod{}
+	for _, pod := range pods {
+		if pod.Namespace != service.Namespace || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+// getMissingTargetPortReason checks that a Service port's targetPort exists on the selected pods. Named ports must
+// be declared by a container. Numbered ports are only checked if the pods declare any ports, because declaring
+// container ports is optional.
+func getMissingTargetPortReason(port corev1.ServicePort, pods []corev1.Pod) string {
+	targetPort := port.TargetPort
+	if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+		// An unset targetPort defaults to the port.
+		targetPort = intstr.FromInt(int(port.Port))
+	}
+
+	protocol := port.Protocol
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+
+	missingPods := []string{}
+	for _, pod := range pods {
+		declaresPorts := false
+		found := false
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				declaresPorts = true
+				containerProtocol := containerPort.Protocol
+				if containerProtocol == "" {
+					containerProtocol = corev1.ProtocolTCP
+				}
+				if containerProtocol != protocol {
+					continue
+				}
+				if (targetPort.Type == intstr.String && containerPort.Name == targetPort.StrVal) ||
+					(targetPort.Type == intstr.Int && containerPort.ContainerPort == targetPort.IntVal) {
+					found = true
+				}
+			}
+		}
+
+		if !found && (targetPort.Type == intstr.String || declaresPorts) {
+			missingPods = append(missingPods, pod.Name)
+		}
+	}
+
+	if len(missingPods) == 0 {
+		return ""
+	}
+
+	sort.Strings(missingPods)
+	return fmt.Sprintf("targetPort %s/%s of port %d does not exist on selected pods: %s", targetPort.String(), protocol, port.Port, strings.Join(missingPods, ", "))
+}
+
+func getMissingIngressBackendReason(backend *networkingv1.IngressServiceBackend, service corev1.Service) string {
+	if service.Name == "" {
+		return fmt.Sprintf("backend service %s does not exist", backend.Name)
+	}
+
+	for _, port := range service.Spec.Ports {
+		if (backend.Port.Name != "" && port.Name == backend.Port.Name) ||
+			(backend.Port.Name == "" && port.Port == backend.Port.Number) {
+			return ""
+		}
+	}
+
+	if backend.Port.Name != "" {
+		return fmt.Sprintf("backend service %s has no port named %s", backend.Name, backend.Port.Name)
+	}
+	return fmt.Sprintf("backend service %s has no port %d", backend.Name, backend.Port.Number)
+}
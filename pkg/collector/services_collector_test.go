@@ -0,0 +1,204 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestServicesCollectorGetName(t *testing.T) {
+	const expectedName = "services"
+
+	c := NewServicesCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestServicesCollectorCheckSupported(t *testing.T) {
+	c := NewServicesCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestServicesCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		Namespaces:    []string{"kube-system"},
+	}
+
+	c := NewServicesCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"services_ingressclasses":             regexp.MustCompile(`"kind":"List"`),
+		"services_kube-system":                regexp.MustCompile(`"name":"kube-dns"`),
+		"services_endpointslices_kube-system": regexp.MustCompile(`"kubernetes.io/service-name":"kube-dns"`),
+		"services_ingresses_kube-system":      regexp.MustCompile(`"kind":"List"`),
+		"services_findings":                   regexp.MustCompile(`^\[.*\]$`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestGetServiceFindings(t *testing.T) {
+	ready := true
+	notReady := false
+	className := "nginx"
+	missingClassName := "traefik"
+
+	services := []corev1.Service{
+		{
+			// Healthy: selects a pod with a named port, and has a ready endpoint.
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "web"},
+				Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromString("http")}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "orphan"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "missing"},
+				Ports:    []corev1.ServicePort{{Port: 80}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "wrong-port"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "web"},
+				Ports:    []corev1.ServicePort{{Port: 443, TargetPort: intstr.FromString("https")}, {Port: 8080, TargetPort: intstr.FromInt(8080)}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "external"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "example.com"},
+		},
+	}
+
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web-1", Labels: map[string]string{"app": "web"}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8000}}}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			// Completed pods are not selected.
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "missing-1", Labels: map[string]string{"app": "missing"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+	}
+
+	endpointSlices := []discoveryv1.EndpointSlice{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web-abc", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}},
+			Endpoints:  []discoveryv1.Endpoint{{Conditions: discoveryv1.EndpointConditions{Ready: &ready}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "wrong-port-abc", Labels: map[string]string{discoveryv1.LabelServiceName: "wrong-port"}},
+			Endpoints:  []discoveryv1.Endpoint{{Conditions: discoveryv1.EndpointConditions{Ready: &notReady}}},
+		},
+	}
+
+	ingresses := []networkingv1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "good"},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &className,
+				DefaultBackend:   &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Name: "http"}}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "bad"},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &missingClassName,
+				Rules: []networkingv1.IngressRule{
+					{
+						IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{Path: "/", Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "gone", Port: networkingv1.ServiceBackendPort{Number: 80}}}},
+								{Path: "/api", Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 8080}}}},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	ingressClasses := []networkingv1.IngressClass{{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}}}
+
+	expected := []ServiceFinding{
+		{Kind: "Service", Namespace: "app", Name: "orphan", Reason: "selector app=missing matches no pods"},
+		{Kind: "Service", Namespace: "app", Name: "orphan", Reason: "has no ready endpoints"},
+		{Kind: "Service", Namespace: "app", Name: "wrong-port", Reason: "targetPort https/TCP of port 443 does not exist on selected pods: web-1"},
+		{Kind: "Service", Namespace: "app", Name: "wrong-port", Reason: "targetPort 8080/TCP of port 8080 does not exist on selected pods: web-1"},
+		{Kind: "Service", Namespace: "app", Name: "wrong-port", Reason: "has no ready endpoints"},
+		{Kind: "Ingress", Namespace: "app", Name: "bad", Reason: "ingressClassName traefik does not exist"},
+		{Kind: "Ingress", Namespace: "app", Name: "bad", Reason: "backend service gone does not exist"},
+		{Kind: "Ingress", Namespace: "app", Name: "bad", Reason: "backend service web has no port 8080"},
+	}
+
+	result := getServiceFindings(services, pods, endpointSlices, ingresses, ingressClasses)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected findings:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestGetServiceFindingsSkipsMissingLists(t *testing.T) {
+	className := "traefik"
+
+	services := []corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "web"},
+				Ports:    []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		},
+	}
+
+	ingresses := []networkingv1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web"},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &className,
+				DefaultBackend:   &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "gone", Port: networkingv1.ServiceBackendPort{Number: 80}}},
+			},
+		},
+	}
+
+	// Without pods, endpoint slices or ingress classes, only the ingress backends can be checked.
+	expected := []ServiceFinding{
+		{Kind: "Ingress", Namespace: "app", Name: "web", Reason: "backend service gone does not exist"},
+	}
+
+	result := getServiceFindings(services, nil, nil, ingresses, nil)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected findings:\nexpected %+v\nfound    %+v", expected, result)
+	}
+
+	// Without services, the ingress backends are not checked either.
+	result = getServiceFindings(nil, nil, nil, ingresses, nil)
+	if len(result) != 0 {
+		t.Errorf("expected no findings, found %+v", result)
+	}
+}
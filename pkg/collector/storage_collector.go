@@ -0,0 +1,276 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// csiNodePluginSelectors select the Azure Disk and Azure File CSI node plugin pods.
+var csiNodePluginSelectors = []string{"app=csi-azuredisk-node", "app=csi-azurefile-node"}
+
+// StoragePendingClaim is a PersistentVolumeClaim that has not been bound to a volume.
+type StoragePendingClaim struct {
+	Namespace    string    `json:"namespace"`
+	Name         string    `json:"name"`
+	StorageClass string    `json:"storageClass"`
+	Created      time.Time `json:"created"`
+	LastEvent    string    `json:"lastEvent"`
+}
+
+// StorageAttachmentError is a VolumeAttachment that failed to attach or detach.
+type StorageAttachmentError struct {
+	Name             string `json:"name"`
+	NodeName         string `json:"nodeName"`
+	PersistentVolume string `json:"persistentVolume"`
+	Attacher         string `json:"attacher"`
+	Attached         bool   `json:"attached"`
+	AttachError      string `json:"attachError,omitempty"`
+	DetachError      string `json:"detachError,omitempty"`
+}
+
+// StorageFindings are the storage resources in an unhealthy state.
+type StorageFindings struct {
+	PendingClaims    []StoragePendingClaim    `json:"pendingClaims"`
+	AttachmentErrors []StorageAttachmentError `json:"attachmentErrors"`
+}
+
+// StorageCollector defines a Storage Collector struct
+type StorageCollector struct {
+	data          map[string]string
+	kubeconfig    *rest.Config
+	commandRunner *utils.KubeCommandRunner
+	osIdentifier  utils.OSIdentifier
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewStorageCollector is a constructor
+func NewStorageCollector(config *rest.Config, osIdentifier utils.OSIdentifier, runtimeInfo *utils.RuntimeInfo) *StorageCollector {
+	return &StorageCollector{
+		data:          make(map[string]string),
+		kubeconfig:    config,
+		commandRunner: utils.NewKubeCommandRunner(config),
+		osIdentifier:  osIdentifier,
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *StorageCollector) GetName() string {
+	return "storage"
+}
+
+func (collector *StorageCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *StorageCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	clusterResources := []struct {
+		collectorKey string
+		schema.GroupVersionResource
+	}{
+		{collectorKey: "storage_persistentvolumes", GroupVersionResource: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumes"}},
+		{collectorKey: "storage_storageclasses", GroupVersionResource: schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}},
+		{collectorKey: "storage_csidrivers", GroupVersionResource: schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "csidrivers"}},
+		{collectorKey: "storage_csinodes", GroupVersionResource: schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "csinodes"}},
+		{collectorKey: "storage_volumeattachments", GroupVersionResource: schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "volumeattachments"}},
+	}
+
+	for _, resource := range clusterResources {
+		value, err := collector.commandRunner.GetJsonListOutput(&resource.GroupVersionResource, "", &metav1.ListOptions{})
+		if err != nil {
+			value = fmt.Sprintf("Failed to collect %s: %+v\n", resource.Resource, err)
+			log.Print(value)
+		}
+		collector.data[resource.collectorKey] = value
+	}
+
+	pvcGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumeclaims"}
+	eventsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "events"}
+	pvcEventsOptions := metav1.ListOptions{FieldSelector: "involvedObject.kind=PersistentVolumeClaim"}
+
+	findings := StorageFindings{
+		PendingClaims:    []StoragePendingClaim{},
+		AttachmentErrors: []StorageAttachmentError{},
+	}
+	for _, namespace := range getConfiguredNamespaces(collector.runtimeInfo) {
+		keySuffix := ""
+		if len(namespace) > 0 {
+			keySuffix = "_" + namespace
+		}
+
+		value, err := collector.commandRunner.GetJsonListOutput(&pvcGVR, namespace, &metav1.ListOptions{})
+		if err != nil {
+			value = fmt.Sprintf("Failed to collect persistentvolumeclaims in namespace '%s': %+v\n", namespace, err)
+			log.Print(value)
+		}
+		collector.data["storage_persistentvolumeclaims"+keySuffix] = value
+
+		value, err = collector.commandRunner.GetJsonListOutput(&eventsGVR, namespace, &pvcEventsOptions)
+		if err != nil {
+			value = fmt.Sprintf("Failed to collect persistentvolumeclaim events in namespace '%s': %+v\n", namespace, err)
+			log.Print(value)
+		}
+		collector.data["storage_persistentvolumeclaim_events"+keySuffix] = value
+
+		pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing persistentvolumeclaims in namespace '%s': %w", namespace, err)
+		}
+		pvcEvents, err := clientset.CoreV1().Events(namespace).List(context.Background(), pvcEventsOptions)
+		if err != nil {
+			return fmt.Errorf("error listing persistentvolumeclaim events in namespace '%s': %w", namespace, err)
+		}
+		findings.PendingClaims = append(findings.PendingClaims, getPendingClaims(pvcs.Items, pvcEvents.Items)...)
+	}
+
+	attachments, err := clientset.StorageV1().VolumeAttachments().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing volumeattachments: %w", err)
+	}
+	findings.AttachmentErrors = getAttachmentErrors(attachments.Items)
+
+	findingsBytes, err := json.Marshal(findings)
+	if err != nil {
+		return fmt.Errorf("marshall storage findings to json: %w", err)
+	}
+	collector.data["storage_findings"] = string(findingsBytes)
+
+	collector.collectNodePluginLogs(clientset)
+
+	// The plugin registration directories are only accessible on a Linux host, and not from connected clusters.
+	if collector.osIdentifier == utils.Linux && !utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		output, err := utils.RunCommandOnHost("find", "/var/lib/kubelet/plugins_registry", "/var/lib/kubelet/plugins", "-maxdepth", "2", "-ls")
+		if err != nil {
+			output = fmt.Sprintf("Failed to list kubelet plugin registrations: %+v\n", err)
+			log.Print(output)
+		}
+		collector.data["storage_kubelet_plugins"] = output
+	}
+
+	return nil
+}
+
+func (collector *StorageCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+// collectNodePluginLogs collects the last 100 lines of logs of each container in the CSI node plugin pods on the host node.
+func (collector *StorageCollector) collectNodePluginLogs(clientset *kubernetes.Clientset) {
+	for _, selector := range csiNodePluginSelectors {
+		pods, err := clientset.CoreV1().Pods("kube-system").List(context.Background(), metav1.ListOptions{
+			LabelSelector: selector,
+			FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+		})
+		if err != nil {
+			log.Printf("Failed to list CSI node plugin pods with selector %s: %v", selector, err)
+			continue
+		}
+
+		for _, pod := range pods.Items {
+			for _, container := range pod.Spec.Containers {
+				logs, err := getPodContainerLogs(pod.Namespace, pod.Name, container.Name, clientset)
+				if err != nil {
+					logs = fmt.Sprintf("Failed to collect logs for container %s in pod %s: %+v\n", container.Name, pod.Name, err)
+					log.Print(logs)
+				}
+				collector.data[fmt.Sprintf("storage/%s_%s_logs", pod.Name, container.Name)] = logs
+			}
+		}
+	}
+}
+
+// getPendingClaims returns the PersistentVolumeClaims that are not yet bound, with the most recent event for each.
+func getPendingClaims(pvcs []corev1.PersistentVolumeClaim, events []corev1.Event) []StoragePendingClaim {
+	latestEvents := map[string]*corev1.Event{}
+	for i := range events {
+		event := &events[i]
+		key := event.InvolvedObject.Namespace + "/" + event.InvolvedObject.Name
+		if latest, ok := latestEvents[key]; !ok || getEventTime(event).After(getEventTime(latest)) {
+			latestEvents[key] = event
+		}
+	}
+
+	result := []StoragePendingClaim{}
+	for _, pvc := range pvcs {
+		if pvc.Status.Phase != corev1.ClaimPending {
+			continue
+		}
+
+		claim := StoragePendingClaim{
+			Namespace: pvc.Namespace,
+			Name:      pvc.Name,
+			Created:   pvc.CreationTimestamp.Time,
+		}
+		if pvc.Spec.StorageClassName != nil {
+			claim.StorageClass = *pvc.Spec.StorageClassName
+		}
+		if event, ok := latestEvents[pvc.Namespace+"/"+pvc.Name]; ok {
+			claim.LastEvent = fmt.Sprintf("%s: %s", event.Reason, strings.TrimSpace(event.Message))
+		}
+		result = append(result, claim)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// getAttachmentErrors returns the VolumeAttachments that report an error attaching or detaching their volume.
+func getAttachmentErrors(attachments []storagev1.VolumeAttachment) []StorageAttachmentError {
+	result := []StorageAttachmentError{}
+	for _, attachment := range attachments {
+		if attachment.Status.AttachError == nil && attachment.Status.DetachError == nil {
+			continue
+		}
+
+		attachmentError := StorageAttachmentError{
+			Name:     attachment.Name,
+			NodeName: attachment.Spec.NodeName,
+			Attacher: attachment.Spec.Attacher,
+			Attached: attachment.Status.Attached,
+		}
+		if attachment.Spec.Source.PersistentVolumeName != nil {
+			attachmentError.PersistentVolume = *attachment.Spec.Source.PersistentVolumeName
+		}
+		if attachment.Status.AttachError != nil {
+			attachmentError.AttachError = attachment.Status.AttachError.Message
+		}
+		if attachment.Status.DetachError != nil {
+			attachmentError.DetachError = attachment.Status.DetachError.Message
+		}
+		result = append(result, attachmentError)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result
+}
+
+// getEventTime returns when a core/v1 event was last seen.
+func getEventTime(event *corev1.Event) time.Time {
+	return fromCoreEvent(event).LastTimestamp
+}
@@ -0,0 +1,153 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStorageCollectorGetName(t *testing.T) {
+	const expectedName = "storage"
+
+	c := NewStorageCollector(nil, "", nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestStorageCollectorCheckSupported(t *testing.T) {
+	c := NewStorageCollector(nil, utils.Linux, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestStorageCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	nodeNames, err := getNodeNames(fixture)
+	if err != nil {
+		t.Fatalf("Error getting node names: %v", err)
+	}
+
+	// The kubelet plugin directories are not accessible from the test environment, so this runs as a connected cluster.
+	runtimeInfo := &utils.RuntimeInfo{
+		HostNodeName:  nodeNames[0],
+		CollectorList: []string{"connectedCluster"},
+		Namespaces:    []string{"default"},
+	}
+
+	c := NewStorageCollector(fixture.PeriscopeAccess.ClientConfig, utils.Linux, runtimeInfo)
+	err = c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"storage_persistentvolumes":                    regexp.MustCompile(`"kind":"List"`),
+		"storage_storageclasses":                       regexp.MustCompile(`"kind":"List"`),
+		"storage_csidrivers":                           regexp.MustCompile(`"kind":"List"`),
+		"storage_csinodes":                             regexp.MustCompile(`"kind":"List"`),
+		"storage_volumeattachments":                    regexp.MustCompile(`"kind":"List"`),
+		"storage_persistentvolumeclaims_default":       regexp.MustCompile(`"kind":"List"`),
+		"storage_persistentvolumeclaim_events_default": regexp.MustCompile(`"kind":"List"`),
+		"storage_findings":                             regexp.MustCompile(`"pendingClaims":\[.*\],"attachmentErrors":\[.*\]`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestGetPendingClaims(t *testing.T) {
+	created := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	storageClass := "managed-csi"
+
+	pvcs := []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bound"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending", CreationTimestamp: metav1.NewTime(created)},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+	}
+
+	events := []corev1.Event{
+		{
+			InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "default", Name: "pending"},
+			Reason:         "Provisioning",
+			Message:        "External provisioner is provisioning volume",
+			LastTimestamp:  metav1.NewTime(created.Add(time.Second)),
+		},
+		{
+			InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "default", Name: "pending"},
+			Reason:         "ProvisioningFailed",
+			Message:        "failed to provision volume: quota exceeded\n",
+			LastTimestamp:  metav1.NewTime(created.Add(time.Minute)),
+		},
+	}
+
+	expected := []StoragePendingClaim{
+		{
+			Namespace:    "default",
+			Name:         "pending",
+			StorageClass: "managed-csi",
+			Created:      created,
+			LastEvent:    "ProvisioningFailed: failed to provision volume: quota exceeded",
+		},
+	}
+
+	result := getPendingClaims(pvcs, events)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected pending claims:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestGetAttachmentErrors(t *testing.T) {
+	pvName := "pvc-1234"
+	attachments := []storagev1.VolumeAttachment{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "csi-ok"},
+			Spec:       storagev1.VolumeAttachmentSpec{Attacher: "disk.csi.azure.com", NodeName: "node-1"},
+			Status:     storagev1.VolumeAttachmentStatus{Attached: true},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "csi-failed"},
+			Spec: storagev1.VolumeAttachmentSpec{
+				Attacher: "disk.csi.azure.com",
+				NodeName: "node-2",
+				Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+			},
+			Status: storagev1.VolumeAttachmentStatus{
+				Attached:    false,
+				AttachError: &storagev1.VolumeError{Message: "disk is attached to another node"},
+			},
+		},
+	}
+
+	expected := []StorageAttachmentError{
+		{
+			Name:             "csi-failed",
+			NodeName:         "node-2",
+			PersistentVolume: "pvc-1234",
+			Attacher:         "disk.csi.azure.com",
+			Attached:         false,
+			AttachError:      "disk is attached to another node",
+		},
+	}
+
+	result := getAttachmentErrors(attachments)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected attachment errors:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"github.com/Azure/aks-periscope/pkg/utils"
 )
 
+// defaultSystemServices are the journald units collected when none are configured. AKS nodes
+// have used containerd as their container runtime since Kubernetes 1.19.
+var defaultSystemServices = []string{"containerd", "kubelet"}
+
 // SystemLogsCollector defines a SystemLogs Collector struct
 type SystemLogsCollector struct {
 	data         map[string]string
@@ -45,7 +49,10 @@ func (collector *SystemLogsCollector) CheckSupported() error {
 
 // Collect implements the interface method
 func (collector *SystemLogsCollector) Collect() error {
-	systemServices := []string{"docker", "kubelet"}
+	systemServices := collector.runtimeInfo.SystemLogsServices
+	if len(systemServices) == 0 {
+		systemServices = defaultSystemServices
+	}
 
 	for _, systemService := range systemServices {
 		output, err := utils.RunCommandOnHost("journalctl", "-u", systemService)
@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
@@ -18,18 +22,55 @@ type SystemPerfCollector struct {
 	data        map[string]string
 	kubeconfig  *restclient.Config
 	runtimeInfo *utils.RuntimeInfo
+	Samples     []SystemPerfSample
 }
 
+// SystemPerfSample holds the node and container metrics taken at a point in time.
+type SystemPerfSample struct {
+	TimeStamp time.Time     `json:"timestamp"`
+	Nodes     []NodeMetrics `json:"nodes"`
+	Pods      []PodMetrics  `json:"pods"`
+}
+
+// NodeMetrics holds the resource usage of a node, with CPU in millicores and memory in bytes.
+// Utilization percentages are relative to the node's allocatable resources.
 type NodeMetrics struct {
-	NodeName    string `json:"name"`
-	CPUUsage    int64  `json:"cpuUsage"`
-	MemoryUsage int64  `json:"memoryUsage"`
+	NodeName          string  `json:"name"`
+	CPUUsage          int64   `json:"cpuUsage"`
+	MemoryUsage       int64   `json:"memoryUsage"`
+	CPUCapacity       int64   `json:"cpuCapacity"`
+	MemoryCapacity    int64   `json:"memoryCapacity"`
+	CPUAllocatable    int64   `json:"cpuAllocatable"`
+	MemoryAllocatable int64   `json:"memoryAllocatable"`
+	CPUUtilization    float64 `json:"cpuUtilization"`
+	MemoryUtilization float64 `json:"memoryUtilization"`
+	OverAllocatable   bool    `json:"overAllocatable"`
 }
 
+// PodMetrics holds the resource usage of a single container, with CPU in millicores and memory in bytes.
+// Utilization percentages are relative to the container's requests and limits, and are zero where
+// the corresponding request or limit is not set.
 type PodMetrics struct {
-	ContainerName string `json:"name"`
-	CPUUsage      int64  `json:"cpuUsage"`
-	MemoryUsage   int64  `json:"memoryUsage"`
+	Namespace                string  `json:"namespace"`
+	PodName                  string  `json:"pod"`
+	ContainerName            string  `json:"name"`
+	NodeName                 string  `json:"node"`
+	CPUUsage                 int64   `json:"cpuUsage"`
+	MemoryUsage              int64   `json:"memoryUsage"`
+	CPURequest               int64   `json:"cpuRequest"`
+	CPULimit                 int64   `json:"cpuLimit"`
+	MemoryRequest            int64   `json:"memoryRequest"`
+	MemoryLimit              int64   `json:"memoryLimit"`
+	CPURequestUtilization    float64 `json:"cpuRequestUtilization"`
+	CPULimitUtilization      float64 `json:"cpuLimitUtilization"`
+	MemoryRequestUtilization float64 `json:"memoryRequestUtilization"`
+	MemoryLimitUtilization   float64 `json:"memoryLimitUtilization"`
+	NodeCPUAllocatable       int64   `json:"nodeCpuAllocatable"`
+	NodeMemoryAllocatable    int64   `json:"nodeMemoryAllocatable"`
+	OverCPURequest           bool    `json:"overCpuRequest"`
+	OverMemoryRequest        bool    `json:"overMemoryRequest"`
+	AtCPULimit               bool    `json:"atCpuLimit"`
+	AtMemoryLimit            bool    `json:"atMemoryLimit"`
 }
 
 // NewSystemPerfCollector is a constructor
@@ -60,18 +101,109 @@ func (collector *SystemPerfCollector) Collect() error {
 		return fmt.Errorf("metrics for config error: %w", err)
 	}
 
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	samplesJson := []string{}
+	err = utils.RunSampled(collector.runtimeInfo.SamplingDuration, collector.runtimeInfo.SamplingInterval, func() error {
+		sample, err := getSystemPerfSample(metric, clientset)
+		if err != nil {
+			return err
+		}
+
+		sampleBytes, err := json.Marshal(sample)
+		if err != nil {
+			return fmt.Errorf("marshall system perf sample to json: %w", err)
+		}
+
+		collector.Samples = append(collector.Samples, *sample)
+		samplesJson = append(samplesJson, string(sampleBytes))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The 'nodes' and 'pods' values hold the most recent sample, and the full set is stored as JSON lines.
+	latest := collector.Samples[len(collector.Samples)-1]
+	jsonNodeResult, err := json.Marshal(latest.Nodes)
+	if err != nil {
+		return fmt.Errorf("marshall node metrics to json: %w", err)
+	}
+
+	collector.data["nodes"] = string(jsonNodeResult)
+
+	jsonPodResult, err := json.Marshal(latest.Pods)
+	if err != nil {
+		return fmt.Errorf("marshall pod metrics to json: %w", err)
+	}
+
+	collector.data["pods"] = string(jsonPodResult)
+	collector.data["systemperf_samples"] = strings.Join(samplesJson, "\n")
+
+	return nil
+}
+
+func (collector *SystemPerfCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+func getSystemPerfSample(metric metrics.Interface, clientset kubernetes.Interface) (*SystemPerfSample, error) {
+	timestamp := time.Now().Truncate(time.Second)
+
 	nodeMetrics, err := metric.MetricsV1beta1().NodeMetricses().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		return fmt.Errorf("node metrics error: %w", err)
+		return nil, fmt.Errorf("node metrics error: %w", err)
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("node list error: %w", err)
+	}
+
+	noderesult, err := getNodeMetrics(nodeMetrics.Items, nodes.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	podMetrics, err := metric.MetricsV1beta1().PodMetricses(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("pod metrics failure: %w", err)
+	}
+
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("pod list failure: %w", err)
+	}
+
+	podresult, err := getPodMetrics(podMetrics.Items, pods.Items, nodes.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SystemPerfSample{
+		TimeStamp: timestamp,
+		Nodes:     noderesult,
+		Pods:      podresult,
+	}, nil
+}
+
+// getNodeMetrics combines the metrics-server usage for each node with the node's capacity and allocatable resources.
+func getNodeMetrics(nodeMetrics []metricsv1beta1.NodeMetrics, nodes []corev1.Node) ([]NodeMetrics, error) {
+	nodesByName := map[string]*corev1.Node{}
+	for i := range nodes {
+		nodesByName[nodes[i].Name] = &nodes[i]
 	}
 
 	noderesult := make([]NodeMetrics, 0)
 
-	for _, nodeMetric := range nodeMetrics.Items {
+	for _, nodeMetric := range nodeMetrics {
 		cpuQuantity := nodeMetric.Usage.Cpu().MilliValue()
 		memQuantity, ok := nodeMetric.Usage.Memory().AsInt64()
 		if !ok {
-			return err
+			return nil, fmt.Errorf("usage memory failure for node %s", nodeMetric.Name)
 		}
 
 		nm := NodeMetrics{
@@ -80,50 +212,95 @@ func (collector *SystemPerfCollector) Collect() error {
 			MemoryUsage: memQuantity,
 		}
 
+		if node, ok := nodesByName[nodeMetric.Name]; ok {
+			nm.CPUCapacity = node.Status.Capacity.Cpu().MilliValue()
+			nm.MemoryCapacity = node.Status.Capacity.Memory().Value()
+			nm.CPUAllocatable = node.Status.Allocatable.Cpu().MilliValue()
+			nm.MemoryAllocatable = node.Status.Allocatable.Memory().Value()
+			nm.CPUUtilization = utilization(nm.CPUUsage, nm.CPUAllocatable)
+			nm.MemoryUtilization = utilization(nm.MemoryUsage, nm.MemoryAllocatable)
+			nm.OverAllocatable = nm.CPUUtilization > 100 || nm.MemoryUtilization > 100
+		}
+
 		noderesult = append(noderesult, nm)
 	}
-	jsonNodeResult, err := json.Marshal(noderesult)
-	if err != nil {
-		return fmt.Errorf("marshall node metrics to json: %w", err)
-	}
 
-	collector.data["nodes"] = string(jsonNodeResult)
+	return noderesult, nil
+}
 
-	podMetrics, err := metric.MetricsV1beta1().PodMetricses(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("pod metrics failure: %w", err)
+// getPodMetrics combines the metrics-server usage for each container with its pod's identity and node,
+// and the container's resource requests and limits.
+func getPodMetrics(podMetrics []metricsv1beta1.PodMetrics, pods []corev1.Pod, nodes []corev1.Node) ([]PodMetrics, error) {
+	podsByKey := map[string]*corev1.Pod{}
+	for i := range pods {
+		podsByKey[pods[i].Namespace+"/"+pods[i].Name] = &pods[i]
+	}
+
+	nodesByName := map[string]*corev1.Node{}
+	for i := range nodes {
+		nodesByName[nodes[i].Name] = &nodes[i]
 	}
 
 	podresult := make([]PodMetrics, 0)
 
-	for _, podMetric := range podMetrics.Items {
-		podContainers := podMetric.Containers
-		for _, container := range podContainers {
+	for _, podMetric := range podMetrics {
+		pod := podsByKey[podMetric.Namespace+"/"+podMetric.Name]
+
+		for _, container := range podMetric.Containers {
 			cpuQuantity := container.Usage.Cpu().MilliValue()
 			memQuantity, ok := container.Usage.Memory().AsInt64()
 			if !ok {
-				return fmt.Errorf("usage memory failure: %w", err)
+				return nil, fmt.Errorf("usage memory failure for container %s in pod %s/%s", container.Name, podMetric.Namespace, podMetric.Name)
 			}
 
 			pm := PodMetrics{
+				Namespace:     podMetric.Namespace,
+				PodName:       podMetric.Name,
 				ContainerName: container.Name,
 				CPUUsage:      cpuQuantity,
 				MemoryUsage:   memQuantity,
 			}
 
+			if pod != nil {
+				pm.NodeName = pod.Spec.NodeName
+				for _, spec := range pod.Spec.Containers {
+					if spec.Name != container.Name {
+						continue
+					}
+					pm.CPURequest = spec.Resources.Requests.Cpu().MilliValue()
+					pm.CPULimit = spec.Resources.Limits.Cpu().MilliValue()
+					pm.MemoryRequest = spec.Resources.Requests.Memory().Value()
+					pm.MemoryLimit = spec.Resources.Limits.Memory().Value()
+				}
+			}
+
+			if node, ok := nodesByName[pm.NodeName]; ok {
+				pm.NodeCPUAllocatable = node.Status.Allocatable.Cpu().MilliValue()
+				pm.NodeMemoryAllocatable = node.Status.Allocatable.Memory().Value()
+			}
+
+			pm.CPURequestUtilization = utilization(pm.CPUUsage, pm.CPURequest)
+			pm.CPULimitUtilization = utilization(pm.CPUUsage, pm.CPULimit)
+			pm.MemoryRequestUtilization = utilization(pm.MemoryUsage, pm.MemoryRequest)
+			pm.MemoryLimitUtilization = utilization(pm.MemoryUsage, pm.MemoryLimit)
+			pm.OverCPURequest = pm.CPURequest > 0 && pm.CPUUsage > pm.CPURequest
+			pm.OverMemoryRequest = pm.MemoryRequest > 0 && pm.MemoryUsage > pm.MemoryRequest
+			// CPU usage is throttled at the limit, and memory usage beyond the limit results in an OOM kill,
+			// so usage at (or very close to) the limit is the best indication of either.
+			pm.AtCPULimit = pm.CPULimit > 0 && pm.CPULimitUtilization >= 95
+			pm.AtMemoryLimit = pm.MemoryLimit > 0 && pm.MemoryLimitUtilization >= 95
+
 			podresult = append(podresult, pm)
 		}
 	}
-	jsonPodResult, err := json.Marshal(podresult)
-	if err != nil {
-		return fmt.Errorf("marshall pod metrics to json: %w", err)
-	}
-
-	collector.data["pods"] = string(jsonPodResult)
 
-	return nil
+	return podresult, nil
 }
 
-func (collector *SystemPerfCollector) GetData() map[string]interfaces.DataValue {
-	return utils.ToDataValueMap(collector.data)
+// utilization returns usage as a percentage of total, or zero if total is not set.
+func utilization(usage, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return percentage(usage, total)
 }
@@ -2,10 +2,15 @@ package collector
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/Azure/aks-periscope/pkg/test"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
 func TestSystemPerfCollectorGetName(t *testing.T) {
@@ -91,3 +96,138 @@ func TestSystemPerfCollectorCollect(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPodMetrics(t *testing.T) {
+	nodes := []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1900m"),
+					corev1.ResourceMemory: resource.MustParse("6Gi"),
+				},
+			},
+		},
+	}
+
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "web-0"},
+			Spec: corev1.PodSpec{
+				NodeName: "node1",
+				Containers: []corev1.Container{
+					{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("100m"),
+								corev1.ResourceMemory: resource.MustParse("100Mi"),
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("200m"),
+								corev1.ResourceMemory: resource.MustParse("200Mi"),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "web-0"},
+			Spec: corev1.PodSpec{
+				NodeName:   "node1",
+				Containers: []corev1.Container{{Name: "app"}},
+			},
+		},
+	}
+
+	podMetrics := []metricsv1beta1.PodMetrics{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "web-0"},
+			Containers: []metricsv1beta1.ContainerMetrics{
+				{
+					Name: "app",
+					Usage: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("195m"),
+						corev1.ResourceMemory: resource.MustParse("50Mi"),
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "web-0"},
+			Containers: []metricsv1beta1.ContainerMetrics{
+				{
+					Name: "app",
+					Usage: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10m"),
+						corev1.ResourceMemory: resource.MustParse("10Mi"),
+					},
+				},
+			},
+		},
+	}
+
+	result, err := getPodMetrics(podMetrics, pods, nodes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []PodMetrics{
+		{
+			Namespace:                "ns1",
+			PodName:                  "web-0",
+			ContainerName:            "app",
+			NodeName:                 "node1",
+			CPUUsage:                 195,
+			MemoryUsage:              50 * 1024 * 1024,
+			CPURequest:               100,
+			CPULimit:                 200,
+			MemoryRequest:            100 * 1024 * 1024,
+			MemoryLimit:              200 * 1024 * 1024,
+			CPURequestUtilization:    195,
+			CPULimitUtilization:      97.5,
+			MemoryRequestUtilization: 50,
+			MemoryLimitUtilization:   25,
+			NodeCPUAllocatable:       1900,
+			NodeMemoryAllocatable:    6 * 1024 * 1024 * 1024,
+			OverCPURequest:           true,
+			AtCPULimit:               true,
+		},
+		{
+			Namespace:             "ns2",
+			PodName:               "web-0",
+			ContainerName:         "app",
+			NodeName:              "node1",
+			CPUUsage:              10,
+			MemoryUsage:           10 * 1024 * 1024,
+			NodeCPUAllocatable:    1900,
+			NodeMemoryAllocatable: 6 * 1024 * 1024 * 1024,
+		},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected pod metrics:\nexpected %+v\nfound    %+v", expected, result)
+	}
+
+	nodeResult, err := getNodeMetrics([]metricsv1beta1.NodeMetrics{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("950m"),
+				corev1.ResourceMemory: resource.MustParse("7Gi"),
+			},
+		},
+	}, nodes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(nodeResult) != 1 || nodeResult[0].CPUUtilization != 50 || nodeResult[0].CPUCapacity != 2000 || !nodeResult[0].OverAllocatable {
+		t.Errorf("unexpected node metrics: %+v", nodeResult)
+	}
+}
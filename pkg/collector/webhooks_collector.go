@@ -0,0 +1,353 @@
+package collector
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// WebhookServiceStatus is the state of the Service backing an admission webhook or APIService.
+type WebhookServiceStatus struct {
+	Namespace         string `json:"namespace"`
+	Name              string `json:"name"`
+	Port              int32  `json:"port"`
+	Exists            bool   `json:"exists"`
+	ReadyEndpoints    int    `json:"readyEndpoints"`
+	NotReadyEndpoints int    `json:"notReadyEndpoints"`
+	Error             string `json:"error,omitempty"`
+}
+
+// AdmissionWebhookStatus is a single webhook within a Validating or Mutating WebhookConfiguration.
+type AdmissionWebhookStatus struct {
+	Kind           string                  `json:"kind"`
+	Configuration  string                  `json:"configuration"`
+	Name           string                  `json:"name"`
+	FailurePolicy  string                  `json:"failurePolicy"`
+	TimeoutSeconds int32                   `json:"timeoutSeconds"`
+	URL            string                  `json:"url,omitempty"`
+	Service        *WebhookServiceStatus   `json:"service,omitempty"`
+	CABundle       []utils.CertificateInfo `json:"caBundle"`
+	CABundleError  string                  `json:"caBundleError,omitempty"`
+}
+
+// APIServiceStatus is an APIService registered with the aggregation layer.
+type APIServiceStatus struct {
+	Name                  string                  `json:"name"`
+	Available             bool                    `json:"available"`
+	AvailableReason       string                  `json:"availableReason"`
+	AvailableMessage      string                  `json:"availableMessage"`
+	InsecureSkipTLSVerify bool                    `json:"insecureSkipTLSVerify"`
+	Service               *WebhookServiceStatus   `json:"service,omitempty"`
+	CABundle              []utils.CertificateInfo `json:"caBundle"`
+	CABundleError         string                  `json:"caBundleError,omitempty"`
+}
+
+// WebhookFinding is a webhook or APIService that is likely to cause API requests to fail.
+type WebhookFinding struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// WebhooksCollector defines a Webhooks Collector struct
+type WebhooksCollector struct {
+	data          map[string]string
+	kubeconfig    *rest.Config
+	commandRunner *utils.KubeCommandRunner
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewWebhooksCollector is a constructor
+func NewWebhooksCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *WebhooksCollector {
+	return &WebhooksCollector{
+		data:          make(map[string]string),
+		kubeconfig:    config,
+		commandRunner: utils.NewKubeCommandRunner(config),
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *WebhooksCollector) GetName() string {
+	return "webhooks"
+}
+
+func (collector *WebhooksCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *WebhooksCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	webhooks := []AdmissionWebhookStatus{}
+
+	validatingConfigs, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing validatingwebhookconfigurations: %w", err)
+	}
+	for _, config := range validatingConfigs.Items {
+		for _, webhook := range config.Webhooks {
+			webhooks = append(webhooks, getAdmissionWebhookStatus("ValidatingWebhookConfiguration", config.Name, webhook.Name, webhook.ClientConfig, webhook.FailurePolicy, webhook.TimeoutSeconds))
+		}
+	}
+
+	mutatingConfigs, err := clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing mutatingwebhookconfigurations: %w", err)
+	}
+	for _, config := range mutatingConfigs.Items {
+		for _, webhook := range config.Webhooks {
+			webhooks = append(webhooks, getAdmissionWebhookStatus("MutatingWebhookConfiguration", config.Name, webhook.Name, webhook.ClientConfig, webhook.FailurePolicy, webhook.TimeoutSeconds))
+		}
+	}
+
+	apiServiceGVR := schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+	apiServiceList, err := collector.commandRunner.GetUnstructuredList(&apiServiceGVR, "", &metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing apiservices: %w", err)
+	}
+	apiServices := []APIServiceStatus{}
+	for i := range apiServiceList.Items {
+		apiServices = append(apiServices, getAPIServiceStatus(&apiServiceList.Items[i]))
+	}
+
+	// Many webhooks and APIServices can share a backing Service, so each is only looked up once.
+	serviceStatuses := map[string]*WebhookServiceStatus{}
+	resolveService := func(service *WebhookServiceStatus) {
+		if service == nil {
+			return
+		}
+		key := service.Namespace + "/" + service.Name
+		resolved, ok := serviceStatuses[key]
+		if !ok {
+			resolved = getWebhookServiceStatus(clientset, service.Namespace, service.Name)
+			serviceStatuses[key] = resolved
+		}
+		service.Exists = resolved.Exists
+		service.ReadyEndpoints = resolved.ReadyEndpoints
+		service.NotReadyEndpoints = resolved.NotReadyEndpoints
+		service.Error = resolved.Error
+	}
+	for i := range webhooks {
+		resolveService(webhooks[i].Service)
+	}
+	for i := range apiServices {
+		resolveService(apiServices[i].Service)
+	}
+
+	webhooksBytes, err := json.Marshal(webhooks)
+	if err != nil {
+		return fmt.Errorf("marshall admission webhooks to json: %w", err)
+	}
+	collector.data["webhooks_admission"] = string(webhooksBytes)
+
+	apiServicesBytes, err := json.Marshal(apiServices)
+	if err != nil {
+		return fmt.Errorf("marshall apiservices to json: %w", err)
+	}
+	collector.data["webhooks_apiservices"] = string(apiServicesBytes)
+
+	findingsBytes, err := json.Marshal(getWebhookFindings(webhooks, apiServices, time.Now()))
+	if err != nil {
+		return fmt.Errorf("marshall webhook findings to json: %w", err)
+	}
+	collector.data["webhooks_findings"] = string(findingsBytes)
+
+	return nil
+}
+
+func (collector *WebhooksCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+func getAdmissionWebhookStatus(kind, configuration, name string, clientConfig admissionregistrationv1.WebhookClientConfig, failurePolicy *admissionregistrationv1.FailurePolicyType, timeoutSeconds *int32) AdmissionWebhookStatus {
+	status := AdmissionWebhookStatus{
+		Kind:          kind,
+		Configuration: configuration,
+		Name:          name,
+		// These are the API server defaults for admissionregistration.k8s.io/v1.
+		FailurePolicy:  string(admissionregistrationv1.Fail),
+		TimeoutSeconds: 10,
+		CABundle:       []utils.CertificateInfo{},
+	}
+	if failurePolicy != nil {
+		status.FailurePolicy = string(*failurePolicy)
+	}
+	if timeoutSeconds != nil {
+		status.TimeoutSeconds = *timeoutSeconds
+	}
+	if clientConfig.URL != nil {
+		status.URL = *clientConfig.URL
+	}
+	if clientConfig.Service != nil {
+		status.Service = &WebhookServiceStatus{
+			Namespace: clientConfig.Service.Namespace,
+			Name:      clientConfig.Service.Name,
+			Port:      443,
+		}
+		if clientConfig.Service.Port != nil {
+			status.Service.Port = *clientConfig.Service.Port
+		}
+	}
+
+	certs, err := utils.GetCertificateInfos(clientConfig.CABundle)
+	if err != nil {
+		status.CABundleError = err.Error()
+	} else {
+		status.CABundle = certs
+	}
+
+	return status
+}
+
+func getAPIServiceStatus(apiService *unstructured.Unstructured) APIServiceStatus {
+	status := APIServiceStatus{
+		Name:     apiService.GetName(),
+		CABundle: []utils.CertificateInfo{},
+	}
+
+	status.InsecureSkipTLSVerify, _, _ = unstructured.NestedBool(apiService.Object, "spec", "insecureSkipTLSVerify")
+
+	// Local APIServices are served by the API server itself, and have no backing Service.
+	if service, found, _ := unstructured.NestedMap(apiService.Object, "spec", "service"); found && service != nil {
+		status.Service = &WebhookServiceStatus{Port: 443}
+		status.Service.Namespace, _, _ = unstructured.NestedString(service, "namespace")
+		status.Service.Name, _, _ = unstructured.NestedString(service, "name")
+		if port, found, _ := unstructured.NestedInt64(service, "port"); found {
+			status.Service.Port = int32(port)
+		}
+	}
+
+	// The caBundle is base64-encoded PEM data when read as unstructured JSON.
+	if caBundle, found, _ := unstructured.NestedString(apiService.Object, "spec", "caBundle"); found {
+		pemData, err := base64.StdEncoding.DecodeString(caBundle)
+		if err != nil {
+			status.CABundleError = fmt.Sprintf("error decoding caBundle: %v", err)
+		} else if certs, err := utils.GetCertificateInfos(pemData); err != nil {
+			status.CABundleError = err.Error()
+		} else {
+			status.CABundle = certs
+		}
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Available" {
+			continue
+		}
+		status.Available = condition["status"] == "True"
+		status.AvailableReason, _ = condition["reason"].(string)
+		status.AvailableMessage, _ = condition["message"].(string)
+	}
+
+	return status
+}
+
+func getWebhookServiceStatus(clientset *kubernetes.Clientset, namespace, name string) *WebhookServiceStatus {
+	status := &WebhookServiceStatus{Namespace: namespace, Name: name}
+
+	_, err := clientset.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			status.Error = fmt.Sprintf("error getting service: %v", err)
+			log.Printf("Failed to get service %s/%s: %v", namespace, name, err)
+		}
+		return status
+	}
+	status.Exists = true
+
+	endpoints, err := clientset.CoreV1().Endpoints(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			status.Error = fmt.Sprintf("error getting endpoints: %v", err)
+			log.Printf("Failed to get endpoints %s/%s: %v", namespace, name, err)
+		}
+		return status
+	}
+
+	for _, subset := range endpoints.Subsets {
+		status.ReadyEndpoints += len(subset.Addresses)
+		status.NotReadyEndpoints += len(subset.NotReadyAddresses)
+	}
+
+	return status
+}
+
+// getWebhookFindings flags the webhooks and APIServices whose failure will cause API requests to be rejected.
+func getWebhookFindings(webhooks []AdmissionWebhookStatus, apiServices []APIServiceStatus, now time.Time) []WebhookFinding {
+	findings := []WebhookFinding{}
+
+	for _, webhook := range webhooks {
+		name := webhook.Configuration + "/" + webhook.Name
+		if webhook.FailurePolicy == string(admissionregistrationv1.Fail) && webhook.Service != nil {
+			if reason := getServiceDownReason(webhook.Service); reason != "" {
+				findings = append(findings, WebhookFinding{Kind: webhook.Kind, Name: name, Reason: "failurePolicy is Fail and " + reason})
+			}
+		}
+		if reason := getCABundleReason(webhook.CABundle, webhook.CABundleError, now); reason != "" {
+			findings = append(findings, WebhookFinding{Kind: webhook.Kind, Name: name, Reason: reason})
+		}
+	}
+
+	for _, apiService := range apiServices {
+		if !apiService.Available {
+			reason := "not Available"
+			if apiService.AvailableReason != "" {
+				reason = fmt.Sprintf("%s: %s: %s", reason, apiService.AvailableReason, apiService.AvailableMessage)
+			}
+			findings = append(findings, WebhookFinding{Kind: "APIService", Name: apiService.Name, Reason: reason})
+		}
+		if reason := getCABundleReason(apiService.CABundle, apiService.CABundleError, now); reason != "" {
+			findings = append(findings, WebhookFinding{Kind: "APIService", Name: apiService.Name, Reason: reason})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Kind != findings[j].Kind {
+			return findings[i].Kind < findings[j].Kind
+		}
+		return findings[i].Name < findings[j].Name
+	})
+
+	return findings
+}
+
+func getServiceDownReason(service *WebhookServiceStatus) string {
+	switch {
+	case service.Error != "":
+		return fmt.Sprintf("service %s/%s could not be checked: %s", service.Namespace, service.Name, service.Error)
+	case !service.Exists:
+		return fmt.Sprintf("service %s/%s does not exist", service.Namespace, service.Name)
+	case service.ReadyEndpoints == 0:
+		return fmt.Sprintf("service %s/%s has no ready endpoints", service.Namespace, service.Name)
+	}
+	return ""
+}
+
+func getCABundleReason(certs []utils.CertificateInfo, caBundleError string, now time.Time) string {
+	if caBundleError != "" {
+		return "caBundle is invalid: " + caBundleError
+	}
+	if expiry, ok := utils.GetEarliestExpiry(certs); ok && expiry.Before(now) {
+		return fmt.Sprintf("caBundle certificate expired at %s", expiry.Format(time.RFC3339))
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestWebhooksCollectorGetName(t *testing.T) {
+	const expectedName = "webhooks"
+
+	c := NewWebhooksCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestWebhooksCollectorCheckSupported(t *testing.T) {
+	c := NewWebhooksCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestWebhooksCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewWebhooksCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"webhooks_admission":   regexp.MustCompile(`^\[.*\]$`),
+		"webhooks_apiservices": regexp.MustCompile(`"name":"v1\.apps","available":true`),
+		"webhooks_findings":    regexp.MustCompile(`^\[.*\]$`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestGetAdmissionWebhookStatus(t *testing.T) {
+	clientConfig := admissionregistrationv1.WebhookClientConfig{
+		Service: &admissionregistrationv1.ServiceReference{Namespace: "gatekeeper-system", Name: "gatekeeper-webhook-service"},
+	}
+
+	expected := AdmissionWebhookStatus{
+		Kind:           "ValidatingWebhookConfiguration",
+		Configuration:  "gatekeeper-validating-webhook-configuration",
+		Name:           "validation.gatekeeper.sh",
+		FailurePolicy:  "Fail",
+		TimeoutSeconds: 10,
+		Service:        &WebhookServiceStatus{Namespace: "gatekeeper-system", Name: "gatekeeper-webhook-service", Port: 443},
+		CABundle:       []utils.CertificateInfo{},
+	}
+
+	result := getAdmissionWebhookStatus("ValidatingWebhookConfiguration", "gatekeeper-validating-webhook-configuration", "validation.gatekeeper.sh", clientConfig, nil, nil)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected webhook status:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestGetAPIServiceStatus(t *testing.T) {
+	apiService := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "v1beta1.metrics.k8s.io"},
+		"spec": map[string]interface{}{
+			"service":               map[string]interface{}{"namespace": "kube-system", "name": "metrics-server", "port": int64(443)},
+			"insecureSkipTLSVerify": true,
+			"caBundle":              "not base64!",
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "False", "reason": "MissingEndpoints", "message": "endpoints for service/metrics-server in \"kube-system\" have no addresses"},
+			},
+		},
+	}}
+
+	result := getAPIServiceStatus(apiService)
+
+	if result.Name != "v1beta1.metrics.k8s.io" || result.Available || result.AvailableReason != "MissingEndpoints" || !result.InsecureSkipTLSVerify {
+		t.Errorf("unexpected apiservice status: %+v", result)
+	}
+	if !reflect.DeepEqual(result.Service, &WebhookServiceStatus{Namespace: "kube-system", Name: "metrics-server", Port: 443}) {
+		t.Errorf("unexpected apiservice service: %+v", result.Service)
+	}
+	if result.CABundleError == "" {
+		t.Errorf("expected caBundle error for invalid base64")
+	}
+}
+
+func TestGetWebhookFindings(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+
+	webhooks := []AdmissionWebhookStatus{
+		{
+			Kind:          "ValidatingWebhookConfiguration",
+			Configuration: "down",
+			Name:          "fail.example.com",
+			FailurePolicy: "Fail",
+			Service:       &WebhookServiceStatus{Namespace: "default", Name: "svc", Exists: true, NotReadyEndpoints: 1},
+			CABundle:      []utils.CertificateInfo{},
+		},
+		{
+			Kind:          "MutatingWebhookConfiguration",
+			Configuration: "down",
+			Name:          "ignore.example.com",
+			FailurePolicy: "Ignore",
+			Service:       &WebhookServiceStatus{Namespace: "default", Name: "missing"},
+			CABundle:      []utils.CertificateInfo{{NotAfter: expired}},
+		},
+		{
+			Kind:          "ValidatingWebhookConfiguration",
+			Configuration: "healthy",
+			Name:          "ok.example.com",
+			FailurePolicy: "Fail",
+			Service:       &WebhookServiceStatus{Namespace: "default", Name: "svc", Exists: true, ReadyEndpoints: 2},
+			CABundle:      []utils.CertificateInfo{{NotAfter: now.Add(time.Hour)}},
+		},
+	}
+
+	apiServices := []APIServiceStatus{
+		{Name: "v1.apps", Available: true, CABundle: []utils.CertificateInfo{}},
+		{Name: "v1beta1.metrics.k8s.io", AvailableReason: "MissingEndpoints", AvailableMessage: "no addresses", CABundle: []utils.CertificateInfo{}},
+	}
+
+	expected := []WebhookFinding{
+		{Kind: "APIService", Name: "v1beta1.metrics.k8s.io", Reason: "not Available: MissingEndpoints: no addresses"},
+		{Kind: "MutatingWebhookConfiguration", Name: "down/ignore.example.com", Reason: "caBundle certificate expired at 2021-10-01T11:00:00Z"},
+		{Kind: "ValidatingWebhookConfiguration", Name: "down/fail.example.com", Reason: "failurePolicy is Fail and service default/svc has no ready endpoints"},
+	}
+
+	result := getWebhookFindings(webhooks, apiServices, now)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected findings:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
@@ -0,0 +1,367 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// cronJobMissedScheduleTolerance allows for the CronJob controller being slightly late to create a job.
+const cronJobMissedScheduleTolerance = 5 * time.Minute
+
+// Workload issue types, which identify the kind of problem a workload has.
+const (
+	WorkloadIssueRolloutStuck        = "RolloutStuck"
+	WorkloadIssueRolloutIncomplete   = "RolloutIncomplete"
+	WorkloadIssueReplicasUnavailable = "ReplicasUnavailable"
+	WorkloadIssuePodsMisscheduled    = "PodsMisscheduled"
+	WorkloadIssuePaused              = "Paused"
+	WorkloadIssueJobFailed           = "JobFailed"
+	WorkloadIssueScheduleMissed      = "ScheduleMissed"
+	WorkloadIssueScheduleInvalid     = "ScheduleInvalid"
+	WorkloadIssueSuspended           = "Suspended"
+)
+
+// WorkloadIssue is a problem with a workload.
+type WorkloadIssue struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// WorkloadReplicaSet is a revision of a Deployment.
+type WorkloadReplicaSet struct {
+	Name          string    `json:"name"`
+	Revision      int64     `json:"revision"`
+	Replicas      int32     `json:"replicas"`
+	ReadyReplicas int32     `json:"readyReplicas"`
+	Images        []string  `json:"images"`
+	Created       time.Time `json:"created"`
+}
+
+// WorkloadStatus is the state of a workload controller. Replica counts are the controller's own: for DaemonSets they
+// count scheduled pods, and for Jobs the desired count is the number of completions.
+type WorkloadStatus struct {
+	Kind        string               `json:"kind"`
+	Namespace   string               `json:"namespace"`
+	Name        string               `json:"name"`
+	Desired     int32                `json:"desired"`
+	Current     int32                `json:"current"`
+	Ready       int32                `json:"ready"`
+	Updated     int32                `json:"updated"`
+	Available   int32                `json:"available"`
+	Failed      int32                `json:"failed,omitempty"`
+	ReplicaSets []WorkloadReplicaSet `json:"replicaSets,omitempty"`
+	Schedule    string               `json:"schedule,omitempty"`
+	LastRun     *time.Time           `json:"lastRun,omitempty"`
+	LastSuccess *time.Time           `json:"lastSuccess,omitempty"`
+	Issues      []WorkloadIssue      `json:"issues"`
+}
+
+// WorkloadsCollector defines a Workloads Collector struct
+type WorkloadsCollector struct {
+	data        map[string]string
+	kubeconfig  *rest.Config
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewWorkloadsCollector is a constructor
+func NewWorkloadsCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *WorkloadsCollector {
+	return &WorkloadsCollector{
+		data:        make(map[string]string),
+		kubeconfig:  config,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *WorkloadsCollector) GetName() string {
+	return "workloads"
+}
+
+func (collector *WorkloadsCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *WorkloadsCollector) Collect() error {
+	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("getting access to K8S failed: %w", err)
+	}
+
+	now := time.Now()
+	workloads := []WorkloadStatus{}
+	for _, namespace := range getConfiguredNamespaces(collector.runtimeInfo) {
+		deployments, err := clientset.AppsV1().Deployments(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing deployments in namespace '%s': %w", namespace, err)
+		}
+		replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing replicasets in namespace '%s': %w", namespace, err)
+		}
+		for _, deployment := range deployments.Items {
+			workloads = append(workloads, getDeploymentStatus(deployment, replicaSets.Items))
+		}
+
+		statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing statefulsets in namespace '%s': %w", namespace, err)
+		}
+		for _, statefulSet := range statefulSets.Items {
+			workloads = append(workloads, getStatefulSetStatus(statefulSet))
+		}
+
+		daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing daemonsets in namespace '%s': %w", namespace, err)
+		}
+		for _, daemonSet := range daemonSets.Items {
+			workloads = append(workloads, getDaemonSetStatus(daemonSet))
+		}
+
+		jobs, err := clientset.BatchV1().Jobs(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing jobs in namespace '%s': %w", namespace, err)
+		}
+		for _, job := range jobs.Items {
+			workloads = append(workloads, getJobStatus(job))
+		}
+
+		// batch/v1 CronJobs are only served from Kubernetes 1.21, so failing to list them should not lose the other workloads.
+		cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			log.Printf("Failed to list cronjobs in namespace '%s': %v", namespace, err)
+		} else {
+			for _, cronJob := range cronJobs.Items {
+				workloads = append(workloads, getCronJobStatus(cronJob, now))
+			}
+		}
+	}
+
+	sort.SliceStable(workloads, func(i, j int) bool {
+		if workloads[i].Kind != workloads[j].Kind {
+			return workloads[i].Kind < workloads[j].Kind
+		}
+		if workloads[i].Namespace != workloads[j].Namespace {
+			return workloads[i].Namespace < workloads[j].Namespace
+		}
+		return workloads[i].Name < workloads[j].Name
+	})
+
+	workloadsBytes, err := json.Marshal(workloads)
+	if err != nil {
+		return fmt.Errorf("marshall workloads to json: %w", err)
+	}
+	collector.data["workloads"] = string(workloadsBytes)
+
+	return nil
+}
+
+func (collector *WorkloadsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
+
+func getDeploymentStatus(deployment appsv1.Deployment, replicaSets []appsv1.ReplicaSet) WorkloadStatus {
+	status := WorkloadStatus{
+		Kind:        "Deployment",
+		Namespace:   deployment.Namespace,
+		Name:        deployment.Name,
+		Desired:     getDesiredReplicas(deployment.Spec.Replicas),
+		Current:     deployment.Status.Replicas,
+		Ready:       deployment.Status.ReadyReplicas,
+		Updated:     deployment.Status.UpdatedReplicas,
+		Available:   deployment.Status.AvailableReplicas,
+		ReplicaSets: []WorkloadReplicaSet{},
+		Issues:      []WorkloadIssue{},
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse {
+			status.Issues = append(status.Issues, WorkloadIssue{Type: WorkloadIssueRolloutStuck, Message: fmt.Sprintf("%s: %s", condition.Reason, condition.Message)})
+		}
+	}
+	if status.Available < status.Desired {
+		status.Issues = append(status.Issues, getReplicasUnavailableIssue(status.Available, status.Desired))
+	}
+	if deployment.Spec.Paused {
+		status.Issues = append(status.Issues, WorkloadIssue{Type: WorkloadIssuePaused, Message: "rollouts are paused"})
+	}
+
+	for _, replicaSet := range replicaSets {
+		if !metav1.IsControlledBy(&replicaSet, &deployment) {
+			continue
+		}
+
+		// The revision annotation is set by the deployment controller, so unparseable values are left as zero.
+		revision, _ := strconv.ParseInt(replicaSet.Annotations["deployment.kubernetes.io/revision"], 10, 64)
+		images := []string{}
+		for _, container := range replicaSet.Spec.Template.Spec.Containers {
+			images = append(images, container.Image)
+		}
+		status.ReplicaSets = append(status.ReplicaSets, WorkloadReplicaSet{
+			Name:          replicaSet.Name,
+			Revision:      revision,
+			Replicas:      replicaSet.Status.Replicas,
+			ReadyReplicas: replicaSet.Status.ReadyReplicas,
+			Images:        images,
+			Created:       replicaSet.CreationTimestamp.Time,
+		})
+	}
+	sort.Slice(status.ReplicaSets, func(i, j int) bool { return status.ReplicaSets[i].Revision > status.ReplicaSets[j].Revision })
+
+	return status
+}
+
+func getStatefulSetStatus(statefulSet appsv1.StatefulSet) WorkloadStatus {
+	status := WorkloadStatus{
+		Kind:      "StatefulSet",
+		Namespace: statefulSet.Namespace,
+		Name:      statefulSet.Name,
+		Desired:   getDesiredReplicas(statefulSet.Spec.Replicas),
+		Current:   statefulSet.Status.Replicas,
+		Ready:     statefulSet.Status.ReadyReplicas,
+		Updated:   statefulSet.Status.UpdatedReplicas,
+		Available: statefulSet.Status.ReadyReplicas,
+		Issues:    []WorkloadIssue{},
+	}
+
+	if status.Ready < status.Desired {
+		status.Issues = append(status.Issues, getReplicasUnavailableIssue(status.Ready, status.Desired))
+	}
+	if statefulSet.Status.UpdateRevision != "" && statefulSet.Status.UpdateRevision != statefulSet.Status.CurrentRevision {
+		status.Issues = append(status.Issues, WorkloadIssue{
+			Type:    WorkloadIssueRolloutIncomplete,
+			Message: fmt.Sprintf("%d of %d replicas updated to revision %s", status.Updated, status.Desired, statefulSet.Status.UpdateRevision),
+		})
+	}
+
+	return status
+}
+
+func getDaemonSetStatus(daemonSet appsv1.DaemonSet) WorkloadStatus {
+	status := WorkloadStatus{
+		Kind:      "DaemonSet",
+		Namespace: daemonSet.Namespace,
+		Name:      daemonSet.Name,
+		Desired:   daemonSet.Status.DesiredNumberScheduled,
+		Current:   daemonSet.Status.CurrentNumberScheduled,
+		Ready:     daemonSet.Status.NumberReady,
+		Updated:   daemonSet.Status.UpdatedNumberScheduled,
+		Available: daemonSet.Status.NumberAvailable,
+		Issues:    []WorkloadIssue{},
+	}
+
+	if daemonSet.Status.NumberUnavailable > 0 {
+		status.Issues = append(status.Issues, getReplicasUnavailableIssue(status.Available, status.Desired))
+	}
+	if daemonSet.Status.NumberMisscheduled > 0 {
+		status.Issues = append(status.Issues, WorkloadIssue{
+			Type:    WorkloadIssuePodsMisscheduled,
+			Message: fmt.Sprintf("%d pods are running on nodes they should not be", daemonSet.Status.NumberMisscheduled),
+		})
+	}
+
+	return status
+}
+
+func getJobStatus(job batchv1.Job) WorkloadStatus {
+	status := WorkloadStatus{
+		Kind:      "Job",
+		Namespace: job.Namespace,
+		Name:      job.Name,
+		Desired:   getDesiredReplicas(job.Spec.Completions),
+		Current:   job.Status.Active,
+		Available: job.Status.Succeeded,
+		Failed:    job.Status.Failed,
+		Issues:    []WorkloadIssue{},
+	}
+
+	if job.Status.CompletionTime != nil {
+		completionTime := job.Status.CompletionTime.Time
+		status.LastSuccess = &completionTime
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			status.Issues = append(status.Issues, WorkloadIssue{Type: WorkloadIssueJobFailed, Message: fmt.Sprintf("%s: %s", condition.Reason, condition.Message)})
+		}
+	}
+
+	return status
+}
+
+func getCronJobStatus(cronJob batchv1.CronJob, now time.Time) WorkloadStatus {
+	status := WorkloadStatus{
+		Kind:      "CronJob",
+		Namespace: cronJob.Namespace,
+		Name:      cronJob.Name,
+		Current:   int32(len(cronJob.Status.Active)),
+		Schedule:  cronJob.Spec.Schedule,
+		Issues:    []WorkloadIssue{},
+	}
+
+	if cronJob.Status.LastScheduleTime != nil {
+		lastRun := cronJob.Status.LastScheduleTime.Time
+		status.LastRun = &lastRun
+	}
+	if cronJob.Status.LastSuccessfulTime != nil {
+		lastSuccess := cronJob.Status.LastSuccessfulTime.Time
+		status.LastSuccess = &lastSuccess
+	}
+
+	if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+		status.Issues = append(status.Issues, WorkloadIssue{Type: WorkloadIssueSuspended, Message: "no new jobs will be scheduled"})
+		return status
+	}
+
+	schedule, err := utils.ParseCronSchedule(cronJob.Spec.Schedule)
+	if err != nil {
+		status.Issues = append(status.Issues, WorkloadIssue{Type: WorkloadIssueScheduleInvalid, Message: err.Error()})
+		return status
+	}
+
+	// The CronJob controller works in UTC unless the schedule sets a time zone, and only creates jobs after it was
+	// created or last ran.
+	from := cronJob.CreationTimestamp.Time.UTC()
+	if status.LastRun != nil {
+		from = status.LastRun.UTC()
+	}
+	next := schedule.Next(from)
+	if !next.IsZero() && next.Add(cronJobMissedScheduleTolerance).Before(now) {
+		message := fmt.Sprintf("expected a job at %s", next.Format(time.RFC3339))
+		if status.Current > 0 && cronJob.Spec.ConcurrencyPolicy == batchv1.ForbidConcurrent {
+			message += fmt.Sprintf(", but %d jobs are still active and concurrencyPolicy is Forbid", status.Current)
+		}
+		status.Issues = append(status.Issues, WorkloadIssue{Type: WorkloadIssueScheduleMissed, Message: message})
+	}
+
+	return status
+}
+
+// getDesiredReplicas returns the desired count, which defaults to 1 when not specified.
+func getDesiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func getReplicasUnavailableIssue(available, desired int32) WorkloadIssue {
+	return WorkloadIssue{
+		Type:    WorkloadIssueReplicasUnavailable,
+		Message: fmt.Sprintf("%d of %d replicas available", available, desired),
+	}
+}
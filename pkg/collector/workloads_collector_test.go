@@ -0,0 +1,267 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestWorkloadsCollectorGetName(t *testing.T) {
+	const expectedName = "workloads"
+
+	c := NewWorkloadsCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestWorkloadsCollectorCheckSupported(t *testing.T) {
+	c := NewWorkloadsCollector(nil, &utils.RuntimeInfo{CollectorList: []string{}})
+	err := c.CheckSupported()
+	if err != nil {
+		t.Errorf("CheckSupported() error = %v, wantErr false", err)
+	}
+}
+
+func TestWorkloadsCollectorCollect(t *testing.T) {
+	fixture, _ := test.GetClusterFixture()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		Namespaces:    []string{"kube-system"},
+	}
+
+	c := NewWorkloadsCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
+	err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	expectedData := map[string]*regexp.Regexp{
+		"workloads": regexp.MustCompile(`"kind":"Deployment","namespace":"kube-system","name":"coredns"`),
+	}
+
+	compareCollectorData(t, expectedData, c.GetData())
+}
+
+func TestGetDeploymentStatus(t *testing.T) {
+	replicas := int32(3)
+	created := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	isController := true
+
+	deployment := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web", UID: types.UID("web-uid")},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          4,
+			ReadyReplicas:     2,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 2,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded", Message: `ReplicaSet "web-2" has timed out progressing.`},
+			},
+		},
+	}
+
+	newReplicaSet := func(name, revision string, replicas int32, image string, owner types.UID) appsv1.ReplicaSet {
+		return appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "app",
+				Name:              name,
+				Annotations:       map[string]string{"deployment.kubernetes.io/revision": revision},
+				OwnerReferences:   []metav1.OwnerReference{{UID: owner, Controller: &isController}},
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: appsv1.ReplicaSetSpec{
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Image: image}}}},
+			},
+			Status: appsv1.ReplicaSetStatus{Replicas: replicas, ReadyReplicas: replicas},
+		}
+	}
+	replicaSets := []appsv1.ReplicaSet{
+		newReplicaSet("web-1", "1", 3, "web:1", "web-uid"),
+		newReplicaSet("web-2", "2", 1, "web:2", "web-uid"),
+		newReplicaSet("other-1", "1", 1, "other:1", "other-uid"),
+	}
+
+	expected := WorkloadStatus{
+		Kind:      "Deployment",
+		Namespace: "app",
+		Name:      "web",
+		Desired:   3,
+		Current:   4,
+		Ready:     2,
+		Updated:   1,
+		Available: 2,
+		ReplicaSets: []WorkloadReplicaSet{
+			{Name: "web-2", Revision: 2, Replicas: 1, ReadyReplicas: 1, Images: []string{"web:2"}, Created: created},
+			{Name: "web-1", Revision: 1, Replicas: 3, ReadyReplicas: 3, Images: []string{"web:1"}, Created: created},
+		},
+		Issues: []WorkloadIssue{
+			{Type: WorkloadIssueRolloutStuck, Message: `ProgressDeadlineExceeded: ReplicaSet "web-2" has timed out progressing.`},
+			{Type: WorkloadIssueReplicasUnavailable, Message: "2 of 3 replicas available"},
+		},
+	}
+
+	result := getDeploymentStatus(deployment, replicaSets)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected deployment status:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestGetStatefulSetAndDaemonSetStatus(t *testing.T) {
+	statefulSet := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "db"},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:        1,
+			ReadyReplicas:   1,
+			UpdatedReplicas: 0,
+			CurrentRevision: "db-1",
+			UpdateRevision:  "db-2",
+		},
+	}
+
+	expectedIssues := []WorkloadIssue{{Type: WorkloadIssueRolloutIncomplete, Message: "0 of 1 replicas updated to revision db-2"}}
+	result := getStatefulSetStatus(statefulSet)
+	if !reflect.DeepEqual(result.Issues, expectedIssues) {
+		t.Errorf("unexpected statefulset issues:\nexpected %+v\nfound    %+v", expectedIssues, result.Issues)
+	}
+
+	daemonSet := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "kube-proxy"},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 3,
+			CurrentNumberScheduled: 3,
+			NumberReady:            2,
+			NumberAvailable:        2,
+			NumberUnavailable:      1,
+			NumberMisscheduled:     1,
+		},
+	}
+
+	expectedIssues = []WorkloadIssue{
+		{Type: WorkloadIssueReplicasUnavailable, Message: "2 of 3 replicas available"},
+		{Type: WorkloadIssuePodsMisscheduled, Message: "1 pods are running on nodes they should not be"},
+	}
+	result = getDaemonSetStatus(daemonSet)
+	if !reflect.DeepEqual(result.Issues, expectedIssues) {
+		t.Errorf("unexpected daemonset issues:\nexpected %+v\nfound    %+v", expectedIssues, result.Issues)
+	}
+}
+
+func TestGetJobStatus(t *testing.T) {
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "migrate"},
+		Status: batchv1.JobStatus{
+			Failed: 6,
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"},
+			},
+		},
+	}
+
+	expected := WorkloadStatus{
+		Kind:      "Job",
+		Namespace: "app",
+		Name:      "migrate",
+		Desired:   1,
+		Failed:    6,
+		Issues:    []WorkloadIssue{{Type: WorkloadIssueJobFailed, Message: "BackoffLimitExceeded: Job has reached the specified backoff limit"}},
+	}
+
+	result := getJobStatus(job)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected job status:\nexpected %+v\nfound    %+v", expected, result)
+	}
+}
+
+func TestGetCronJobStatus(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	suspend := true
+
+	tests := []struct {
+		name       string
+		cronJob    batchv1.CronJob
+		wantIssues []WorkloadIssue
+	}{
+		{
+			name: "on schedule",
+			cronJob: batchv1.CronJob{
+				Spec:   batchv1.CronJobSpec{Schedule: "0 * * * *"},
+				Status: batchv1.CronJobStatus{LastScheduleTime: &metav1.Time{Time: now.Add(-time.Hour)}},
+			},
+			wantIssues: []WorkloadIssue{},
+		},
+		{
+			name: "missed with forbidden concurrency",
+			cronJob: batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{Schedule: "*/10 * * * *", ConcurrencyPolicy: batchv1.ForbidConcurrent},
+				Status: batchv1.CronJobStatus{
+					LastScheduleTime: &metav1.Time{Time: now.Add(-time.Hour)},
+					Active:           []corev1.ObjectReference{{Name: "job-1"}},
+				},
+			},
+			wantIssues: []WorkloadIssue{{Type: WorkloadIssueScheduleMissed, Message: "expected a job at 2021-10-01T11:10:00Z, but 1 jobs are still active and concurrencyPolicy is Forbid"}},
+		},
+		{
+			name: "never run since creation",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-48 * time.Hour))},
+				Spec:       batchv1.CronJobSpec{Schedule: "@daily"},
+			},
+			wantIssues: []WorkloadIssue{{Type: WorkloadIssueScheduleMissed, Message: "expected a job at 2021-09-30T00:00:00Z"}},
+		},
+		{
+			name: "on schedule in a time zone",
+			cronJob: batchv1.CronJob{
+				// 21:00 in Tokyo is 12:00 UTC.
+				Spec:   batchv1.CronJobSpec{Schedule: "CRON_TZ=Asia/Tokyo 0 21 * * *"},
+				Status: batchv1.CronJobStatus{LastScheduleTime: &metav1.Time{Time: now.Add(-24 * time.Hour)}},
+			},
+			wantIssues: []WorkloadIssue{},
+		},
+		{
+			name: "on schedule at a constant interval",
+			cronJob: batchv1.CronJob{
+				Spec:   batchv1.CronJobSpec{Schedule: "@every 2h"},
+				Status: batchv1.CronJobStatus{LastScheduleTime: &metav1.Time{Time: now.Add(-time.Hour)}},
+			},
+			wantIssues: []WorkloadIssue{},
+		},
+		{
+			name: "missed at a constant interval",
+			cronJob: batchv1.CronJob{
+				Spec:   batchv1.CronJobSpec{Schedule: "@every 30m"},
+				Status: batchv1.CronJobStatus{LastScheduleTime: &metav1.Time{Time: now.Add(-time.Hour)}},
+			},
+			wantIssues: []WorkloadIssue{{Type: WorkloadIssueScheduleMissed, Message: "expected a job at 2021-10-01T11:30:00Z"}},
+		},
+		{
+			name: "suspended",
+			cronJob: batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{Schedule: "* * * * *", Suspend: &suspend},
+			},
+			wantIssues: []WorkloadIssue{{Type: WorkloadIssueSuspended, Message: "no new jobs will be scheduled"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := getCronJobStatus(tt.cronJob, now)
+			if !reflect.DeepEqual(result.Issues, tt.wantIssues) {
+				t.Errorf("unexpected issues:\nexpected %+v\nfound    %+v", tt.wantIssues, result.Issues)
+			}
+		})
+	}
+}
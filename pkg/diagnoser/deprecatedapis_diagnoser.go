@@ -0,0 +1,142 @@
+package diagnoser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/Azure/aks-periscope/pkg/collector"
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+type deprecatedAPIObjectDatum struct {
+	Release          string `json:"Release"`
+	ReleaseNamespace string `json:"ReleaseNamespace"`
+	Revision         int    `json:"Revision"`
+	Kind             string `json:"Kind"`
+	Namespace        string `json:"Namespace"`
+	Name             string `json:"Name"`
+	APIVersion       string `json:"APIVersion"`
+	RemovedIn        string `json:"RemovedIn"`
+	Replacement      string `json:"Replacement"`
+}
+
+type deprecatedAPIRequestDatum struct {
+	GroupVersion string  `json:"GroupVersion"`
+	Resource     string  `json:"Resource"`
+	Subresource  string  `json:"Subresource"`
+	Requests     float64 `json:"Requests"`
+	RemovedIn    string  `json:"RemovedIn"`
+	Replacement  string  `json:"Replacement"`
+}
+
+type deprecatedAPIsDiagnosticDatum struct {
+	ServerVersion string                      `json:"ServerVersion"`
+	TargetVersion string                      `json:"TargetVersion"`
+	HelmObjects   []deprecatedAPIObjectDatum  `json:"HelmObjects"`
+	RequestedAPIs []deprecatedAPIRequestDatum `json:"RequestedAPIs"`
+}
+
+// DeprecatedAPIsDiagnoser defines a DeprecatedAPIs Diagnoser struct
+type DeprecatedAPIsDiagnoser struct {
+	runtimeInfo             *utils.RuntimeInfo
+	deprecatedAPIsCollector *collector.DeprecatedAPIsCollector
+	data                    map[string]string
+}
+
+// NewDeprecatedAPIsDiagnoser is a constructor
+func NewDeprecatedAPIsDiagnoser(runtimeInfo *utils.RuntimeInfo, deprecatedAPIsCollector *collector.DeprecatedAPIsCollector) *DeprecatedAPIsDiagnoser {
+	return &DeprecatedAPIsDiagnoser{
+		runtimeInfo:             runtimeInfo,
+		deprecatedAPIsCollector: deprecatedAPIsCollector,
+		data:                    make(map[string]string),
+	}
+}
+
+func (diagnoser *DeprecatedAPIsDiagnoser) GetName() string {
+	return "deprecatedapis"
+}
+
+// Diagnose implements the interface method
+func (diagnoser *DeprecatedAPIsDiagnoser) Diagnose() error {
+	versions := diagnoser.deprecatedAPIsCollector.Versions
+	if versions == nil {
+		return fmt.Errorf("no deprecated API data was collected")
+	}
+
+	deprecatedAPIsDiagnosticData := deprecatedAPIsDiagnosticDatum{
+		ServerVersion: versions.ServerVersion,
+		TargetVersion: versions.TargetVersion,
+		HelmObjects:   []deprecatedAPIObjectDatum{},
+		RequestedAPIs: []deprecatedAPIRequestDatum{},
+	}
+
+	for _, object := range diagnoser.deprecatedAPIsCollector.HelmObjects {
+		api, ok := utils.GetRemovedAPIForKind(object.APIVersion, object.Kind)
+		if !ok || !isRemovedByTarget(api, versions.TargetVersion) {
+			continue
+		}
+
+		deprecatedAPIsDiagnosticData.HelmObjects = append(deprecatedAPIsDiagnosticData.HelmObjects, deprecatedAPIObjectDatum{
+			Release:          object.Release,
+			ReleaseNamespace: object.ReleaseNamespace,
+			Revision:         object.Revision,
+			Kind:             object.Kind,
+			Namespace:        object.Namespace,
+			Name:             object.Name,
+			APIVersion:       object.APIVersion,
+			RemovedIn:        api.RemovedIn,
+			Replacement:      api.Replacement,
+		})
+	}
+
+	for _, request := range diagnoser.deprecatedAPIsCollector.RequestedAPIs {
+		// The API server reports the removal release itself, which is used for APIs missing from the known list.
+		api, ok := utils.GetRemovedAPIForResource(request.Group, request.Version, request.Resource)
+		if !ok {
+			api = utils.RemovedAPI{GroupVersion: getGroupVersion(request.Group, request.Version), RemovedIn: request.RemovedRelease, Replacement: "unknown"}
+		}
+		if len(api.RemovedIn) == 0 || !isRemovedByTarget(api, versions.TargetVersion) {
+			continue
+		}
+
+		deprecatedAPIsDiagnosticData.RequestedAPIs = append(deprecatedAPIsDiagnosticData.RequestedAPIs, deprecatedAPIRequestDatum{
+			GroupVersion: api.GroupVersion,
+			Resource:     request.Resource,
+			Subresource:  request.Subresource,
+			Requests:     request.Requests,
+			RemovedIn:    api.RemovedIn,
+			Replacement:  api.Replacement,
+		})
+	}
+
+	dataBytes, err := json.Marshal(deprecatedAPIsDiagnosticData)
+	if err != nil {
+		return fmt.Errorf("marshal data from DeprecatedAPIs Diagnoser: %w", err)
+	}
+
+	diagnoser.data["deprecatedapis"] = string(dataBytes)
+
+	return nil
+}
+
+func (diagnoser *DeprecatedAPIsDiagnoser) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(diagnoser.data)
+}
+
+func isRemovedByTarget(api utils.RemovedAPI, targetVersion string) bool {
+	removed, err := utils.IsRemovedBy(api.RemovedIn, targetVersion)
+	if err != nil {
+		log.Printf("Unable to compare %s removal release with target version: %v", api.GroupVersion, err)
+		return false
+	}
+	return removed
+}
+
+func getGroupVersion(group, version string) string {
+	if len(group) == 0 {
+		return version
+	}
+	return group + "/" + version
+}
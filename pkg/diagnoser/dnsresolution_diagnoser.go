@@ -0,0 +1,224 @@
+package diagnoser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/collector"
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+const defaultClusterDomain = "cluster.local"
+
+type dnsNdotsAmplificationDatum struct {
+	Name             string   `json:"Name"`
+	Dots             int      `json:"Dots"`
+	Ndots            int      `json:"Ndots"`
+	SearchDomains    []string `json:"SearchDomains"`
+	QueriesPerLookup int      `json:"QueriesPerLookup"`
+}
+
+type dnsNameserverHealthDatum struct {
+	Nameserver     string   `json:"Nameserver"`
+	Source         string   `json:"Source"`
+	Queries        int      `json:"Queries"`
+	Failures       int      `json:"Failures"`
+	ServerFailures int      `json:"ServerFailures"`
+	Unreachable    bool     `json:"Unreachable"`
+	Errors         []string `json:"Errors"`
+}
+
+type dnsAnswerDatum struct {
+	Nameserver string   `json:"Nameserver"`
+	Source     string   `json:"Source"`
+	Rcode      string   `json:"Rcode"`
+	Answers    []string `json:"Answers"`
+}
+
+type dnsInconsistentAnswersDatum struct {
+	Name          string           `json:"Name"`
+	RcodeMismatch bool             `json:"RcodeMismatch"`
+	Answers       []dnsAnswerDatum `json:"Answers"`
+}
+
+type dnsResolutionDiagnosticDatum struct {
+	HostName            string                        `json:"HostName"`
+	NdotsAmplification  []dnsNdotsAmplificationDatum  `json:"NdotsAmplification"`
+	FailingNameservers  []dnsNameserverHealthDatum    `json:"FailingNameservers"`
+	InconsistentAnswers []dnsInconsistentAnswersDatum `json:"InconsistentAnswers"`
+}
+
+// DNSResolutionDiagnoser defines a DNSResolution Diagnoser struct
+type DNSResolutionDiagnoser struct {
+	runtimeInfo       *utils.RuntimeInfo
+	dnsProbeCollector *collector.DNSProbeCollector
+	data              map[string]string
+}
+
+// NewDNSResolutionDiagnoser is a constructor
+func NewDNSResolutionDiagnoser(runtimeInfo *utils.RuntimeInfo, dnsProbeCollector *collector.DNSProbeCollector) *DNSResolutionDiagnoser {
+	return &DNSResolutionDiagnoser{
+		runtimeInfo:       runtimeInfo,
+		dnsProbeCollector: dnsProbeCollector,
+		data:              make(map[string]string),
+	}
+}
+
+func (diagnoser *DNSResolutionDiagnoser) GetName() string {
+	return "dnsresolution"
+}
+
+// Diagnose implements the interface method
+func (diagnoser *DNSResolutionDiagnoser) Diagnose() error {
+	containerConf := diagnoser.dnsProbeCollector.ContainerResolvConf
+	if containerConf == nil {
+		return fmt.Errorf("no DNS probe results were collected")
+	}
+
+	results := diagnoser.dnsProbeCollector.Results
+	names := []string{}
+	resultsByName := map[string][]collector.DNSProbeResult{}
+	for _, result := range results {
+		if _, ok := resultsByName[result.Name]; !ok {
+			names = append(names, result.Name)
+		}
+		resultsByName[result.Name] = append(resultsByName[result.Name], result)
+	}
+
+	dnsResolutionDiagnosticData := dnsResolutionDiagnosticDatum{
+		HostName:            diagnoser.runtimeInfo.HostNodeName,
+		NdotsAmplification:  getNdotsAmplification(names, containerConf),
+		FailingNameservers:  getFailingNameservers(results),
+		InconsistentAnswers: []dnsInconsistentAnswersDatum{},
+	}
+
+	clusterDomain := getClusterDomain(containerConf)
+	for _, name := range names {
+		// Cluster names are only resolvable by cluster DNS, so the VM's nameservers are expected to differ.
+		isClusterName := strings.HasSuffix(strings.TrimSuffix(name, "."), "."+clusterDomain)
+
+		answers := []dnsAnswerDatum{}
+		signatures := map[string]bool{}
+		rcodes := map[string]bool{}
+		for _, result := range resultsByName[name] {
+			if len(result.Error) > 0 || (isClusterName && result.Nameserver.Source == "virtualmachine") {
+				continue
+			}
+
+			// Load-balanced or geo-distributed names may legitimately return different addresses from
+			// different resolvers, so these are reported for investigation rather than treated as errors.
+			sortedAnswers := append([]string{}, result.Answers...)
+			sort.Strings(sortedAnswers)
+			signatures[result.Rcode+" "+strings.Join(sortedAnswers, ",")] = true
+			rcodes[result.Rcode] = true
+			answers = append(answers, dnsAnswerDatum{
+				Nameserver: result.Nameserver.Address,
+				Source:     result.Nameserver.Source,
+				Rcode:      result.Rcode,
+				Answers:    sortedAnswers,
+			})
+		}
+
+		if len(signatures) > 1 {
+			dnsResolutionDiagnosticData.InconsistentAnswers = append(dnsResolutionDiagnosticData.InconsistentAnswers, dnsInconsistentAnswersDatum{
+				Name:          name,
+				RcodeMismatch: len(rcodes) > 1,
+				Answers:       answers,
+			})
+		}
+	}
+
+	dataBytes, err := json.Marshal(dnsResolutionDiagnosticData)
+	if err != nil {
+		return fmt.Errorf("marshal data from DNSResolution Diagnoser: %w", err)
+	}
+
+	diagnoser.data["dnsresolution"] = string(dataBytes)
+
+	return nil
+}
+
+func (diagnoser *DNSResolutionDiagnoser) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(diagnoser.data)
+}
+
+// getNdotsAmplification finds the names that a pod's resolver will first try with each search domain appended,
+// because they contain fewer dots than the ndots option. Each of these lookups is sent for both A and AAAA records.
+func getNdotsAmplification(names []string, conf *utils.ResolvConf) []dnsNdotsAmplificationDatum {
+	result := []dnsNdotsAmplificationDatum{}
+	if len(conf.Search) == 0 {
+		return result
+	}
+
+	for _, name := range names {
+		if strings.HasSuffix(name, ".") {
+			continue
+		}
+
+		dots := strings.Count(name, ".")
+		if dots < conf.Ndots {
+			result = append(result, dnsNdotsAmplificationDatum{
+				Name:             name,
+				Dots:             dots,
+				Ndots:            conf.Ndots,
+				SearchDomains:    conf.Search,
+				QueriesPerLookup: (len(conf.Search) + 1) * 2,
+			})
+		}
+	}
+
+	return result
+}
+
+// getFailingNameservers returns the nameservers for which any query timed out, failed or returned a server error.
+func getFailingNameservers(results []collector.DNSProbeResult) []dnsNameserverHealthDatum {
+	keys := []string{}
+	healthByNameserver := map[string]*dnsNameserverHealthDatum{}
+	for _, result := range results {
+		key := result.Nameserver.Source + "/" + result.Nameserver.Address
+		health, ok := healthByNameserver[key]
+		if !ok {
+			health = &dnsNameserverHealthDatum{
+				Nameserver: result.Nameserver.Address,
+				Source:     result.Nameserver.Source,
+				Errors:     []string{},
+			}
+			healthByNameserver[key] = health
+			keys = append(keys, key)
+		}
+
+		health.Queries++
+		if len(result.Error) > 0 {
+			health.Failures++
+			if !utils.Contains(health.Errors, result.Error) {
+				health.Errors = append(health.Errors, result.Error)
+			}
+		} else if result.Rcode == "SERVFAIL" || result.Rcode == "REFUSED" {
+			health.ServerFailures++
+		}
+	}
+
+	failing := []dnsNameserverHealthDatum{}
+	for _, key := range keys {
+		health := healthByNameserver[key]
+		if health.Failures > 0 || health.ServerFailures > 0 {
+			health.Unreachable = health.Failures == health.Queries
+			failing = append(failing, *health)
+		}
+	}
+
+	return failing
+}
+
+// getClusterDomain finds the cluster domain from the 'svc.<cluster-domain>' entry in a pod's search domains.
+func getClusterDomain(conf *utils.ResolvConf) string {
+	for _, search := range conf.Search {
+		if strings.HasPrefix(search, "svc.") {
+			return strings.TrimPrefix(search, "svc.")
+		}
+	}
+	return defaultClusterDomain
+}
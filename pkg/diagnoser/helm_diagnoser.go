@@ -0,0 +1,218 @@
+package diagnoser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/collector"
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const (
+	// helmDefaultTimeWindow is used for recent rollbacks and app version changes when DIAGNOSTIC_TIME_WINDOW is not set.
+	helmDefaultTimeWindow = time.Hour
+	// helmPendingThreshold is how long a release can be pending before it is considered stuck. This is well beyond
+	// Helm's default 5 minute timeout, after which the client gives up without updating the release status.
+	helmPendingThreshold = 15 * time.Minute
+)
+
+// Findings are sorted by priority, with 1 being the most urgent.
+const (
+	helmPriorityHigh   = 1
+	helmPriorityMedium = 2
+	helmPriorityLow    = 3
+)
+
+var helmSeverities = map[int]string{
+	helmPriorityHigh:   "High",
+	helmPriorityMedium: "Medium",
+	helmPriorityLow:    "Low",
+}
+
+type helmFindingDatum struct {
+	Priority  int       `json:"Priority"`
+	Severity  string    `json:"Severity"`
+	Type      string    `json:"Type"`
+	Namespace string    `json:"Namespace"`
+	Release   string    `json:"Release"`
+	Chart     string    `json:"Chart"`
+	Revision  int       `json:"Revision"`
+	Date      time.Time `json:"Date"`
+	Message   string    `json:"Message"`
+}
+
+type helmDiagnosticDatum struct {
+	WindowStart time.Time          `json:"WindowStart"`
+	Findings    []helmFindingDatum `json:"Findings"`
+}
+
+// HelmDiagnoser defines a Helm Diagnoser struct
+type HelmDiagnoser struct {
+	runtimeInfo   *utils.RuntimeInfo
+	helmCollector *collector.HelmCollector
+	data          map[string]string
+}
+
+// NewHelmDiagnoser is a constructor
+func NewHelmDiagnoser(runtimeInfo *utils.RuntimeInfo, helmCollector *collector.HelmCollector) *HelmDiagnoser {
+	return &HelmDiagnoser{
+		runtimeInfo:   runtimeInfo,
+		helmCollector: helmCollector,
+		data:          make(map[string]string),
+	}
+}
+
+func (diagnoser *HelmDiagnoser) GetName() string {
+	return "helm"
+}
+
+// Diagnose implements the interface method
+func (diagnoser *HelmDiagnoser) Diagnose() error {
+	// The Helm collector only runs on connected clusters, so there is nothing to diagnose elsewhere.
+	if err := diagnoser.helmCollector.CheckSupported(); err != nil {
+		log.Printf("Skipping Helm diagnosis: %v", err)
+		return nil
+	}
+
+	releases := diagnoser.helmCollector.Releases
+	if releases == nil {
+		return fmt.Errorf("no helm releases were collected")
+	}
+
+	window := diagnoser.runtimeInfo.TimeWindow
+	if window <= 0 {
+		window = helmDefaultTimeWindow
+	}
+	now := time.Now()
+
+	helmDiagnosticData := helmDiagnosticDatum{
+		WindowStart: now.Add(-window),
+		Findings:    getHelmFindings(releases, now, window),
+	}
+
+	dataBytes, err := json.Marshal(helmDiagnosticData)
+	if err != nil {
+		return fmt.Errorf("marshal data from Helm Diagnoser: %w", err)
+	}
+
+	diagnoser.data["helm_findings"] = string(dataBytes)
+
+	return nil
+}
+
+func (diagnoser *HelmDiagnoser) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(diagnoser.data)
+}
+
+// getHelmFindings finds the releases that are stuck or failed, and those that were rolled back or changed
+// app version within the time window.
+func getHelmFindings(releases []collector.HelmRelease, now time.Time, window time.Duration) []helmFindingDatum {
+	windowStart := now.Add(-window)
+	findings := []helmFindingDatum{}
+
+	addFinding := func(r collector.HelmRelease, priority int, findingType string, revision int, date time.Time, message string) {
+		findings = append(findings, helmFindingDatum{
+			Priority:  priority,
+			Severity:  helmSeverities[priority],
+			Type:      findingType,
+			Namespace: r.Namespace,
+			Release:   r.Name,
+			Chart:     r.ChartName,
+			Revision:  revision,
+			Date:      date,
+			Message:   message,
+		})
+	}
+
+	for _, r := range releases {
+		// The history is sorted by revision, so the last entry is the latest revision, if there is one.
+		latest := collector.HelmReleaseHistory{Status: r.Status, Revision: r.Revision}
+		if len(r.History) > 0 {
+			latest = r.History[len(r.History)-1]
+		}
+
+		if latest.Status.IsPending() {
+			// A release without a known deployment time is reported, since it cannot be shown to be in progress.
+			pendingFor := now.Sub(latest.Date)
+			if latest.Date.IsZero() {
+				addFinding(r, helmPriorityHigh, "StuckPending", latest.Revision, latest.Date,
+					fmt.Sprintf("release is %s", latest.Status))
+			} else if pendingFor >= helmPendingThreshold {
+				addFinding(r, helmPriorityHigh, "StuckPending", latest.Revision, latest.Date,
+					fmt.Sprintf("release has been %s for %s", latest.Status, pendingFor.Round(time.Second)))
+			}
+		}
+
+		if latest.Status == release.StatusFailed {
+			message := "latest revision failed"
+			if len(latest.Message) > 0 {
+				message = fmt.Sprintf("latest revision failed: %s", latest.Message)
+			}
+			addFinding(r, helmPriorityHigh, "LatestRevisionFailed", latest.Revision, latest.Date, message)
+		}
+
+		// Helm describes rollback revisions as 'Rollback to <revision>'.
+		for _, history := range r.History {
+			if history.Date.Before(windowStart) || !strings.HasPrefix(history.Message, "Rollback to") {
+				continue
+			}
+			addFinding(r, helmPriorityMedium, "RecentRollback", history.Revision, history.Date,
+				fmt.Sprintf("revision %d: %s", history.Revision, history.Message))
+		}
+
+		if appVersions, revision, date := getHelmAppVersionChanges(r.History, windowStart); len(appVersions) > 1 {
+			addFinding(r, helmPriorityLow, "AppVersionChanged", revision, date,
+				fmt.Sprintf("app version changed from %s within the last %s", strings.Join(appVersions, " to "), window))
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Release != b.Release {
+			return a.Release < b.Release
+		}
+		return a.Revision < b.Revision
+	})
+
+	return findings
+}
+
+// getHelmAppVersionChanges returns the sequence of distinct app versions of the revisions deployed within the
+// window, starting from the version in use before the window, along with the latest revision to change it.
+func getHelmAppVersionChanges(history []collector.HelmReleaseHistory, windowStart time.Time) ([]string, int, time.Time) {
+	appVersions := []string{}
+	revision := 0
+	date := time.Time{}
+
+	for i, entry := range history {
+		inWindow := !entry.Date.Before(windowStart)
+		// Only the last revision before the window is needed, as the baseline.
+		isBaseline := !inWindow && (i+1 == len(history) || !history[i+1].Date.Before(windowStart))
+		if !inWindow && !isBaseline {
+			continue
+		}
+
+		if len(appVersions) > 0 && appVersions[len(appVersions)-1] == entry.AppVersion {
+			continue
+		}
+		appVersions = append(appVersions, entry.AppVersion)
+		if len(appVersions) > 1 {
+			revision = entry.Revision
+			date = entry.Date
+		}
+	}
+
+	return appVersions, revision, date
+}
@@ -0,0 +1,191 @@
+package diagnoser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/collector"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestHelmDiagnoserSkipsUnsupportedCollector(t *testing.T) {
+	runtimeInfo := &utils.RuntimeInfo{CollectorList: []string{}}
+	d := NewHelmDiagnoser(runtimeInfo, collector.NewHelmCollector(nil, runtimeInfo))
+
+	if err := d.Diagnose(); err != nil {
+		t.Errorf("Diagnose() error = %v, wantErr false", err)
+	}
+	if len(d.GetData()) != 0 {
+		t.Errorf("expected no data, found %d items", len(d.GetData()))
+	}
+}
+
+func TestGetHelmFindings(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	window := time.Hour
+
+	tests := []struct {
+		name     string
+		releases []collector.HelmRelease
+		expected []helmFindingDatum
+	}{
+		{
+			name: "healthy release",
+			releases: []collector.HelmRelease{
+				{Name: "web", Namespace: "app", ChartName: "web", Status: release.StatusDeployed, Revision: 1, History: []collector.HelmReleaseHistory{
+					{Revision: 1, Status: release.StatusDeployed, AppVersion: "1.0", Date: now.Add(-30 * time.Minute), Message: "Install complete"},
+				}},
+			},
+			expected: []helmFindingDatum{},
+		},
+		{
+			name: "pending within threshold",
+			releases: []collector.HelmRelease{
+				{Name: "web", Namespace: "app", ChartName: "web", Status: release.StatusPendingUpgrade, Revision: 1, History: []collector.HelmReleaseHistory{
+					{Revision: 1, Status: release.StatusPendingUpgrade, AppVersion: "1.0", Date: now.Add(-5 * time.Minute)},
+				}},
+			},
+			expected: []helmFindingDatum{},
+		},
+		{
+			name: "pending beyond threshold",
+			releases: []collector.HelmRelease{
+				{Name: "web", Namespace: "app", ChartName: "web", Status: release.StatusPendingInstall, Revision: 1, History: []collector.HelmReleaseHistory{
+					{Revision: 1, Status: release.StatusPendingInstall, AppVersion: "1.0", Date: now.Add(-20 * time.Minute)},
+				}},
+			},
+			expected: []helmFindingDatum{
+				{Priority: 1, Severity: "High", Type: "StuckPending", Namespace: "app", Release: "web", Chart: "web", Revision: 1, Date: now.Add(-20 * time.Minute), Message: "release has been pending-install for 20m0s"},
+			},
+		},
+		{
+			name: "pending without history",
+			releases: []collector.HelmRelease{
+				{Name: "web", Namespace: "app", ChartName: "web", Status: release.StatusPendingRollback, Revision: 3},
+			},
+			expected: []helmFindingDatum{
+				{Priority: 1, Severity: "High", Type: "StuckPending", Namespace: "app", Release: "web", Chart: "web", Revision: 3, Message: "release is pending-rollback"},
+			},
+		},
+		{
+			name: "latest revision failed",
+			releases: []collector.HelmRelease{
+				{Name: "web", Namespace: "app", ChartName: "web", Status: release.StatusFailed, Revision: 2, History: []collector.HelmReleaseHistory{
+					{Revision: 1, Status: release.StatusSuperseded, AppVersion: "1.0", Date: now.Add(-3 * time.Hour)},
+					{Revision: 2, Status: release.StatusFailed, AppVersion: "1.0", Date: now.Add(-2 * time.Hour), Message: "Upgrade \"web\" failed: timed out waiting for the condition"},
+				}},
+			},
+			expected: []helmFindingDatum{
+				{Priority: 1, Severity: "High", Type: "LatestRevisionFailed", Namespace: "app", Release: "web", Chart: "web", Revision: 2, Date: now.Add(-2 * time.Hour), Message: "latest revision failed: Upgrade \"web\" failed: timed out waiting for the condition"},
+			},
+		},
+		{
+			name: "rollbacks inside and outside the window",
+			releases: []collector.HelmRelease{
+				{Name: "web", Namespace: "app", ChartName: "web", Status: release.StatusDeployed, Revision: 4, History: []collector.HelmReleaseHistory{
+					{Revision: 1, Status: release.StatusSuperseded, AppVersion: "1.0", Date: now.Add(-4 * time.Hour)},
+					{Revision: 2, Status: release.StatusSuperseded, AppVersion: "1.0", Date: now.Add(-3 * time.Hour), Message: "Rollback to 1"},
+					{Revision: 3, Status: release.StatusSuperseded, AppVersion: "1.0", Date: now.Add(-20 * time.Minute), Message: "Upgrade complete"},
+					{Revision: 4, Status: release.StatusDeployed, AppVersion: "1.0", Date: now.Add(-10 * time.Minute), Message: "Rollback to 2"},
+				}},
+			},
+			expected: []helmFindingDatum{
+				{Priority: 2, Severity: "Medium", Type: "RecentRollback", Namespace: "app", Release: "web", Chart: "web", Revision: 4, Date: now.Add(-10 * time.Minute), Message: "revision 4: Rollback to 2"},
+			},
+		},
+		{
+			name: "findings sorted by priority across releases",
+			releases: []collector.HelmRelease{
+				{Name: "api", Namespace: "app", ChartName: "api", Status: release.StatusDeployed, Revision: 2, History: []collector.HelmReleaseHistory{
+					{Revision: 1, Status: release.StatusSuperseded, AppVersion: "1.0", Date: now.Add(-2 * time.Hour)},
+					{Revision: 2, Status: release.StatusDeployed, AppVersion: "1.1", Date: now.Add(-10 * time.Minute)},
+				}},
+				{Name: "web", Namespace: "app", ChartName: "web", Status: release.StatusFailed, Revision: 1, History: []collector.HelmReleaseHistory{
+					{Revision: 1, Status: release.StatusFailed, AppVersion: "2.0", Date: now.Add(-5 * time.Minute)},
+				}},
+			},
+			expected: []helmFindingDatum{
+				{Priority: 1, Severity: "High", Type: "LatestRevisionFailed", Namespace: "app", Release: "web", Chart: "web", Revision: 1, Date: now.Add(-5 * time.Minute), Message: "latest revision failed"},
+				{Priority: 3, Severity: "Low", Type: "AppVersionChanged", Namespace: "app", Release: "api", Chart: "api", Revision: 2, Date: now.Add(-10 * time.Minute), Message: "app version changed from 1.0 to 1.1 within the last 1h0m0s"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := getHelmFindings(tt.releases, now, window)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("unexpected findings:\nexpected %+v\nfound    %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetHelmAppVersionChanges(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	windowStart := now.Add(-time.Hour)
+
+	tests := []struct {
+		name             string
+		history          []collector.HelmReleaseHistory
+		expectedVersions []string
+		expectedRevision int
+	}{
+		{
+			name:             "no history",
+			history:          []collector.HelmReleaseHistory{},
+			expectedVersions: []string{},
+		},
+		{
+			name: "only the baseline before the window",
+			history: []collector.HelmReleaseHistory{
+				{Revision: 1, AppVersion: "1.0", Date: now.Add(-3 * time.Hour)},
+				{Revision: 2, AppVersion: "1.1", Date: now.Add(-2 * time.Hour)},
+			},
+			expectedVersions: []string{"1.1"},
+		},
+		{
+			name: "baseline is the last revision before the window",
+			history: []collector.HelmReleaseHistory{
+				{Revision: 1, AppVersion: "1.0", Date: now.Add(-3 * time.Hour)},
+				{Revision: 2, AppVersion: "1.1", Date: now.Add(-2 * time.Hour)},
+				{Revision: 3, AppVersion: "1.2", Date: now.Add(-30 * time.Minute)},
+			},
+			expectedVersions: []string{"1.1", "1.2"},
+			expectedRevision: 3,
+		},
+		{
+			name: "unchanged app version within the window",
+			history: []collector.HelmReleaseHistory{
+				{Revision: 1, AppVersion: "1.0", Date: now.Add(-2 * time.Hour)},
+				{Revision: 2, AppVersion: "1.0", Date: now.Add(-30 * time.Minute)},
+				{Revision: 3, AppVersion: "1.0", Date: now.Add(-10 * time.Minute)},
+			},
+			expectedVersions: []string{"1.0"},
+		},
+		{
+			name: "change and revert within the window",
+			history: []collector.HelmReleaseHistory{
+				{Revision: 1, AppVersion: "1.0", Date: now.Add(-40 * time.Minute)},
+				{Revision: 2, AppVersion: "1.1", Date: now.Add(-30 * time.Minute)},
+				{Revision: 3, AppVersion: "1.0", Date: now.Add(-10 * time.Minute)},
+			},
+			expectedVersions: []string{"1.0", "1.1", "1.0"},
+			expectedRevision: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, revision, _ := getHelmAppVersionChanges(tt.history, windowStart)
+			if !reflect.DeepEqual(versions, tt.expectedVersions) {
+				t.Errorf("unexpected app versions: expected %v, found %v", tt.expectedVersions, versions)
+			}
+			if revision != tt.expectedRevision {
+				t.Errorf("unexpected revision: expected %d, found %d", tt.expectedRevision, revision)
+			}
+		})
+	}
+}
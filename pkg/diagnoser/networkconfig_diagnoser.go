@@ -21,19 +21,21 @@ type networkConfigDiagnosticDatum struct {
 
 // NetworkConfigDiagnoser defines a NetworkConfig Diagnoser struct
 type NetworkConfigDiagnoser struct {
-	runtimeInfo         *utils.RuntimeInfo
-	dnsCollector        *collector.DNSCollector
-	kubeletCmdCollector *collector.KubeletCmdCollector
-	data                map[string]string
+	runtimeInfo            *utils.RuntimeInfo
+	dnsCollector           *collector.DNSCollector
+	kubeletCmdCollector    *collector.KubeletCmdCollector
+	kubeletConfigCollector *collector.KubeletConfigCollector
+	data                   map[string]string
 }
 
 // NewNetworkConfigDiagnoser is a constructor
-func NewNetworkConfigDiagnoser(runtimeInfo *utils.RuntimeInfo, dnsCollector *collector.DNSCollector, kubeletCmdCollector *collector.KubeletCmdCollector) *NetworkConfigDiagnoser {
+func NewNetworkConfigDiagnoser(runtimeInfo *utils.RuntimeInfo, dnsCollector *collector.DNSCollector, kubeletCmdCollector *collector.KubeletCmdCollector, kubeletConfigCollector *collector.KubeletConfigCollector) *NetworkConfigDiagnoser {
 	return &NetworkConfigDiagnoser{
-		runtimeInfo:         runtimeInfo,
-		dnsCollector:        dnsCollector,
-		kubeletCmdCollector: kubeletCmdCollector,
-		data:                make(map[string]string),
+		runtimeInfo:            runtimeInfo,
+		dnsCollector:           dnsCollector,
+		kubeletCmdCollector:    kubeletCmdCollector,
+		kubeletConfigCollector: kubeletConfigCollector,
+		data:                   make(map[string]string),
 	}
 }
 
@@ -66,6 +68,12 @@ func (diagnoser *NetworkConfigDiagnoser) Diagnose() error {
 		}
 	}
 
+	// Newer kubelets take most of their settings from a config file rather than command-line flags,
+	// in which case the effective value is only available from the kubelet configuration.
+	if networkConfigDiagnosticData.MaxPodsPerNode == 0 && diagnoser.kubeletConfigCollector.KubeletConfig != nil {
+		networkConfigDiagnosticData.MaxPodsPerNode = int(diagnoser.kubeletConfigCollector.KubeletConfig.MaxPods)
+	}
+
 	dataBytes, err := json.Marshal(networkConfigDiagnosticData)
 	if err != nil {
 		return fmt.Errorf("marshal data from NetworkConfig Diagnoser: %w", err)
@@ -77,15 +85,7 @@ func (diagnoser *NetworkConfigDiagnoser) Diagnose() error {
 }
 
 func (diagnoser *NetworkConfigDiagnoser) getDns(confFileContent string) []string {
-	var dns []string
-	words := strings.Split(confFileContent, " ")
-	for i := range words {
-		if words[i] == "nameserver" {
-			dns = append(dns, strings.TrimSuffix(words[i+1], "\n"))
-		}
-	}
-
-	return dns
+	return utils.ParseResolvConf(confFileContent).Nameservers
 }
 
 func (collector *NetworkConfigDiagnoser) GetData() map[string]interfaces.DataValue {
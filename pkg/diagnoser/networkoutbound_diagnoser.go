@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,6 +14,10 @@ import (
 	"github.com/Azure/aks-periscope/pkg/utils"
 )
 
+// minimumSampleGap is the largest gap between two samples with the same status for them to be considered
+// part of the same period, when the configured sampling interval is smaller than this.
+const minimumSampleGap = 5 * time.Second
+
 type networkOutboundDiagnosticDatum struct {
 	HostName string    `json:"HostName"`
 	Type     string    `json:"Type"`
@@ -21,6 +26,15 @@ type networkOutboundDiagnosticDatum struct {
 	Status   string    `json:"Status"`
 }
 
+type networkOutboundSummaryDatum struct {
+	HostName      string `json:"HostName"`
+	Type          string `json:"Type"`
+	Samples       int    `json:"Samples"`
+	Failures      int    `json:"Failures"`
+	StatusChanges int    `json:"StatusChanges"`
+	Flapping      bool   `json:"Flapping"`
+}
+
 // NetworkOutboundDiagnoser defines a NetworkOutbound Diagnoser struct
 type NetworkOutboundDiagnoser struct {
 	runtimeInfo              *utils.RuntimeInfo
@@ -44,17 +58,27 @@ func (collector *NetworkOutboundDiagnoser) GetName() string {
 // Diagnose implements the interface method
 func (diagnoser *NetworkOutboundDiagnoser) Diagnose() error {
 	outboundDiagnosticData := []networkOutboundDiagnosticDatum{}
+	outboundSummaryData := []networkOutboundSummaryDatum{}
+
+	maxGap := 2 * diagnoser.runtimeInfo.SamplingInterval
+	if maxGap < minimumSampleGap {
+		maxGap = minimumSampleGap
+	}
+
+	collectorData := diagnoser.networkOutboundCollector.GetData()
+	outboundTypes := make([]string, 0, len(collectorData))
+	for outboundType := range collectorData {
+		outboundTypes = append(outboundTypes, outboundType)
+	}
+	sort.Strings(outboundTypes)
 
-	for _, value := range diagnoser.networkOutboundCollector.GetData() {
+	for _, outboundType := range outboundTypes {
 		dataPoint := networkOutboundDiagnosticDatum{HostName: diagnoser.runtimeInfo.HostNodeName}
+		summary := networkOutboundSummaryDatum{HostName: diagnoser.runtimeInfo.HostNodeName, Type: outboundType}
 
-		// TODO: We could read this directly from the collector object, rather than deserializing it from the output.
-		// However, this diagnoser no longer does what it was originally intended to do, and as it is it doesn't
-		// really provide any value.
-		// The NetworkOutboundCollector used to append to a file that could potentially contain multiple status values
-		// over time, and this diagnoser would aggregate this data into timestamps for each status change. But now
-		// its output is effectively identical to that of the collector.
-		data, err := utils.GetContent(func() (io.ReadCloser, error) { return value.GetReader() })
+		// The NetworkOutboundCollector stores one line of JSON per sample, and this aggregates consecutive
+		// samples into periods with the same status.
+		data, err := utils.GetContent(func() (io.ReadCloser, error) { return collectorData[outboundType].GetReader() })
 
 		if err != nil {
 			log.Printf("Retrieving data failed: %v", err)
@@ -70,14 +94,20 @@ func (diagnoser *NetworkOutboundDiagnoser) Diagnose() error {
 				continue
 			}
 
+			summary.Samples++
+			if outboundDatum.Status != "Connected" {
+				summary.Failures++
+			}
+
 			if dataPoint.Start.IsZero() {
 				setDataPoint(&outboundDatum, &dataPoint)
 			} else {
 				if outboundDatum.Status != dataPoint.Status {
 					outboundDiagnosticData = append(outboundDiagnosticData, dataPoint)
 					setDataPoint(&outboundDatum, &dataPoint)
+					summary.StatusChanges++
 				} else {
-					if int(outboundDatum.TimeStamp.Sub(dataPoint.End).Seconds()) > 5 {
+					if outboundDatum.TimeStamp.Sub(dataPoint.End) > maxGap {
 						outboundDiagnosticData = append(outboundDiagnosticData, dataPoint)
 						setDataPoint(&outboundDatum, &dataPoint)
 					} else {
@@ -90,6 +120,11 @@ func (diagnoser *NetworkOutboundDiagnoser) Diagnose() error {
 		if !dataPoint.Start.IsZero() {
 			outboundDiagnosticData = append(outboundDiagnosticData, dataPoint)
 		}
+
+		// A connection that both succeeded and failed within the window is intermittent, which usually points
+		// to something different (e.g. SNAT port exhaustion) from one that consistently fails.
+		summary.Flapping = summary.StatusChanges > 0 && summary.Failures > 0 && summary.Failures < summary.Samples
+		outboundSummaryData = append(outboundSummaryData, summary)
 	}
 
 	dataBytes, err := json.Marshal(outboundDiagnosticData)
@@ -99,6 +134,13 @@ func (diagnoser *NetworkOutboundDiagnoser) Diagnose() error {
 
 	diagnoser.data["networkoutbound"] = string(dataBytes)
 
+	summaryBytes, err := json.Marshal(outboundSummaryData)
+	if err != nil {
+		return fmt.Errorf("marshal summary from NetworkOutbound Diagnoser: %w", err)
+	}
+
+	diagnoser.data["networkoutbound_summary"] = string(summaryBytes)
+
 	return nil
 }
 
@@ -0,0 +1,126 @@
+package diagnoser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/collector"
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// quotaPressureThreshold is the fraction of a hard limit at which a quota is considered under pressure.
+const quotaPressureThreshold = 0.9
+
+type resourceQuotaPressureDatum struct {
+	Namespace string  `json:"Namespace"`
+	Quota     string  `json:"Quota"`
+	Resource  string  `json:"Resource"`
+	Used      string  `json:"Used"`
+	Hard      string  `json:"Hard"`
+	Ratio     float64 `json:"Ratio"`
+}
+
+type resourceQuotaExceededEventDatum struct {
+	Namespace     string    `json:"Namespace"`
+	Kind          string    `json:"Kind"`
+	Name          string    `json:"Name"`
+	Message       string    `json:"Message"`
+	Count         int32     `json:"Count"`
+	LastTimestamp time.Time `json:"LastTimestamp"`
+}
+
+type resourceQuotaDiagnosticDatum struct {
+	NamespacesUnderPressure []string                          `json:"NamespacesUnderPressure"`
+	QuotasUnderPressure     []resourceQuotaPressureDatum      `json:"QuotasUnderPressure"`
+	ExceededQuotaEvents     []resourceQuotaExceededEventDatum `json:"ExceededQuotaEvents"`
+}
+
+// ResourceQuotaDiagnoser defines a ResourceQuota Diagnoser struct
+type ResourceQuotaDiagnoser struct {
+	runtimeInfo            *utils.RuntimeInfo
+	resourceQuotaCollector *collector.ResourceQuotaCollector
+	data                   map[string]string
+}
+
+// NewResourceQuotaDiagnoser is a constructor
+func NewResourceQuotaDiagnoser(runtimeInfo *utils.RuntimeInfo, resourceQuotaCollector *collector.ResourceQuotaCollector) *ResourceQuotaDiagnoser {
+	return &ResourceQuotaDiagnoser{
+		runtimeInfo:            runtimeInfo,
+		resourceQuotaCollector: resourceQuotaCollector,
+		data:                   make(map[string]string),
+	}
+}
+
+func (diagnoser *ResourceQuotaDiagnoser) GetName() string {
+	return "resourcequota"
+}
+
+// Diagnose implements the interface method
+func (diagnoser *ResourceQuotaDiagnoser) Diagnose() error {
+	quotas := diagnoser.resourceQuotaCollector.Quotas
+	if quotas == nil {
+		return fmt.Errorf("no resource quotas were collected")
+	}
+
+	resourceQuotaDiagnosticData := resourceQuotaDiagnosticDatum{
+		NamespacesUnderPressure: []string{},
+		QuotasUnderPressure:     []resourceQuotaPressureDatum{},
+		ExceededQuotaEvents:     getExceededQuotaEvents(diagnoser.resourceQuotaCollector.FailedCreateEvents),
+	}
+
+	for _, quota := range quotas {
+		for _, item := range quota.Items {
+			if item.Ratio < quotaPressureThreshold {
+				continue
+			}
+
+			resourceQuotaDiagnosticData.QuotasUnderPressure = append(resourceQuotaDiagnosticData.QuotasUnderPressure, resourceQuotaPressureDatum{
+				Namespace: quota.Namespace,
+				Quota:     quota.Name,
+				Resource:  item.Resource,
+				Used:      item.Used,
+				Hard:      item.Hard,
+				Ratio:     item.Ratio,
+			})
+			if !utils.Contains(resourceQuotaDiagnosticData.NamespacesUnderPressure, quota.Namespace) {
+				resourceQuotaDiagnosticData.NamespacesUnderPressure = append(resourceQuotaDiagnosticData.NamespacesUnderPressure, quota.Namespace)
+			}
+		}
+	}
+
+	dataBytes, err := json.Marshal(resourceQuotaDiagnosticData)
+	if err != nil {
+		return fmt.Errorf("marshal data from ResourceQuota Diagnoser: %w", err)
+	}
+
+	diagnoser.data["resourcequota"] = string(dataBytes)
+
+	return nil
+}
+
+func (diagnoser *ResourceQuotaDiagnoser) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(diagnoser.data)
+}
+
+// getExceededQuotaEvents returns the FailedCreate events caused by the quota admission plugin rejecting a request.
+func getExceededQuotaEvents(events []collector.KubeEvent) []resourceQuotaExceededEventDatum {
+	result := []resourceQuotaExceededEventDatum{}
+	for _, event := range events {
+		if !strings.Contains(event.Message, "exceeded quota") {
+			continue
+		}
+
+		result = append(result, resourceQuotaExceededEventDatum{
+			Namespace:     event.Object.Namespace,
+			Kind:          event.Object.Kind,
+			Name:          event.Object.Name,
+			Message:       event.Message,
+			Count:         event.Count,
+			LastTimestamp: event.LastTimestamp,
+		})
+	}
+	return result
+}
@@ -0,0 +1,182 @@
+package diagnoser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/collector"
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+type systemPerfNodePeak struct {
+	NodeName              string    `json:"NodeName"`
+	PeakCPUUsage          int64     `json:"PeakCPUUsage"`
+	PeakCPUUtilization    float64   `json:"PeakCPUUtilization"`
+	PeakCPUTime           time.Time `json:"PeakCPUTime"`
+	PeakMemoryUsage       int64     `json:"PeakMemoryUsage"`
+	PeakMemoryUtilization float64   `json:"PeakMemoryUtilization"`
+	PeakMemoryTime        time.Time `json:"PeakMemoryTime"`
+	OverAllocatableCount  int       `json:"OverAllocatableCount"`
+}
+
+type systemPerfContainerPeak struct {
+	Namespace          string    `json:"Namespace"`
+	PodName            string    `json:"PodName"`
+	ContainerName      string    `json:"ContainerName"`
+	NodeName           string    `json:"NodeName"`
+	CPULimit           int64     `json:"CPULimit"`
+	MemoryLimit        int64     `json:"MemoryLimit"`
+	PeakCPUUsage       int64     `json:"PeakCPUUsage"`
+	PeakCPUTime        time.Time `json:"PeakCPUTime"`
+	PeakMemoryUsage    int64     `json:"PeakMemoryUsage"`
+	PeakMemoryTime     time.Time `json:"PeakMemoryTime"`
+	AtCPULimitCount    int       `json:"AtCPULimitCount"`
+	AtMemoryLimitCount int       `json:"AtMemoryLimitCount"`
+}
+
+type systemPerfDiagnosticDatum struct {
+	HostName    string                    `json:"HostName"`
+	Samples     int                       `json:"Samples"`
+	WindowStart time.Time                 `json:"WindowStart"`
+	WindowEnd   time.Time                 `json:"WindowEnd"`
+	Nodes       []systemPerfNodePeak      `json:"Nodes"`
+	Containers  []systemPerfContainerPeak `json:"Containers"`
+}
+
+// SystemPerfDiagnoser defines a SystemPerf Diagnoser struct
+type SystemPerfDiagnoser struct {
+	runtimeInfo         *utils.RuntimeInfo
+	systemPerfCollector *collector.SystemPerfCollector
+	data                map[string]string
+}
+
+// NewSystemPerfDiagnoser is a constructor
+func NewSystemPerfDiagnoser(runtimeInfo *utils.RuntimeInfo, systemPerfCollector *collector.SystemPerfCollector) *SystemPerfDiagnoser {
+	return &SystemPerfDiagnoser{
+		runtimeInfo:         runtimeInfo,
+		systemPerfCollector: systemPerfCollector,
+		data:                make(map[string]string),
+	}
+}
+
+func (diagnoser *SystemPerfDiagnoser) GetName() string {
+	return "systemperf"
+}
+
+// Diagnose implements the interface method
+func (diagnoser *SystemPerfDiagnoser) Diagnose() error {
+	// The SystemPerf collector does not run on connected clusters, so there is nothing to diagnose there.
+	if err := diagnoser.systemPerfCollector.CheckSupported(); err != nil {
+		log.Printf("Skipping system performance diagnosis: %v", err)
+		return nil
+	}
+
+	samples := diagnoser.systemPerfCollector.Samples
+	if len(samples) == 0 {
+		return fmt.Errorf("no system performance samples were collected")
+	}
+
+	systemPerfDiagnosticData := systemPerfDiagnosticDatum{
+		HostName:    diagnoser.runtimeInfo.HostNodeName,
+		Samples:     len(samples),
+		WindowStart: samples[0].TimeStamp,
+		WindowEnd:   samples[len(samples)-1].TimeStamp,
+		Nodes:       []systemPerfNodePeak{},
+		Containers:  []systemPerfContainerPeak{},
+	}
+
+	nodePeaks := map[string]*systemPerfNodePeak{}
+	containerPeaks := map[string]*systemPerfContainerPeak{}
+	for _, sample := range samples {
+		for _, node := range sample.Nodes {
+			peak, ok := nodePeaks[node.NodeName]
+			if !ok {
+				peak = &systemPerfNodePeak{NodeName: node.NodeName, PeakCPUUsage: -1, PeakMemoryUsage: -1}
+				nodePeaks[node.NodeName] = peak
+			}
+			if node.CPUUsage > peak.PeakCPUUsage {
+				peak.PeakCPUUsage = node.CPUUsage
+				peak.PeakCPUUtilization = node.CPUUtilization
+				peak.PeakCPUTime = sample.TimeStamp
+			}
+			if node.MemoryUsage > peak.PeakMemoryUsage {
+				peak.PeakMemoryUsage = node.MemoryUsage
+				peak.PeakMemoryUtilization = node.MemoryUtilization
+				peak.PeakMemoryTime = sample.TimeStamp
+			}
+			if node.OverAllocatable {
+				peak.OverAllocatableCount++
+			}
+		}
+
+		for _, container := range sample.Pods {
+			key := fmt.Sprintf("%s/%s/%s", container.Namespace, container.PodName, container.ContainerName)
+			peak, ok := containerPeaks[key]
+			if !ok {
+				peak = &systemPerfContainerPeak{
+					Namespace:       container.Namespace,
+					PodName:         container.PodName,
+					ContainerName:   container.ContainerName,
+					NodeName:        container.NodeName,
+					CPULimit:        container.CPULimit,
+					MemoryLimit:     container.MemoryLimit,
+					PeakCPUUsage:    -1,
+					PeakMemoryUsage: -1,
+				}
+				containerPeaks[key] = peak
+			}
+			if container.CPUUsage > peak.PeakCPUUsage {
+				peak.PeakCPUUsage = container.CPUUsage
+				peak.PeakCPUTime = sample.TimeStamp
+			}
+			if container.MemoryUsage > peak.PeakMemoryUsage {
+				peak.PeakMemoryUsage = container.MemoryUsage
+				peak.PeakMemoryTime = sample.TimeStamp
+			}
+			if container.AtCPULimit {
+				peak.AtCPULimitCount++
+			}
+			if container.AtMemoryLimit {
+				peak.AtMemoryLimitCount++
+			}
+		}
+	}
+
+	for _, peak := range nodePeaks {
+		systemPerfDiagnosticData.Nodes = append(systemPerfDiagnosticData.Nodes, *peak)
+	}
+	sort.Slice(systemPerfDiagnosticData.Nodes, func(i, j int) bool {
+		return systemPerfDiagnosticData.Nodes[i].NodeName < systemPerfDiagnosticData.Nodes[j].NodeName
+	})
+
+	for _, peak := range containerPeaks {
+		systemPerfDiagnosticData.Containers = append(systemPerfDiagnosticData.Containers, *peak)
+	}
+	sort.Slice(systemPerfDiagnosticData.Containers, func(i, j int) bool {
+		a, b := systemPerfDiagnosticData.Containers[i], systemPerfDiagnosticData.Containers[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.PodName != b.PodName {
+			return a.PodName < b.PodName
+		}
+		return a.ContainerName < b.ContainerName
+	})
+
+	dataBytes, err := json.Marshal(systemPerfDiagnosticData)
+	if err != nil {
+		return fmt.Errorf("marshal data from SystemPerf Diagnoser: %w", err)
+	}
+
+	diagnoser.data["systemperf_peaks"] = string(dataBytes)
+
+	return nil
+}
+
+func (diagnoser *SystemPerfDiagnoser) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(diagnoser.data)
+}
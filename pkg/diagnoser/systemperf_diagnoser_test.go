@@ -0,0 +1,29 @@
+package diagnoser
+
+import (
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/collector"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestSystemPerfDiagnoserSkipsUnsupportedCollector(t *testing.T) {
+	runtimeInfo := &utils.RuntimeInfo{CollectorList: []string{"connectedCluster"}}
+	d := NewSystemPerfDiagnoser(runtimeInfo, collector.NewSystemPerfCollector(nil, runtimeInfo))
+
+	if err := d.Diagnose(); err != nil {
+		t.Errorf("Diagnose() error = %v, wantErr false", err)
+	}
+	if len(d.GetData()) != 0 {
+		t.Errorf("expected no data, found %d items", len(d.GetData()))
+	}
+}
+
+func TestSystemPerfDiagnoserNoSamples(t *testing.T) {
+	runtimeInfo := &utils.RuntimeInfo{CollectorList: []string{}}
+	d := NewSystemPerfDiagnoser(runtimeInfo, collector.NewSystemPerfCollector(nil, runtimeInfo))
+
+	if err := d.Diagnose(); err == nil {
+		t.Errorf("Diagnose() expected an error when no samples were collected")
+	}
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+// CertificateInfo is a summary of an X.509 certificate, without any key material.
+type CertificateInfo struct {
+	Subject        string    `json:"subject"`
+	Issuer         string    `json:"issuer"`
+	SerialNumber   string    `json:"serialNumber"`
+	DNSNames       []string  `json:"dnsNames"`
+	IPAddresses    []string  `json:"ipAddresses"`
+	URIs           []string  `json:"uris"`
+	EmailAddresses []string  `json:"emailAddresses"`
+	IsCA           bool      `json:"isCA"`
+	NotBefore      time.Time `json:"notBefore"`
+	NotAfter       time.Time `json:"notAfter"`
+}
+
+// ParsePEMCertificates parses all the CERTIFICATE blocks in PEM-encoded data, ignoring any other blocks such as keys.
+func ParsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing certificate %d: %w", len(certs)+1, err)
+		}
+		certs = append(certs, cert)
+	}
+
+	return certs, nil
+}
+
+// GetCertificateInfos parses the certificates in PEM-encoded data and summarizes each of them.
+func GetCertificateInfos(data []byte) ([]CertificateInfo, error) {
+	certs, err := ParsePEMCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []CertificateInfo{}
+	for _, cert := range certs {
+		infos = append(infos, GetCertificateInfo(cert))
+	}
+
+	return infos, nil
+}
+
+// GetCertificateInfo summarizes a certificate, including all its subject alternative names.
+func GetCertificateInfo(cert *x509.Certificate) CertificateInfo {
+	dnsNames := cert.DNSNames
+	if dnsNames == nil {
+		dnsNames = []string{}
+	}
+
+	ipAddresses := []string{}
+	for _, ip := range cert.IPAddresses {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+
+	uris := []string{}
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	emailAddresses := cert.EmailAddresses
+	if emailAddresses == nil {
+		emailAddresses = []string{}
+	}
+
+	return CertificateInfo{
+		Subject:        cert.Subject.String(),
+		Issuer:         cert.Issuer.String(),
+		SerialNumber:   cert.SerialNumber.String(),
+		DNSNames:       dnsNames,
+		IPAddresses:    ipAddresses,
+		URIs:           uris,
+		EmailAddresses: emailAddresses,
+		IsCA:           cert.IsCA,
+		NotBefore:      cert.NotBefore.UTC(),
+		NotAfter:       cert.NotAfter.UTC(),
+	}
+}
+
+// GetEarliestExpiry returns the earliest expiry time of the given certificates, and false if there are none.
+func GetEarliestExpiry(infos []CertificateInfo) (time.Time, bool) {
+	var earliest time.Time
+	for i, info := range infos {
+		if i == 0 || info.NotAfter.Before(earliest) {
+			earliest = info.NotAfter
+		}
+	}
+
+	return earliest, len(infos) > 0
+}
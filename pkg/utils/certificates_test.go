@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func createTestCertificatePEM(t *testing.T, commonName string, serial int64, notAfter time.Time) []byte {
+	return createTestCertificatePEMWithSANs(t, commonName, serial, notAfter, func(*x509.Certificate) {})
+}
+
+func createTestCertificatePEMWithSANs(t *testing.T, commonName string, serial int64, notAfter time.Time, addSANs func(*x509.Certificate)) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{commonName},
+		NotBefore:             notAfter.Add(-24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	addSANs(template)
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("error creating certificate: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestGetCertificateInfos(t *testing.T) {
+	notAfter1 := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter2 := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	data := createTestCertificatePEM(t, "webhook.default.svc", 1, notAfter1)
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("ignored")})...)
+	data = append(data, createTestCertificatePEM(t, "ca", 2, notAfter2)...)
+
+	infos, err := GetCertificateInfos(data)
+	if err != nil {
+		t.Fatalf("GetCertificateInfos() error = %v", err)
+	}
+
+	expected := []CertificateInfo{
+		{
+			Subject:        "CN=webhook.default.svc",
+			Issuer:         "CN=webhook.default.svc",
+			SerialNumber:   "1",
+			DNSNames:       []string{"webhook.default.svc"},
+			IPAddresses:    []string{},
+			URIs:           []string{},
+			EmailAddresses: []string{},
+			IsCA:           true,
+			NotBefore:      notAfter1.Add(-24 * time.Hour),
+			NotAfter:       notAfter1,
+		},
+		{
+			Subject:        "CN=ca",
+			Issuer:         "CN=ca",
+			SerialNumber:   "2",
+			DNSNames:       []string{"ca"},
+			IPAddresses:    []string{},
+			URIs:           []string{},
+			EmailAddresses: []string{},
+			IsCA:           true,
+			NotBefore:      notAfter2.Add(-24 * time.Hour),
+			NotAfter:       notAfter2,
+		},
+	}
+	if !reflect.DeepEqual(infos, expected) {
+		t.Errorf("unexpected certificate infos:\nexpected %+v\nfound    %+v", expected, infos)
+	}
+
+	earliest, ok := GetEarliestExpiry(infos)
+	if !ok || !earliest.Equal(notAfter2) {
+		t.Errorf("unexpected earliest expiry: expected %v, found %v (%v)", notAfter2, earliest, ok)
+	}
+}
+
+func TestGetCertificateInfoSANs(t *testing.T) {
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	spiffeID, _ := url.Parse("spiffe://cluster.local/ns/app/sa/web")
+
+	data := createTestCertificatePEMWithSANs(t, "aks-nodepool1-0", 1, notAfter, func(cert *x509.Certificate) {
+		cert.IPAddresses = []net.IP{net.ParseIP("10.240.0.4"), net.ParseIP("fd00::4")}
+		cert.URIs = []*url.URL{spiffeID}
+		cert.EmailAddresses = []string{"admin@example.com"}
+	})
+
+	infos, err := GetCertificateInfos(data)
+	if err != nil || len(infos) != 1 {
+		t.Fatalf("GetCertificateInfos() = %v, %v", infos, err)
+	}
+
+	info := infos[0]
+	if expected := []string{"aks-nodepool1-0"}; !reflect.DeepEqual(info.DNSNames, expected) {
+		t.Errorf("unexpected DNS names: expected %v, found %v", expected, info.DNSNames)
+	}
+	if expected := []string{"10.240.0.4", "fd00::4"}; !reflect.DeepEqual(info.IPAddresses, expected) {
+		t.Errorf("unexpected IP addresses: expected %v, found %v", expected, info.IPAddresses)
+	}
+	if expected := []string{"spiffe://cluster.local/ns/app/sa/web"}; !reflect.DeepEqual(info.URIs, expected) {
+		t.Errorf("unexpected URIs: expected %v, found %v", expected, info.URIs)
+	}
+	if expected := []string{"admin@example.com"}; !reflect.DeepEqual(info.EmailAddresses, expected) {
+		t.Errorf("unexpected email addresses: expected %v, found %v", expected, info.EmailAddresses)
+	}
+}
+
+func TestGetCertificateInfosErrors(t *testing.T) {
+	infos, err := GetCertificateInfos([]byte("not a certificate"))
+	if err != nil || len(infos) != 0 {
+		t.Errorf("expected no certificates and no error for non-PEM data, found %v, %v", infos, err)
+	}
+
+	_, err = GetCertificateInfos(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")}))
+	if err == nil {
+		t.Errorf("expected error for invalid certificate")
+	}
+
+	if _, ok := GetEarliestExpiry([]CertificateInfo{}); ok {
+		t.Errorf("expected no earliest expiry for empty certificates")
+	}
+}
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	// Time zone data is embedded, because the container image may not include it.
+	_ "time/tzdata"
+)
+
+// cronMaxSearch bounds the search for the next time a schedule fires, for schedules such as "0 0 30 2 *" that never do.
+const cronMaxSearch = 5 * 365 * 24 * time.Hour
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// CronSchedule is a parsed standard five-field cron schedule, as used by Kubernetes CronJobs.
+type CronSchedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// When both day fields are restricted, a time matches if either of them does.
+	dayOfMonthStar bool
+	dayOfWeekStar  bool
+	// The time zone from a CRON_TZ= or TZ= prefix, or nil to use the location of the times passed to Next.
+	location *time.Location
+	// The interval of an @every schedule, which fires at a constant delay rather than matching the fields.
+	every time.Duration
+}
+
+// ParseCronSchedule parses a schedule of the form "minute hour day-of-month month day-of-week", supporting
+// lists, ranges, steps, month and day names, the @hourly/@daily/@weekly/@monthly/@yearly macros, @every <duration>,
+// and a CRON_TZ= or TZ= time zone prefix, as the CronJob controller does.
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	var location *time.Location
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		i := strings.IndexFunc(spec, unicode.IsSpace)
+		if i < 0 {
+			return nil, fmt.Errorf("missing schedule after time zone in cron schedule '%s'", spec)
+		}
+		zone := spec[strings.Index(spec, "=")+1 : i]
+		var err error
+		if location, err = time.LoadLocation(zone); err != nil {
+			return nil, fmt.Errorf("invalid time zone '%s': %w", zone, err)
+		}
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration in cron schedule '%s': %w", spec, err)
+		}
+		// The controller's cron library runs these at whole seconds, and at most once a second.
+		every = every.Truncate(time.Second)
+		if every < time.Second {
+			every = time.Second
+		}
+		return &CronSchedule{location: location, every: every}, nil
+	}
+
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in cron schedule '%s', found %d", spec, len(fields))
+	}
+
+	schedule := &CronSchedule{location: location}
+	var err error
+	if schedule.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute: %w", err)
+	}
+	if schedule.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour: %w", err)
+	}
+	if schedule.dayOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day of month: %w", err)
+	}
+	if schedule.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("invalid month: %w", err)
+	}
+	// Both 0 and 7 are Sunday.
+	if schedule.dayOfWeek, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("invalid day of week: %w", err)
+	}
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1
+	}
+
+	schedule.dayOfMonthStar = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[2], "?")
+	schedule.dayOfWeekStar = strings.HasPrefix(fields[4], "*") || strings.HasPrefix(fields[4], "?")
+
+	return schedule, nil
+}
+
+// Next returns the first time after t that the schedule fires (for @every schedules, the interval after t), in the
+// schedule's time zone if it has one and t's location otherwise, or the zero time if it does not fire within five years.
+func (schedule *CronSchedule) Next(t time.Time) time.Time {
+	if schedule.location != nil {
+		t = t.In(schedule.location)
+	}
+
+	if schedule.every > 0 {
+		return t.Truncate(time.Second).Add(schedule.every)
+	}
+
+	limit := t.Add(cronMaxSearch)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		if schedule.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !schedule.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if schedule.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if schedule.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (schedule *CronSchedule) matchesDay(t time.Time) bool {
+	domMatch := schedule.dayOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := schedule.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if schedule.dayOfMonthStar || schedule.dayOfWeekStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps into a bit set.
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in '%s'", part)
+			}
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = parseCronValue(bounds[0], names); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(bounds[1], names); err != nil {
+				return 0, err
+			}
+		default:
+			value, err := parseCronValue(rangePart, names)
+			if err != nil {
+				return 0, err
+			}
+			start = value
+			// A single value with a step, such as "5/15", runs from the value to the maximum.
+			if step == 1 {
+				end = value
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("'%s' is outside the range %d-%d", part, min, max)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseCronValue(value string, names map[string]int) (int, error) {
+	if number, ok := names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", value)
+	}
+	return number, nil
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// A Friday.
+	from := time.Date(2021, 10, 1, 12, 34, 56, 0, time.UTC)
+
+	tests := []struct {
+		schedule string
+		want     time.Time
+	}{
+		{schedule: "* * * * *", want: time.Date(2021, 10, 1, 12, 35, 0, 0, time.UTC)},
+		{schedule: "*/15 * * * *", want: time.Date(2021, 10, 1, 12, 45, 0, 0, time.UTC)},
+		{schedule: "5/30 * * * *", want: time.Date(2021, 10, 1, 12, 35, 0, 0, time.UTC)},
+		{schedule: "0 0 * * *", want: time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)},
+		{schedule: "@hourly", want: time.Date(2021, 10, 1, 13, 0, 0, 0, time.UTC)},
+		{schedule: "30 9 * * mon-fri", want: time.Date(2021, 10, 4, 9, 30, 0, 0, time.UTC)},
+		{schedule: "0 12 1,15 * *", want: time.Date(2021, 10, 15, 12, 0, 0, 0, time.UTC)},
+		{schedule: "0 0 1 JAN *", want: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{schedule: "0 0 * * 7", want: time.Date(2021, 10, 3, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted, either may match: the 13th, or any Friday.
+		{schedule: "0 18 13 * 5", want: time.Date(2021, 10, 1, 18, 0, 0, 0, time.UTC)},
+		{schedule: "0 0 30 2 *", want: time.Time{}},
+		// 9:00 in New York is 13:00 UTC during daylight saving time.
+		{schedule: "CRON_TZ=America/New_York 0 9 * * *", want: time.Date(2021, 10, 1, 13, 0, 0, 0, time.UTC)},
+		{schedule: "TZ=Asia/Tokyo 0 0 * * *", want: time.Date(2021, 10, 1, 15, 0, 0, 0, time.UTC)},
+		// @every fires at a constant interval after the last run, not on minute boundaries.
+		{schedule: "@every 90m", want: time.Date(2021, 10, 1, 14, 4, 56, 0, time.UTC)},
+		{schedule: "@every 1h30m45s", want: time.Date(2021, 10, 1, 14, 5, 41, 0, time.UTC)},
+		{schedule: "@every 100ms", want: time.Date(2021, 10, 1, 12, 34, 57, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(tt.schedule)
+			if err != nil {
+				t.Fatalf("ParseCronSchedule() error = %v", err)
+			}
+
+			got := schedule.Next(from)
+			if !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronScheduleErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"CRON_TZ=Not/AZone 0 0 * * *",
+		"TZ=UTC",
+		"@every",
+		"@every 5x",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			if _, err := ParseCronSchedule(tt); err == nil {
+				t.Errorf("expected error parsing '%s'", tt)
+			}
+		})
+	}
+}
@@ -37,16 +37,21 @@ type CommandOutputStreams struct {
 
 // IsAzureStackCloud returns true if the application is running on Azure Stack Cloud
 func IsAzureStackCloud(filePaths *KnownFilePaths) bool {
+	cloud := GetAzureCloud(filePaths)
+	return strings.EqualFold(cloud, AzureStackCloudName)
+}
+
+// GetAzureCloud returns the name of the Azure cloud from azure.json (e.g. AzurePublicCloud), or an empty string if unavailable
+func GetAzureCloud(filePaths *KnownFilePaths) string {
 	azureFile, err := os.ReadFile(filePaths.AzureJson)
 	if err != nil {
-		return false
+		return ""
 	}
 	var azure Azure
 	if err = json.Unmarshal([]byte(azureFile), &azure); err != nil {
-		return false
+		return ""
 	}
-	cloud := azure.Cloud
-	return strings.EqualFold(cloud, AzureStackCloudName)
+	return azure.Cloud
 }
 
 func CopyFile(source, destination string) error {
@@ -14,6 +14,9 @@ type KnownFilePaths struct {
 	AzureStackCertHost      string
 	AzureStackCertContainer string
 	NodeLogsList            string
+	CgroupRoot              string
+	PacketCapture           string
+	KubeletKubeconfig       string
 	Config                  string
 	Secret                  string
 }
@@ -22,12 +25,29 @@ type ConfigKey string
 type SecretKey string
 
 const (
-	CollectorListKey     ConfigKey = "COLLECTOR_LIST"
-	ContainerLogsListKey ConfigKey = "DIAGNOSTIC_CONTAINERLOGS_LIST"
-	KubeObjectsListKey   ConfigKey = "DIAGNOSTIC_KUBEOBJECTS_LIST"
-	NodeLogsLinuxKey     ConfigKey = "DIAGNOSTIC_NODELOGS_LIST_LINUX"
-	NodeLogsWindowsKey   ConfigKey = "DIAGNOSTIC_NODELOGS_LIST_WINDOWS"
-	RunIdKey             ConfigKey = "DIAGNOSTIC_RUN_ID"
+	CollectorListKey           ConfigKey = "COLLECTOR_LIST"
+	ContainerLogsListKey       ConfigKey = "DIAGNOSTIC_CONTAINERLOGS_LIST"
+	KubeObjectsListKey         ConfigKey = "DIAGNOSTIC_KUBEOBJECTS_LIST"
+	NodeLogsLinuxKey           ConfigKey = "DIAGNOSTIC_NODELOGS_LIST_LINUX"
+	NodeLogsWindowsKey         ConfigKey = "DIAGNOSTIC_NODELOGS_LIST_WINDOWS"
+	RunIdKey                   ConfigKey = "DIAGNOSTIC_RUN_ID"
+	SystemLogsListKey          ConfigKey = "DIAGNOSTIC_SYSTEMLOGS_LIST"
+	SamplingDurationKey        ConfigKey = "DIAGNOSTIC_SAMPLING_DURATION"
+	SamplingIntervalKey        ConfigKey = "DIAGNOSTIC_SAMPLING_INTERVAL"
+	OutboundTargetsKey         ConfigKey = "DIAGNOSTIC_NETWORKOUTBOUND_TARGETS"
+	DNSProbeNamesKey           ConfigKey = "DIAGNOSTIC_DNS_PROBE_NAMES"
+	PacketCaptureDurationKey   ConfigKey = "DIAGNOSTIC_PACKETCAPTURE_DURATION"
+	PacketCaptureInterfaceKey  ConfigKey = "DIAGNOSTIC_PACKETCAPTURE_INTERFACE"
+	PacketCaptureFilterKey     ConfigKey = "DIAGNOSTIC_PACKETCAPTURE_FILTER"
+	PacketCaptureMaxBytesKey   ConfigKey = "DIAGNOSTIC_PACKETCAPTURE_MAX_BYTES"
+	PacketCaptureMaxPacketsKey ConfigKey = "DIAGNOSTIC_PACKETCAPTURE_MAX_PACKETS"
+	NamespacesListKey          ConfigKey = "DIAGNOSTIC_NAMESPACES_LIST"
+	TimeWindowKey              ConfigKey = "DIAGNOSTIC_TIME_WINDOW"
+	ServiceAccountsListKey     ConfigKey = "DIAGNOSTIC_SERVICEACCOUNTS_LIST"
+	CertificateExpiryWindowKey ConfigKey = "DIAGNOSTIC_CERTIFICATE_EXPIRY_WINDOW"
+	TargetKubernetesVersionKey ConfigKey = "DIAGNOSTIC_TARGET_KUBERNETES_VERSION"
+	HelmReleasesListKey        ConfigKey = "DIAGNOSTIC_HELM_RELEASES_LIST"
+	HelmDetailsListKey         ConfigKey = "DIAGNOSTIC_HELM_DETAILS_LIST"
 )
 
 const (
@@ -45,6 +65,7 @@ func GetKnownFilePaths(osIdentifier OSIdentifier) (*KnownFilePaths, error) {
 			AzureJson:           "/k/azure.json",
 			AzureStackCloudJson: "/k/azurestackcloud.json",
 			WindowsLogsOutput:   "/k/periscope-diagnostic-output",
+			KubeletKubeconfig:   "/k/config",
 			NodeLogsList:        "/config/" + string(NodeLogsWindowsKey),
 			Config:              "/config",
 			Secret:              "/secret",
@@ -60,8 +81,13 @@ func GetKnownFilePaths(osIdentifier OSIdentifier) (*KnownFilePaths, error) {
 			AzureStackCertHost:      "/etchostlogs/ssl/certs/azsCertificate.pem",
 			AzureStackCertContainer: "/etc/ssl/certs/azsCertificate.pem",
 			NodeLogsList:            "/config/" + string(NodeLogsLinuxKey),
-			Config:                  "/config",
-			Secret:                  "/secret",
+			CgroupRoot:              "/cgrouphost",
+			// The host's /var/log is mounted at the same path in the container, so this is valid in both.
+			PacketCapture: "/var/log/aks-periscope-capture.pcap",
+			// The kubelet kubeconfig is not mounted in the container, so it is read on the host.
+			KubeletKubeconfig: "/var/lib/kubelet/kubeconfig",
+			Config:            "/config",
+			Secret:            "/secret",
 		}, nil
 	default:
 		return nil, fmt.Errorf("unexpected OS: %s", osIdentifier)
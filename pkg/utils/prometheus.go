@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PrometheusSample is a single sample from a Prometheus text exposition, e.g. a /metrics endpoint.
+type PrometheusSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// ParsePrometheusText parses the samples from the Prometheus text exposition format, ignoring comments,
+// HELP and TYPE lines, and sample timestamps.
+func ParsePrometheusText(content string) ([]PrometheusSample, error) {
+	samples := []PrometheusSample{}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sample, err := parsePrometheusLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing line %d: %w", i+1, err)
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+// SumPrometheusSamples adds the values of all samples with the given name whose labels include those given.
+func SumPrometheusSamples(samples []PrometheusSample, name string, labels map[string]string) float64 {
+	sum := 0.0
+	for _, sample := range samples {
+		if sample.Name != name {
+			continue
+		}
+
+		matches := true
+		for key, value := range labels {
+			if sample.Labels[key] != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			sum += sample.Value
+		}
+	}
+
+	return sum
+}
+
+func parsePrometheusLine(line string) (PrometheusSample, error) {
+	sample := PrometheusSample{Labels: map[string]string{}}
+
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd <= 0 {
+		return sample, fmt.Errorf("missing value in '%s'", line)
+	}
+	sample.Name = line[:nameEnd]
+	rest := line[nameEnd:]
+
+	if strings.HasPrefix(rest, "{") {
+		labelsEnd, err := parsePrometheusLabels(rest[1:], sample.Labels)
+		if err != nil {
+			return sample, err
+		}
+		rest = rest[1+labelsEnd:]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return sample, fmt.Errorf("missing value in '%s'", line)
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, fmt.Errorf("invalid value in '%s': %w", line, err)
+	}
+	sample.Value = value
+
+	return sample, nil
+}
+
+// parsePrometheusLabels reads comma-separated name="value" pairs up to the closing brace, returning the number
+// of characters consumed, including the brace.
+func parsePrometheusLabels(content string, labels map[string]string) (int, error) {
+	i := 0
+	for {
+		for i < len(content) && (content[i] == ' ' || content[i] == ',') {
+			i++
+		}
+		if i >= len(content) {
+			return 0, fmt.Errorf("unterminated labels in '%s'", content)
+		}
+		if content[i] == '}' {
+			return i + 1, nil
+		}
+
+		equals := strings.IndexByte(content[i:], '=')
+		if equals < 0 || i+equals+1 >= len(content) || content[i+equals+1] != '"' {
+			return 0, fmt.Errorf("invalid label in '%s'", content)
+		}
+		name := strings.TrimSpace(content[i : i+equals])
+		i += equals + 2
+
+		var value strings.Builder
+		for {
+			if i >= len(content) {
+				return 0, fmt.Errorf("unterminated label value in '%s'", content)
+			}
+			c := content[i]
+			i++
+			if c == '"' {
+				break
+			}
+			if c == '\\' && i < len(content) {
+				switch content[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(content[i])
+				}
+				i++
+				continue
+			}
+			value.WriteByte(c)
+		}
+		labels[name] = value.String()
+	}
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParsePrometheusText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []PrometheusSample
+		wantErr bool
+	}{
+		{
+			name: "counters, labels and timestamps",
+			content: `# HELP coredns_dns_requests_total Counter of DNS requests made per zone, protocol and family.
+# TYPE coredns_dns_requests_total counter
+coredns_dns_requests_total{family="1",proto="udp",server="dns://:53",type="A",zone="."} 1234
+coredns_dns_responses_total{rcode="NXDOMAIN",server="dns://:53",zone="."} 56 1633072800000
+process_start_time_seconds 1.6330728e+09
+`,
+			want: []PrometheusSample{
+				{Name: "coredns_dns_requests_total", Labels: map[string]string{"family": "1", "proto": "udp", "server": "dns://:53", "type": "A", "zone": "."}, Value: 1234},
+				{Name: "coredns_dns_responses_total", Labels: map[string]string{"rcode": "NXDOMAIN", "server": "dns://:53", "zone": "."}, Value: 56},
+				{Name: "process_start_time_seconds", Labels: map[string]string{}, Value: 1.6330728e+09},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "escaped label values and trailing comma",
+			content: `test_metric{path="C:\\temp",msg="say \"hi\"\nbye",} 1`,
+			want: []PrometheusSample{
+				{Name: "test_metric", Labels: map[string]string{"path": `C:\temp`, "msg": "say \"hi\"\nbye"}, Value: 1},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "special values",
+			content: "bucket{le=\"+Inf\"} +Inf\n",
+			want: []PrometheusSample{
+				{Name: "bucket", Labels: map[string]string{"le": "+Inf"}, Value: math.Inf(1)},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing value",
+			content: "test_metric{a=\"b\"}\n",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unterminated labels",
+			content: "test_metric{a=\"b\" 1\n",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParsePrometheusText(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePrometheusText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("unexpected result:\nexpected %+v\nfound    %+v", tt.want, result)
+			}
+		})
+	}
+}
+
+func TestSumPrometheusSamples(t *testing.T) {
+	samples := []PrometheusSample{
+		{Name: "coredns_dns_responses_total", Labels: map[string]string{"rcode": "NOERROR", "server": "dns://:53"}, Value: 10},
+		{Name: "coredns_dns_responses_total", Labels: map[string]string{"rcode": "SERVFAIL", "server": "dns://:53"}, Value: 2},
+		{Name: "coredns_dns_responses_total", Labels: map[string]string{"rcode": "SERVFAIL", "server": "dns://:5353"}, Value: 3},
+		{Name: "coredns_cache_hits_total", Labels: map[string]string{"type": "success"}, Value: 7},
+	}
+
+	if sum := SumPrometheusSamples(samples, "coredns_dns_responses_total", nil); sum != 15 {
+		t.Errorf("unexpected sum of all responses: expected 15, found %v", sum)
+	}
+	if sum := SumPrometheusSamples(samples, "coredns_dns_responses_total", map[string]string{"rcode": "SERVFAIL"}); sum != 5 {
+		t.Errorf("unexpected sum of SERVFAIL responses: expected 5, found %v", sum)
+	}
+	if sum := SumPrometheusSamples(samples, "missing", nil); sum != 0 {
+		t.Errorf("unexpected sum of missing metric: expected 0, found %v", sum)
+	}
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// RemovedAPI is a deprecated API version of a kind that is no longer served from a given Kubernetes release.
+type RemovedAPI struct {
+	GroupVersion string `json:"groupVersion"`
+	Kind         string `json:"kind"`
+	Resource     string `json:"resource"`
+	RemovedIn    string `json:"removedIn"`
+	Replacement  string `json:"replacement"`
+}
+
+// removedAPIs are taken from the Kubernetes deprecated API migration guide:
+// https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var removedAPIs = []RemovedAPI{
+	{"extensions/v1beta1", "DaemonSet", "daemonsets", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "Deployment", "deployments", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", "replicasets", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "networkpolicies", "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", "podsecuritypolicies", "1.16", "policy/v1beta1"},
+	{"apps/v1beta1", "Deployment", "deployments", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "statefulsets", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "daemonsets", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "deployments", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "replicasets", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "statefulsets", "1.16", "apps/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "mutatingwebhookconfigurations", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "validatingwebhookconfigurations", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "customresourcedefinitions", "1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "apiservices", "1.22", "apiregistration.k8s.io/v1"},
+	{"authentication.k8s.io/v1beta1", "TokenReview", "tokenreviews", "1.22", "authentication.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "LocalSubjectAccessReview", "localsubjectaccessreviews", "1.22", "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SelfSubjectAccessReview", "selfsubjectaccessreviews", "1.22", "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SubjectAccessReview", "subjectaccessreviews", "1.22", "authorization.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "certificatesigningrequests", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "leases", "1.22", "coordination.k8s.io/v1"},
+	{"extensions/v1beta1", "Ingress", "ingresses", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "ingresses", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "ingressclasses", "1.22", "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "clusterroles", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "clusterrolebindings", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "roles", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "rolebindings", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "priorityclasses", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "csidrivers", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "csinodes", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "storageclasses", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "volumeattachments", "1.22", "storage.k8s.io/v1"},
+	{"batch/v1beta1", "CronJob", "cronjobs", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "endpointslices", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "events", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "horizontalpodautoscalers", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", "poddisruptionbudgets", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "podsecuritypolicies", "1.25", "none (use Pod Security Admission)"},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "runtimeclasses", "1.25", "node.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "flowschemas", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "prioritylevelconfigurations", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "horizontalpodautoscalers", "1.26", "autoscaling/v2"},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "csistoragecapacities", "1.27", "storage.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", "flowschemas", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", "prioritylevelconfigurations", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "flowschemas", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", "prioritylevelconfigurations", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// GetRemovedAPIForKind finds the removed API for an apiVersion and kind, as written in a manifest.
+func GetRemovedAPIForKind(apiVersion, kind string) (RemovedAPI, bool) {
+	for _, api := range removedAPIs {
+		if api.GroupVersion == apiVersion && api.Kind == kind {
+			return api, true
+		}
+	}
+	return RemovedAPI{}, false
+}
+
+// GetRemovedAPIForResource finds the removed API for a group, version and resource, as reported by the API server.
+func GetRemovedAPIForResource(group, apiVersion, resource string) (RemovedAPI, bool) {
+	groupVersion := apiVersion
+	if len(group) > 0 {
+		groupVersion = group + "/" + apiVersion
+	}
+
+	for _, api := range removedAPIs {
+		if api.GroupVersion == groupVersion && api.Resource == resource {
+			return api, true
+		}
+	}
+	return RemovedAPI{}, false
+}
+
+// IsRemovedBy returns whether an API removed in the given release is no longer served by the target release.
+func IsRemovedBy(removedIn, target string) (bool, error) {
+	removedVersion, err := version.ParseGeneric(removedIn)
+	if err != nil {
+		return false, fmt.Errorf("invalid removed release '%s': %w", removedIn, err)
+	}
+	targetVersion, err := version.ParseGeneric(target)
+	if err != nil {
+		return false, fmt.Errorf("invalid target release '%s': %w", target, err)
+	}
+
+	// Only the major and minor versions matter, so a target of v1.25.3 is treated as 1.25.
+	if targetVersion.Major() != removedVersion.Major() {
+		return targetVersion.Major() > removedVersion.Major(), nil
+	}
+	return targetVersion.Minor() >= removedVersion.Minor(), nil
+}
+
+// GetNextMinorVersion returns the major and minor version following a Kubernetes version such as "v1.23.5".
+func GetNextMinorVersion(kubernetesVersion string) (string, error) {
+	parsed, err := version.ParseGeneric(strings.TrimSpace(kubernetesVersion))
+	if err != nil {
+		return "", fmt.Errorf("invalid Kubernetes version '%s': %w", kubernetesVersion, err)
+	}
+	return fmt.Sprintf("%d.%d", parsed.Major(), parsed.Minor()+1), nil
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestGetRemovedAPI(t *testing.T) {
+	api, ok := GetRemovedAPIForKind("networking.k8s.io/v1beta1", "Ingress")
+	if !ok || api.RemovedIn != "1.22" || api.Replacement != "networking.k8s.io/v1" {
+		t.Errorf("unexpected removed API for Ingress: %+v, %v", api, ok)
+	}
+
+	api, ok = GetRemovedAPIForResource("batch", "v1beta1", "cronjobs")
+	if !ok || api.Kind != "CronJob" || api.RemovedIn != "1.25" {
+		t.Errorf("unexpected removed API for cronjobs: %+v, %v", api, ok)
+	}
+
+	if _, ok := GetRemovedAPIForKind("apps/v1", "Deployment"); ok {
+		t.Errorf("expected apps/v1 Deployment not to be removed")
+	}
+}
+
+func TestIsRemovedBy(t *testing.T) {
+	tests := []struct {
+		removedIn string
+		target    string
+		want      bool
+	}{
+		{removedIn: "1.25", target: "1.24", want: false},
+		{removedIn: "1.25", target: "1.25", want: true},
+		{removedIn: "1.25", target: "v1.25.3", want: true},
+		{removedIn: "1.16", target: "1.26", want: true},
+		{removedIn: "1.32", target: "1.26", want: false},
+	}
+
+	for _, tt := range tests {
+		got, err := IsRemovedBy(tt.removedIn, tt.target)
+		if err != nil {
+			t.Errorf("IsRemovedBy(%s, %s) error = %v", tt.removedIn, tt.target, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("IsRemovedBy(%s, %s) = %v, want %v", tt.removedIn, tt.target, got, tt.want)
+		}
+	}
+
+	if _, err := IsRemovedBy("1.25", "latest"); err == nil {
+		t.Errorf("expected error for invalid target release")
+	}
+}
+
+func TestGetNextMinorVersion(t *testing.T) {
+	got, err := GetNextMinorVersion("v1.23.5")
+	if err != nil {
+		t.Fatalf("GetNextMinorVersion() error = %v", err)
+	}
+	if got != "1.24" {
+		t.Errorf("GetNextMinorVersion() = %s, want 1.24", got)
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// defaultNdots is the resolver's ndots value when not set in resolv.conf, see resolv.conf(5).
+const defaultNdots = 1
+
+// ResolvConf holds the resolver settings from a resolv.conf file.
+type ResolvConf struct {
+	Nameservers []string
+	Search      []string
+	Ndots       int
+	Options     []string
+}
+
+// ParseResolvConf parses the content of a resolv.conf file. Keywords and values may be separated by any
+// whitespace, and comments start with '#' or ';'.
+func ParseResolvConf(content string) *ResolvConf {
+	conf := &ResolvConf{
+		Nameservers: []string{},
+		Search:      []string{},
+		Ndots:       defaultNdots,
+		Options:     []string{},
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if index := strings.IndexAny(line, "#;"); index >= 0 {
+			line = line[:index]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "nameserver":
+			conf.Nameservers = append(conf.Nameservers, fields[1])
+		case "search", "domain":
+			// The last 'search' or 'domain' entry takes precedence.
+			conf.Search = fields[1:]
+		case "options":
+			for _, option := range fields[1:] {
+				conf.Options = append(conf.Options, option)
+				if strings.HasPrefix(option, "ndots:") {
+					if ndots, err := strconv.Atoi(strings.TrimPrefix(option, "ndots:")); err == nil {
+						conf.Ndots = ndots
+					}
+				}
+			}
+		}
+	}
+
+	return conf
+}
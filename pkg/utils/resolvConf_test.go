@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseResolvConf(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *ResolvConf
+	}{
+		{
+			name:    "kubernetes pod",
+			content: "search default.svc.cluster.local svc.cluster.local cluster.local\nnameserver 10.0.0.10\noptions ndots:5\n",
+			want: &ResolvConf{
+				Nameservers: []string{"10.0.0.10"},
+				Search:      []string{"default.svc.cluster.local", "svc.cluster.local", "cluster.local"},
+				Ndots:       5,
+				Options:     []string{"ndots:5"},
+			},
+		},
+		{
+			name:    "tabs, comments and multiple nameservers",
+			content: "# Generated by systemd-resolved\nnameserver\t168.63.129.16\nnameserver  10.1.1.1 ; secondary\ndomain example.internal\noptions edns0 trust-ad\n",
+			want: &ResolvConf{
+				Nameservers: []string{"168.63.129.16", "10.1.1.1"},
+				Search:      []string{"example.internal"},
+				Ndots:       1,
+				Options:     []string{"edns0", "trust-ad"},
+			},
+		},
+		{
+			name:    "empty",
+			content: "",
+			want: &ResolvConf{
+				Nameservers: []string{},
+				Search:      []string{},
+				Ndots:       1,
+				Options:     []string{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseResolvConf(tt.content)
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("unexpected result:\nexpected %+v\nfound    %+v", tt.want, result)
+			}
+		})
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
 	"github.com/hashicorp/go-multierror"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type Feature string
@@ -24,10 +26,28 @@ func getKnownFeatures() []Feature {
 type RuntimeInfo struct {
 	RunId                   string
 	HostNodeName            string
+	PodNamespace            string
 	CollectorList           []string
 	KubernetesObjects       []string
 	NodeLogs                []string
 	ContainerLogsNamespaces []string
+	SystemLogsServices      []string
+	SamplingDuration        time.Duration
+	SamplingInterval        time.Duration
+	NetworkOutboundTargets  []string
+	DNSProbeNames           []string
+	PacketCaptureDuration   time.Duration
+	PacketCaptureInterface  string
+	PacketCaptureFilter     string
+	PacketCaptureMaxBytes   int64
+	PacketCaptureMaxPackets int64
+	Namespaces              []string
+	TimeWindow              time.Duration
+	ServiceAccounts         []string
+	CertificateExpiryWindow time.Duration
+	TargetKubernetesVersion string
+	HelmReleases            []string
+	HelmDetails             []string
 	StorageAccountName      string
 	StorageSasKey           string
 	StorageContainerName    string
@@ -45,6 +65,23 @@ func GetRuntimeInfo(fs interfaces.FileSystemAccessor, filePaths *KnownFilePaths)
 	kubernetesObjects, errs := readFileContent(fs, filePaths.GetConfigPath(KubeObjectsListKey), false, errs)
 	nodeLogs, errs := readFileContent(fs, filePaths.NodeLogsList, false, errs)
 	containerLogsNamespaces, errs := readFileContent(fs, filePaths.GetConfigPath(ContainerLogsListKey), false, errs)
+	systemLogsServices, errs := readFileContent(fs, filePaths.GetConfigPath(SystemLogsListKey), false, errs)
+	samplingDuration, errs := readDurationContent(fs, filePaths.GetConfigPath(SamplingDurationKey), errs)
+	samplingInterval, errs := readDurationContent(fs, filePaths.GetConfigPath(SamplingIntervalKey), errs)
+	networkOutboundTargets, errs := readFileContent(fs, filePaths.GetConfigPath(OutboundTargetsKey), false, errs)
+	dnsProbeNames, errs := readFileContent(fs, filePaths.GetConfigPath(DNSProbeNamesKey), false, errs)
+	packetCaptureDuration, errs := readDurationContent(fs, filePaths.GetConfigPath(PacketCaptureDurationKey), errs)
+	packetCaptureInterface, errs := readFileContent(fs, filePaths.GetConfigPath(PacketCaptureInterfaceKey), false, errs)
+	packetCaptureFilter, errs := readFileContent(fs, filePaths.GetConfigPath(PacketCaptureFilterKey), false, errs)
+	packetCaptureMaxBytes, errs := readQuantityContent(fs, filePaths.GetConfigPath(PacketCaptureMaxBytesKey), errs)
+	packetCaptureMaxPackets, errs := readQuantityContent(fs, filePaths.GetConfigPath(PacketCaptureMaxPacketsKey), errs)
+	namespaces, errs := readFileContent(fs, filePaths.GetConfigPath(NamespacesListKey), false, errs)
+	timeWindow, errs := readDurationContent(fs, filePaths.GetConfigPath(TimeWindowKey), errs)
+	serviceAccounts, errs := readFileContent(fs, filePaths.GetConfigPath(ServiceAccountsListKey), false, errs)
+	certificateExpiryWindow, errs := readDurationContent(fs, filePaths.GetConfigPath(CertificateExpiryWindowKey), errs)
+	targetKubernetesVersion, errs := readFileContent(fs, filePaths.GetConfigPath(TargetKubernetesVersionKey), false, errs)
+	helmReleases, errs := readFileContent(fs, filePaths.GetConfigPath(HelmReleasesListKey), false, errs)
+	helmDetails, errs := readFileContent(fs, filePaths.GetConfigPath(HelmDetailsListKey), false, errs)
 
 	// Secret
 	storageAccountName, errs := readFileContent(fs, filePaths.GetSecretPath(AccountNameKey), false, errs)
@@ -61,6 +98,10 @@ func GetRuntimeInfo(fs interfaces.FileSystemAccessor, filePaths *KnownFilePaths)
 		errs = multierror.Append(errs, errors.New("variable HOST_NODE_NAME value not set for container"))
 	}
 
+	// The namespace Periscope runs in is also exposed through the downward API. It is optional, so that Periscope can
+	// still run with manifests that do not set it.
+	podNamespace := os.Getenv("POD_NAMESPACE")
+
 	features := map[Feature]bool{}
 	for _, feature := range getKnownFeatures() {
 		featureFilePath := filePaths.GetFeaturePath(feature)
@@ -78,10 +119,28 @@ func GetRuntimeInfo(fs interfaces.FileSystemAccessor, filePaths *KnownFilePaths)
 	return &RuntimeInfo{
 		RunId:                   runId,
 		HostNodeName:            hostName,
+		PodNamespace:            podNamespace,
 		CollectorList:           strings.Fields(collectorList),
 		KubernetesObjects:       strings.Fields(kubernetesObjects),
 		NodeLogs:                strings.Fields(nodeLogs),
 		ContainerLogsNamespaces: strings.Fields(containerLogsNamespaces),
+		SystemLogsServices:      strings.Fields(systemLogsServices),
+		SamplingDuration:        samplingDuration,
+		SamplingInterval:        samplingInterval,
+		NetworkOutboundTargets:  strings.Fields(networkOutboundTargets),
+		DNSProbeNames:           strings.Fields(dnsProbeNames),
+		PacketCaptureDuration:   packetCaptureDuration,
+		PacketCaptureInterface:  strings.TrimSpace(packetCaptureInterface),
+		PacketCaptureFilter:     strings.TrimSpace(packetCaptureFilter),
+		PacketCaptureMaxBytes:   packetCaptureMaxBytes,
+		PacketCaptureMaxPackets: packetCaptureMaxPackets,
+		Namespaces:              strings.Fields(namespaces),
+		TimeWindow:              timeWindow,
+		ServiceAccounts:         strings.Fields(serviceAccounts),
+		CertificateExpiryWindow: certificateExpiryWindow,
+		TargetKubernetesVersion: strings.TrimSpace(targetKubernetesVersion),
+		HelmReleases:            strings.Fields(helmReleases),
+		HelmDetails:             strings.Fields(helmDetails),
 		StorageAccountName:      storageAccountName,
 		StorageSasKey:           storageSasKey,
 		StorageContainerName:    storageContainerName,
@@ -113,6 +172,36 @@ func readFileContent(fs interfaces.FileSystemAccessor, filePath string, mandator
 	return value, readErrors
 }
 
+// readDurationContent reads an optional duration value (e.g. "5m"), which is zero if unset.
+func readDurationContent(fs interfaces.FileSystemAccessor, filePath string, readErrors error) (time.Duration, error) {
+	value, readErrors := readFileContent(fs, filePath, false, readErrors)
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0, readErrors
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, multierror.Append(readErrors, fmt.Errorf("invalid duration in %s: %w", filePath, err))
+	}
+	return duration, readErrors
+}
+
+// readQuantityContent reads an optional quantity value (e.g. "100Mi"), which is zero if unset.
+func readQuantityContent(fs interfaces.FileSystemAccessor, filePath string, readErrors error) (int64, error) {
+	value, readErrors := readFileContent(fs, filePath, false, readErrors)
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0, readErrors
+	}
+
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, multierror.Append(readErrors, fmt.Errorf("invalid quantity in %s: %w", filePath, err))
+	}
+	return quantity.Value(), readErrors
+}
+
 func (runtimeInfo *RuntimeInfo) HasFeature(feature Feature) bool {
 	_, ok := runtimeInfo.Features[feature]
 	return ok
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"log"
+	"time"
+)
+
+// RunSampled calls sample immediately, and then once per interval until duration has elapsed. If either duration
+// or interval is zero, sample is called only once. An error from the first sample is returned, since there is no
+// data to report. Errors from subsequent samples are logged, so that a transient failure doesn't discard the
+// samples already taken.
+func RunSampled(duration, interval time.Duration, sample func() error) error {
+	if err := sample(); err != nil {
+		return err
+	}
+
+	if duration <= 0 || interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	remaining := int(duration / interval)
+	for i := 0; i < remaining; i++ {
+		<-ticker.C
+		if err := sample(); err != nil {
+			log.Printf("Sample %d of %d failed: %v", i+2, remaining+1, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunSampled(t *testing.T) {
+	tests := []struct {
+		name        string
+		duration    time.Duration
+		interval    time.Duration
+		failOn      int
+		wantSamples int
+		wantErr     bool
+	}{
+		{
+			name:        "single sample",
+			duration:    0,
+			interval:    10 * time.Millisecond,
+			failOn:      -1,
+			wantSamples: 1,
+			wantErr:     false,
+		},
+		{
+			name:        "sampled over duration",
+			duration:    30 * time.Millisecond,
+			interval:    10 * time.Millisecond,
+			failOn:      -1,
+			wantSamples: 4,
+			wantErr:     false,
+		},
+		{
+			name:        "first sample fails",
+			duration:    30 * time.Millisecond,
+			interval:    10 * time.Millisecond,
+			failOn:      1,
+			wantSamples: 1,
+			wantErr:     true,
+		},
+		{
+			name:        "later sample fails",
+			duration:    30 * time.Millisecond,
+			interval:    10 * time.Millisecond,
+			failOn:      2,
+			wantSamples: 4,
+			wantErr:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := 0
+			err := RunSampled(tt.duration, tt.interval, func() error {
+				samples++
+				if samples == tt.failOn {
+					return errors.New("sample failed")
+				}
+				return nil
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RunSampled() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if samples != tt.wantSamples {
+				t.Errorf("unexpected sample count: expected %d, found %d", tt.wantSamples, samples)
+			}
+		})
+	}
+}